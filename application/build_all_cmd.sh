@@ -4,4 +4,21 @@ go build -o bin/webapp ./cmd/webapp
 go build -o bin/seeder ./cmd/seeder
 go build -o bin/createclusters ./cmd/createclusters
 go build -o bin/createdb ./cmd/createdb
+go build -o bin/checkclusters ./cmd/checkclusters
+go build -o bin/retention ./cmd/retention
 
+go build -o bin/computepatterns ./cmd/computepatterns
+go build -o bin/createhubs ./cmd/createhubs
+go build -o bin/bridgeschedules ./cmd/bridgeschedules
+go build -o bin/reconcileschedules ./cmd/reconcileschedules
+go build -o bin/collecthistory ./cmd/collecthistory
+go build -o bin/importroutes ./cmd/importroutes
+go build -o bin/schedulechanges ./cmd/schedulechanges
+go build -o bin/importopenflights ./cmd/importopenflights
+go build -o bin/importgtfs ./cmd/importgtfs
+go build -o bin/exportgtfs ./cmd/exportgtfs
+go build -o bin/export ./cmd/export
+go build -o bin/import ./cmd/import
+go build -o bin/pollflightstatus ./cmd/pollflightstatus
+go build -o bin/delaystats ./cmd/delaystats
+go build -o bin/collectairlines ./cmd/collectairlines
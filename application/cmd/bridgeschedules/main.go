@@ -0,0 +1,79 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/bridge"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// bridgeschedules converts imported airports and flight schedules into points and travels.
+// Clustered tables have to be recreated with createclusters afterwards.
+func main() {
+	var environment string
+	var full bool
+	var dedupCodeshares bool
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.BoolVar(&full, "full", false, "Convert all flight schedules instead of the ones changed since the previous run")
+	flag.BoolVar(&dedupCodeshares, "dedup-codeshares", false, "Move codeshare records stored before the deduplication to flight_codeshares first")
+	flag.Parse()
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	if dedupCodeshares {
+		err = migrations.CreateFlightCodesharesTable(db)
+		if err != nil {
+			fmt.Printf("Error creating flight_codeshares table: %v\n", err)
+			os.Exit(1)
+		}
+
+		deleted, err := dao.NewFlightCodesharesDao(db).DeduplicateStoredSchedules()
+		if err != nil {
+			fmt.Printf("Error deduplicating codeshares: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Deleted %d codeshare duplicates of flight schedules\n", deleted)
+	}
+
+	err = migrations.PartitionTravelsTable(db)
+	if err != nil {
+		fmt.Printf("Error partitioning travels: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.AddTravelsDetailsColumns(db)
+	if err != nil {
+		fmt.Printf("Error adding details to travels: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateFlightScheduleChangesTable(db)
+	if err != nil {
+		fmt.Printf("Error creating flight_schedule_changes table: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := bridge.NewSchedulesBridge(db).Sync(full)
+	if err != nil {
+		fmt.Printf("Error converting schedules: %v\n", err)
+		os.Exit(1)
+	}
+
+	since := "the beginning"
+	if !result.Since.IsZero() {
+		since = result.Since.Format(time.DateTime)
+	}
+
+	fmt.Printf("Flight schedules updated from %s converted: %d points and %d travels affected, %d stale travels deleted.\n",
+		since, result.Points, result.Travels, result.DeletedTravels)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/migrations"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// checkclusters verifies clustered_*arrival_travels* tables against travels.
+// Should be run after each createclusters run.
+// Exit codes: 0 - consistent, 1 - check failed to run, 2 - mismatches found.
+func main() {
+	var environment string
+	var sampleLimit int
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.IntVar(&sampleLimit, "samples", 10, "Maximum amount of mismatching travel ids to print per check")
+	flag.Parse()
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Checking clusters...")
+	checker := migrations.NewClustersChecker(db, sampleLimit)
+	report, err := checker.Check()
+	if err != nil {
+		fmt.Printf("Error checking clusters: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(report.ToString())
+
+	if !report.IsConsistent() {
+		fmt.Println("Clusters are NOT consistent with travels!")
+		os.Exit(2)
+	}
+
+	fmt.Println("Clusters are consistent with travels.")
+}
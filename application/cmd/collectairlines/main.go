@@ -0,0 +1,60 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"log"
+	"strings"
+)
+
+// batchSize is the amount of rows inserted by one statement
+const batchSize = 1000
+
+// collectairlines collects the airlines of Aviation Edge airline database into the airlines table,
+// their names are shown by the search results of the operating airlines
+func main() {
+	var environment string
+	var country string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&country, "country", "", "Country ISO2 code of the airlines to collect, all when empty")
+	flag.Parse()
+
+	err := godotenv.Load()
+	if err != nil {
+		fmt.Println("Warning: .env file not found, using environment variables")
+	}
+
+	di.InitializeSingletons(environment)
+
+	err = migrations.CreateAirlinesTable(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := di.Wrap(di.GetAviationEdgeClient)
+	airlines, err := client.GetAirlines(aviation_edge.AirlinesParams{CodeIso2Country: strings.ToUpper(country)})
+	if err != nil {
+		log.Fatalf("Failed to get airlines: %v", err)
+	}
+
+	responses := make([]*aviation_edge.AirlineResponse, len(airlines))
+	for i := range airlines {
+		responses[i] = &airlines[i]
+	}
+
+	airlinesDao := dao.NewAirlinesDao(di.DatabaseInstance)
+	for start := 0; start < len(responses); start += batchSize {
+		err = airlinesDao.Upsert(responses[start:min(start+batchSize, len(responses))])
+		if err != nil {
+			log.Fatalf("Failed to save airlines: %v", err)
+		}
+	}
+
+	fmt.Printf("Collected %d airlines\n", len(responses))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/import"
+	"darbelis.eu/persedimai/internal/migrations"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// collecthistory imports the past departures of an airport with their actual and estimated times and delays
+// into flight_schedules. The imported days are tracked per airport in airports_history_meta_days.
+func main() {
+	var airportCode string
+	var startDate string
+	var endDate string
+	var environment string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&airportCode, "airport", "", "Airport IATA code (e.g., VNO, JFK) or '*' for any unprocessed airport")
+	flag.StringVar(&startDate, "start", "", "Start date in YYYY-MM-DD format")
+	flag.StringVar(&endDate, "end", "", "End date in YYYY-MM-DD format")
+	flag.Parse()
+
+	if airportCode == "" || startDate == "" || endDate == "" {
+		fmt.Println("Error: airport, start and end parameters are required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  collecthistory -airport VNO -start 2025-11-01 -end 2025-11-30")
+		fmt.Println("  collecthistory -airport '*' -start 2025-11-01 -end 2025-11-30")
+		os.Exit(1)
+	}
+
+	// Load .env file
+	err := godotenv.Load()
+	if err != nil {
+		fmt.Println("Warning: .env file not found, using environment variables")
+	}
+
+	di.InitializeSingletons(environment)
+
+	collector := di.Wrap(di.GetDataCollector)
+
+	historyMetaDao := di.Wrap(di.GetAirportsHistoryMetaDao)
+	err = historyMetaDao.CreateTable()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = migrations.CreateFlightCodesharesTable(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = migrations.CreateFlightScheduleChangesTable(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = migrations.AddTypeToFlightSchedulesUniqueKey(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = migrations.AddFlightSchedulesLocalTimesColumns(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	converted, left, err := _import.BackfillLocalTimes(di.Wrap(di.GetFlightSchedulesDao), di.Wrap(di.GetAirportsDao))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if converted > 0 || left > 0 {
+		fmt.Printf("Converted %d flight schedules stored in local times to UTC, %d of unknown timezones left\n", converted, left)
+	}
+	err = historyMetaDao.BackfillDays()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = collector.InitializeEuropeanAirportsHistoryMeta()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if airportCode == "*" {
+		wildcardMeta, err := historyMetaDao.GetFirstWithNullDates()
+		if err != nil {
+			log.Fatalf("Failed to get airport with null dates: %v", err)
+		}
+		if wildcardMeta == nil {
+			fmt.Println("No airports found with null history import dates. All airports have been processed.")
+			return
+		}
+		airportCode = wildcardMeta.AirportCode
+		fmt.Printf("Selected airport: %s\n", airportCode)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Collecting historical departures for airport %s from %s to %s\n", airportCode, startDate, endDate)
+	err = collector.CollectAirportHistory(ctx, airportCode, startDate, endDate)
+	if errors.Is(err, _import.ErrAlreadyImported) {
+		fmt.Println("No import needed.")
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("Airport %s interrupted, the next run imports the missing days\n", airportCode)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to collect historical schedules: %v", err)
+	}
+
+	fmt.Printf("\nImport completed! Airport %s history metadata updated\n", airportCode)
+}
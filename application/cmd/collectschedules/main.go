@@ -1,83 +1,36 @@
 package main
 
 import (
+	"context"
 	"darbelis.eu/persedimai/di"
-	"darbelis.eu/persedimai/internal/tables"
-	"darbelis.eu/persedimai/internal/util"
+	"darbelis.eu/persedimai/internal/import"
+	"darbelis.eu/persedimai/internal/migrations"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/joho/godotenv"
 	"log"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 )
 
-// ImportPlan contains the plan for importing airport data
-type ImportPlan struct {
-	SkipImport       bool
-	ImportStartDate  string
-	ImportEndDate    string
-	MetaStartDate    time.Time // Date to save to metadata
-	MetaEndDate      time.Time // Date to save to metadata
-	AlreadyCoveredBy string    // Description of existing coverage
-}
-
-// calculateImportPlan determines what date range needs to be imported based on existing metadata
-func calculateImportPlan(startDate, endDate string, meta *tables.AirportMeta) *ImportPlan {
-	requestedStart := util.ParseDate(startDate)
-	requestedEnd := util.ParseDate(endDate)
-
-	plan := &ImportPlan{}
-
-	// Check if data is already imported
-	if meta != nil && meta.ImportedFrom != nil && meta.ImportedTo != nil {
-		// Check if requested range is fully covered
-		if !meta.ImportedFrom.After(requestedStart) && !meta.ImportedTo.Before(requestedEnd) {
-			plan.SkipImport = true
-			plan.AlreadyCoveredBy = fmt.Sprintf("%s to %s",
-				meta.ImportedFrom.Format(time.DateOnly),
-				meta.ImportedTo.Format(time.DateOnly))
-			return plan
-		}
-
-		// Partially covered - import the whole range (including any gaps)
-		// Calculate the full range to import
-		plan.ImportStartDate = startDate
-		plan.ImportEndDate = endDate
-
-		// Metadata should cover the entire range (merge with existing)
-		if meta.ImportedFrom.Before(requestedStart) {
-			plan.MetaStartDate = *meta.ImportedFrom
-		} else {
-			plan.MetaStartDate = requestedStart
-		}
-
-		if meta.ImportedTo.After(requestedEnd) {
-			plan.MetaEndDate = *meta.ImportedTo
-		} else {
-			plan.MetaEndDate = requestedEnd
-		}
-	} else {
-		// No existing metadata or no import dates - import the full range
-		plan.ImportStartDate = startDate
-		plan.ImportEndDate = endDate
-		plan.MetaStartDate = requestedStart
-		plan.MetaEndDate = requestedEnd
-	}
-
-	return plan
-}
-
 func main() {
 	var airportCode string
 	var startDate string
 	var endDate string
 	var environment string
+	var arrivals bool
+	var all bool
+	var workers int
 
 	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
 	flag.StringVar(&airportCode, "airport", "", "Airport IATA code (e.g., VNO, JFK) or '*' for any unprocessed airport")
 	flag.StringVar(&startDate, "start", "", "Start date in YYYY-MM-DD format")
 	flag.StringVar(&endDate, "end", "", "End date in YYYY-MM-DD format")
+	flag.BoolVar(&arrivals, "arrivals", false, "Collect arrival schedules of the airport too, to be reconciled with reconcileschedules")
+	flag.BoolVar(&all, "all", false, "With -airport '*' collect all the pending airports instead of one")
+	flag.IntVar(&workers, "workers", 4, "Number of airports collected at once with -all")
 	flag.Parse()
 
 	// Validate required parameters
@@ -88,6 +41,7 @@ func main() {
 		fmt.Println("\nExamples:")
 		fmt.Println("  collectschedules -airport VNO -start 2025-12-27 -end 2025-12-30")
 		fmt.Println("  collectschedules -airport '*' -start 2025-12-27 -end 2025-12-30")
+		fmt.Println("  collectschedules -airport '*' -all -workers 4 -start 2025-12-27 -end 2025-12-30")
 		os.Exit(1)
 	}
 
@@ -121,16 +75,64 @@ func main() {
 		log.Fatal(err)
 		return
 	}
+	err = migrations.CreateFlightCodesharesTable(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	err = migrations.CreateFlightScheduleChangesTable(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	err = migrations.AddTypeToFlightSchedulesUniqueKey(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	err = migrations.AddFlightSchedulesLocalTimesColumns(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	converted, left, err := _import.BackfillLocalTimes(di.Wrap(di.GetFlightSchedulesDao), di.Wrap(di.GetAirportsDao))
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	if converted > 0 || left > 0 {
+		fmt.Printf("Converted %d flight schedules stored in local times to UTC, %d of unknown timezones left\n", converted, left)
+	}
+	err = airportsMetaDao.BackfillDays()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
 	err = collector.InitializeEuropeanAirportsMeta()
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
+	// the first signal lets the workers finish their days, the second one quits at once
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			cancel()
+			fmt.Println("\nInterrupted, finishing the days being collected (press Ctrl+C again to quit at once)...")
+		case <-done:
+		}
+	}()
+
 	// Handle wildcard airport code
 	if airportCode == "*" {
-		fmt.Println("Wildcard '*' detected, searching for airport with null import dates...")
-
 		// Print statistics
 		nullCount, err := airportsMetaDao.CountWithNullDates()
 		if err != nil {
@@ -144,6 +146,35 @@ func main() {
 		fmt.Printf("Airport statistics: %d total, %d pending (null dates), %d completed (non-null dates)\n",
 			totalCount, nullCount, nonNullCount)
 
+		if all {
+			pending, err := airportsMetaDao.GetPending()
+			if err != nil {
+				log.Fatalf("Failed to get pending airports: %v", err)
+			}
+			if len(pending) == 0 {
+				fmt.Println("No pending airports found. All airports have been processed.")
+				return
+			}
+
+			airportCodes := make([]string, 0, len(pending))
+			for _, meta := range pending {
+				airportCodes = append(airportCodes, meta.AirportCode)
+			}
+			fmt.Printf("Collecting schedules of %d pending airports from %s to %s with %d workers\n",
+				len(airportCodes), startDate, endDate, workers)
+
+			summary := _import.CollectAirports(ctx, airportCodes, workers, func(ctx context.Context, airportCode string) error {
+				return collector.CollectAirportSchedules(ctx, airportCode, startDate, endDate, arrivals)
+			})
+
+			fmt.Printf("\n%s", summary)
+			if len(summary.Failed) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Println("Wildcard '*' detected, searching for airport with null import dates...")
 		wildcardMeta, err := airportsMetaDao.GetFirstWithNullDates()
 		if err != nil {
 			log.Fatalf("Failed to get airport with null dates: %v", err)
@@ -156,51 +187,19 @@ func main() {
 		fmt.Printf("Selected airport: %s\n", airportCode)
 	}
 
-	// Get existing import metadata for the airport
-	meta, err := airportsMetaDao.Get(airportCode)
-	if err != nil {
-		log.Fatalf("Failed to get airport metadata: %v", err)
-	}
-
-	// Calculate what needs to be imported
-	plan := calculateImportPlan(startDate, endDate, meta)
-
-	// Check if import can be skipped
-	if plan.SkipImport {
-		fmt.Printf("Airport %s is already imported in the range %s (requested: %s to %s)\n",
-			airportCode,
-			plan.AlreadyCoveredBy,
-			startDate, endDate)
+	fmt.Printf("Collecting schedules for airport %s from %s to %s\n", airportCode, startDate, endDate)
+	err = collector.CollectAirportSchedules(ctx, airportCode, startDate, endDate, arrivals)
+	if errors.Is(err, _import.ErrAlreadyImported) {
 		fmt.Println("No import needed.")
 		return
 	}
-
-	// Execute import for the date range
-	fmt.Printf("Collecting departure schedules for airport %s from %s to %s\n",
-		airportCode, plan.ImportStartDate, plan.ImportEndDate)
-	err = collector.CollectDepartureSchedules(airportCode, plan.ImportStartDate, plan.ImportEndDate)
-	if err != nil {
-		log.Fatalf("Failed to collect schedules: %v", err)
-	}
-
-	// Update metadata with import information
-	if meta == nil {
-		meta = &tables.AirportMeta{
-			AirportCode: airportCode,
-		}
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("Airport %s interrupted, the next run imports the missing days\n", airportCode)
+		os.Exit(1)
 	}
-	meta.ImportedFrom = &plan.MetaStartDate
-	meta.ImportedTo = &plan.MetaEndDate
-
-	err = airportsMetaDao.Upsert(meta, true)
 	if err != nil {
-		log.Fatalf("Failed to update airport metadata: %v", err)
+		log.Fatalf("Failed to collect schedules: %v", err)
 	}
 
-	fmt.Printf("\nImport completed! Airport %s metadata updated with range %s to %s\n",
-		airportCode,
-		plan.MetaStartDate.Format(time.DateOnly),
-		plan.MetaEndDate.Format(time.DateOnly))
-
-	//fmt.Printf("\nCollection completed! Total schedules collected: %d\n", consumer.TotalCount)
+	fmt.Printf("\nImport completed! Airport %s metadata updated\n", airportCode)
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/transfer_patterns"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// computepatterns precomputes transfer patterns of the given or of the most searched
+// origin/destination pairs from the travels table
+func main() {
+	var environment string
+	var pairsList string
+	var top int
+	var from string
+	var to string
+	var maxLegs int
+	var minConnectionMinutes int
+	var maxConnectionHours int
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&pairsList, "pairs", "", "Comma separated pairs SOURCE:DESTINATION, the most searched pairs are used when empty")
+	flag.IntVar(&top, "top", 20, "Amount of the most searched pairs to compute when -pairs is not given")
+	flag.StringVar(&from, "from", "", "Departures from date (YYYY-MM-DD), the earliest departure by default")
+	flag.StringVar(&to, "to", "", "Departures to date (YYYY-MM-DD), the latest departure by default")
+	flag.IntVar(&maxLegs, "max-legs", 3, "Maximum amount of travels in a pattern")
+	flag.IntVar(&minConnectionMinutes, "min-connection", 30, "Minimum connection time in minutes")
+	flag.IntVar(&maxConnectionHours, "max-connection", 32, "Maximum connection time in hours")
+	flag.Parse()
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateTransferPatternsTable(db)
+	if err != nil {
+		fmt.Printf("Error creating transfer_patterns table: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = dao.NewSearchStatsDao(db).CreateTable()
+	if err != nil {
+		fmt.Printf("Error creating search_stats table: %v\n", err)
+		os.Exit(1)
+	}
+
+	var pairs []*dao.SearchPair
+	if pairsList != "" {
+		pairs, err = parsePairs(pairsList)
+	} else {
+		pairs, err = dao.NewSearchStatsDao(db).GetTopPairs(top)
+	}
+	if err != nil {
+		fmt.Printf("Error reading pairs: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(pairs) == 0 {
+		fmt.Println("No pairs to compute.")
+		return
+	}
+
+	travelDao := dao.NewTravelDao(db)
+	minDeparture, maxDeparture, err := travelDao.GetMinMaxDeparture()
+	if err != nil {
+		fmt.Printf("Error reading departures range: %v\n", err)
+		os.Exit(1)
+	}
+
+	if from != "" {
+		minDeparture, err = util.TryToParseDate(from, []string{time.DateOnly})
+		if err != nil {
+			fmt.Printf("Invalid from date: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if to != "" {
+		maxDeparture, err = util.TryToParseDate(to, []string{time.DateOnly})
+		if err != nil {
+			fmt.Printf("Invalid to date: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Loading travels departing from %s to %s\n", minDeparture.Format(time.DateTime), maxDeparture.Format(time.DateTime))
+	travels, err := travelDao.FindByDepartureRange(minDeparture, maxDeparture)
+	if err != nil {
+		fmt.Printf("Error loading travels: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded %d travels\n", len(travels))
+
+	builder := transfer_patterns.NewPatternsBuilder(travels, maxLegs,
+		time.Duration(minConnectionMinutes)*time.Minute, time.Duration(maxConnectionHours)*time.Hour)
+
+	transferPatternDao := dao.NewTransferPatternDao(db)
+
+	// one scan per source serves all of its destinations
+	var sources []string
+	destinationsBySource := make(map[string][]string)
+	for _, pair := range pairs {
+		if _, exists := destinationsBySource[pair.From]; !exists {
+			sources = append(sources, pair.From)
+		}
+		destinationsBySource[pair.From] = append(destinationsBySource[pair.From], pair.To)
+	}
+
+	for _, source := range sources {
+		destinations := destinationsBySource[source]
+		startTime := time.Now()
+		patterns := builder.Build(source, destinations)
+
+		err = transferPatternDao.ReplaceForSource(source, destinations, patterns)
+		if err != nil {
+			fmt.Printf("Error storing patterns of %s: %v\n", source, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s → %v: %d patterns in %v\n", source, destinations, len(patterns), time.Since(startTime))
+	}
+
+	fmt.Println("Transfer patterns computed.")
+}
+
+func parsePairs(pairsList string) ([]*dao.SearchPair, error) {
+	var pairs []*dao.SearchPair
+	for _, pairString := range strings.Split(pairsList, ",") {
+		parts := strings.Split(strings.TrimSpace(pairString), ":")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid pair %q, expected SOURCE:DESTINATION", pairString)
+		}
+		pairs = append(pairs, &dao.SearchPair{From: parts[0], To: parts[1]})
+	}
+
+	return pairs, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
 	"darbelis.eu/persedimai/internal/database"
 	"flag"
 	"fmt"
@@ -58,6 +59,14 @@ func main() {
 	}
 
 	fmt.Printf("Privileges granted successfully to user '%s' on database '%s'!\n", dbConfig.Username, dbName)
+
+	// the searches count the searched pairs from the first one
+	fmt.Println("Creating search_stats table...")
+	err = dao.NewSearchStatsDao(database.NewDatabase(dbConfig)).CreateTable()
+	if err != nil {
+		fmt.Printf("Error creating search_stats table: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func createDatabase(db *database.Database, dbName string) error {
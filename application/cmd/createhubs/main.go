@@ -0,0 +1,86 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/hubs"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// createhubs fills the hubs tables used by the hub search strategy.
+// By default hubs are the points with x = 0, the same as the generator uses for hub travels.
+func main() {
+	var environment string
+	var hubIDsList string
+	var hubX float64
+	var nearest int
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&hubIDsList, "ids", "", "Comma separated hub point ids, overrides -x")
+	flag.Float64Var(&hubX, "x", 0, "Points having this x coordinate are hubs")
+	flag.IntVar(&nearest, "nearest", 2, "Amount of nearest hubs assigned to every point")
+	flag.Parse()
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateHubsTables(db)
+	if err != nil {
+		fmt.Printf("Error creating hubs tables: %v\n", err)
+		os.Exit(1)
+	}
+
+	allPoints, err := dao.NewPointDao(db).SelectAll()
+	if err != nil {
+		fmt.Printf("Error loading points: %v\n", err)
+		os.Exit(1)
+	}
+
+	var hubPoints []*tables.Point
+	if hubIDsList != "" {
+		hubIDs := make(map[string]bool)
+		for _, id := range strings.Split(hubIDsList, ",") {
+			hubIDs[strings.TrimSpace(id)] = true
+		}
+		hubPoints = util.ArrayFilter(allPoints, func(p *tables.Point) bool { return hubIDs[p.ID] })
+	} else {
+		hubPoints = util.ArrayFilter(allPoints, func(p *tables.Point) bool { return p.X == hubX })
+	}
+
+	if len(hubPoints) == 0 {
+		fmt.Println("Error: no hub points found")
+		os.Exit(1)
+	}
+	fmt.Printf("Found %d hubs of %d points\n", len(hubPoints), len(allPoints))
+
+	var pointHubs []*tables.PointHub
+	for _, point := range allPoints {
+		pointHubs = append(pointHubs, hubs.NearestHubs(point, hubPoints, nearest)...)
+	}
+
+	hubDao := dao.NewHubDao(db)
+	hubIDs := util.ArrayMap(hubPoints, func(p *tables.Point) string { return p.ID })
+	err = hubDao.ReplaceHubs(hubIDs, pointHubs)
+	if err != nil {
+		fmt.Printf("Error storing hubs: %v\n", err)
+		os.Exit(1)
+	}
+
+	connectionsCount, err := hubDao.RebuildHubConnections()
+	if err != nil {
+		fmt.Printf("Error building hub connections: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Stored %d point hubs and %d hub connections.\n", len(pointHubs), connectionsCount)
+}
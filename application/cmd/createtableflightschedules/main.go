@@ -2,6 +2,8 @@ package main
 
 import (
 	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/import"
 	"darbelis.eu/persedimai/internal/migrations"
 	"flag"
 	"fmt"
@@ -30,5 +32,29 @@ func main() {
 	}
 	fmt.Println("✓ Table created successfully")
 
+	fmt.Println("Adding local time columns to flight_schedules table...")
+	err = migrations.AddFlightSchedulesLocalTimesColumns(db)
+	if err != nil {
+		fmt.Printf("Error altering table: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Table altered successfully")
+
+	fmt.Println("Converting flight schedules stored in local times to UTC...")
+	converted, left, err := _import.BackfillLocalTimes(dao.NewAviationEdgeFlightSchedulesDao(db), dao.NewAirportsDao(db))
+	if err != nil {
+		fmt.Printf("Error converting schedules: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ %d schedules converted, %d of unknown timezones left\n", converted, left)
+
+	fmt.Println("Adding schedule type to the unique key of flight_schedules table...")
+	err = migrations.AddTypeToFlightSchedulesUniqueKey(db)
+	if err != nil {
+		fmt.Printf("Error altering table: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Table altered successfully")
+
 	fmt.Println("Done!")
 }
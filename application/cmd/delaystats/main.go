@@ -0,0 +1,114 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/delays"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// delaystats computes the delay distributions per flight, route and departure airport into delay_stats from
+// the past flights of flight_schedules (collecthistory) and the last polled statuses of flight_status_snapshots
+// (pollflightstatus), or prints the computed ones with -report
+func main() {
+	var environment string
+	var startDate string
+	var endDate string
+	var minFlights int
+	var report string
+	var airport string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&startDate, "start", "", "First departure date of the observed flights in YYYY-MM-DD format (default: 90 days ago)")
+	flag.StringVar(&endDate, "end", "", "Last departure date of the observed flights in YYYY-MM-DD format (default: today)")
+	flag.IntVar(&minFlights, "min-flights", 5, "Flights observed at least to compute the stats of a flight, route or airport")
+	flag.StringVar(&report, "report", "", "Print the computed stats of the scope (flight, route or airport) instead of computing")
+	flag.StringVar(&airport, "airport", "", "Departure airport IATA code of the printed stats")
+	flag.Parse()
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateDelayStatsTable(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	statsDao := dao.NewDelayStatsDao(db)
+
+	if report != "" {
+		printReport(statsDao, report, strings.ToUpper(airport))
+		return
+	}
+
+	if endDate == "" {
+		endDate = time.Now().UTC().Format(time.DateOnly)
+	}
+	if startDate == "" {
+		startDate = time.Now().UTC().AddDate(0, 0, -90).Format(time.DateOnly)
+	}
+
+	err = migrations.CreateFlightStatusSnapshotsTable(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = migrations.AddFlightStatusSnapshotsScheduledColumn(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schedules, err := dao.NewAviationEdgeFlightSchedulesDao(db).FindDelayObservations(startDate, endDate)
+	if err != nil {
+		log.Fatalf("Failed to load flight schedules: %v", err)
+	}
+	snapshots, err := dao.NewFlightStatusSnapshotsDao(db).FindObservations(startDate, endDate)
+	if err != nil {
+		log.Fatalf("Failed to load flight status snapshots: %v", err)
+	}
+
+	observations := delays.Merge(schedules, snapshots)
+	stats := delays.Aggregate(observations, minFlights)
+
+	err = statsDao.ReplaceAll(stats)
+	if err != nil {
+		log.Fatalf("Failed to save delay stats: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, stat := range stats {
+		counts[stat.Scope]++
+	}
+	fmt.Printf("Observed %d flights (%d of schedules, %d of snapshots) from %s to %s\n",
+		len(observations), len(schedules), len(observations)-len(schedules), startDate, endDate)
+	fmt.Printf("Saved delay stats of %d flights, %d routes and %d airports\n",
+		counts[tables.DelayScopeFlight], counts[tables.DelayScopeRoute], counts[tables.DelayScopeAirport])
+}
+
+func printReport(statsDao *dao.DelayStatsDao, scope, airport string) {
+	stats, err := statsDao.FindByScope(scope, airport)
+	if err != nil {
+		log.Fatalf("Failed to load delay stats: %v", err)
+	}
+	if len(stats) == 0 {
+		fmt.Println("No delay stats found")
+		return
+	}
+
+	fmt.Printf("%-8s %-4s %-4s %7s %6s %5s %5s %5s %5s %9s  %s\n",
+		"FLIGHT", "DEP", "ARR", "FLIGHTS", "MEAN", "P50", "P75", "P90", "P95", "CANCELLED", "PERIOD")
+	for _, s := range stats {
+		fmt.Printf("%-8s %-4s %-4s %7d %6.1f %5d %5d %5d %5d %8.1f%%  %s..%s\n",
+			s.FlightIataNumber, s.DepIataCode, s.ArrIataCode, s.Flights, s.MeanDelay,
+			s.P50Delay, s.P75Delay, s.P90Delay, s.P95Delay, s.CancellationRate*100, s.PeriodFrom, s.PeriodTo)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/dataset"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// export writes the points and/or the travels to CSV or NDJSON files to be imported into another environment
+// with the import command. The travels are streamed from the database.
+func main() {
+	var environment string
+	var pointsPath string
+	var travelsPath string
+	var format string
+	var startDate string
+	var endDate string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&pointsPath, "points", "", "Path of the points file to write")
+	flag.StringVar(&travelsPath, "travels", "", "Path of the travels file to write")
+	flag.StringVar(&format, "format", "", "csv or ndjson (default: by the file extension)")
+	flag.StringVar(&startDate, "start", "", "First departure date of the travels to export in YYYY-MM-DD format (default: all)")
+	flag.StringVar(&endDate, "end", "", "Last departure date of the travels to export in YYYY-MM-DD format (default: all)")
+	flag.Parse()
+
+	if pointsPath == "" && travelsPath == "" {
+		fmt.Println("Error: nothing to do, give points and/or travels file")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Println("  export -env test -points points.csv -travels travels.ndjson -start 2026-02-01 -end 2026-02-28")
+		os.Exit(1)
+	}
+	if (startDate == "") != (endDate == "") {
+		fmt.Println("Error: both start and end are required to export a period")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	if pointsPath != "" {
+		points, err := dao.NewPointDao(db).SelectAll()
+		if err != nil {
+			log.Fatalf("Failed to load points: %v", err)
+		}
+
+		count := 0
+		err = writeFile(pointsPath, format, func(file *os.File, fileFormat dataset.Format) error {
+			encoder, err := dataset.NewPointsEncoder(file, fileFormat)
+			if err != nil {
+				return err
+			}
+			for _, point := range points {
+				err = encoder.Encode(point)
+				if err != nil {
+					return err
+				}
+				count++
+			}
+			return encoder.Flush()
+		})
+		if err != nil {
+			log.Fatalf("Failed to export points: %v", err)
+		}
+		fmt.Printf("Exported %d points to %s\n", count, pointsPath)
+	}
+
+	if travelsPath != "" {
+		err = migrations.CheckTravelsDetailsColumns(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var from, to time.Time
+		if startDate != "" {
+			from, to = util.ParseDate(startDate), util.ParseDate(endDate).AddDate(0, 0, 1)
+		}
+
+		count := 0
+		err = writeFile(travelsPath, format, func(file *os.File, fileFormat dataset.Format) error {
+			encoder, err := dataset.NewTravelsEncoder(file, fileFormat)
+			if err != nil {
+				return err
+			}
+			err = dao.NewTravelDao(db).ForEach(from, to, func(travel *tables.Transfer) error {
+				count++
+				return encoder.Encode(travel)
+			})
+			if err != nil {
+				return err
+			}
+			return encoder.Flush()
+		})
+		if err != nil {
+			log.Fatalf("Failed to export travels: %v", err)
+		}
+		fmt.Printf("Exported %d travels to %s\n", count, travelsPath)
+	}
+}
+
+func writeFile(path string, format string, write func(file *os.File, fileFormat dataset.Format) error) error {
+	fileFormat, err := dataset.FormatOf(path, format)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(file, fileFormat)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/gtfs"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// exportgtfs exports the points and the travels as a static GTFS feed to validate the data with the GTFS tools
+// and to compare the search results with other journey planners
+func main() {
+	var environment string
+	var output string
+	var startDate string
+	var endDate string
+	var routeType int
+	var kilometres string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&output, "out", "", "Path of the GTFS zip file to write")
+	flag.StringVar(&startDate, "start", "", "First departure date of the travels to export in YYYY-MM-DD format (default: all)")
+	flag.StringVar(&endDate, "end", "", "Last departure date of the travels to export in YYYY-MM-DD format (default: all)")
+	flag.IntVar(&routeType, "route-type", 3, "GTFS route_type of the routes, 3 (bus) or the extended 1100 (air service)")
+	flag.StringVar(&kilometres, "km", "auto", "Convert the coordinates from kilometres into degrees: auto (when they are not degrees), yes or no")
+	flag.Parse()
+
+	if output == "" {
+		fmt.Println("Error: out is required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Println("  exportgtfs -env test -out ./test_gtfs.zip -start 2026-02-01 -end 2026-02-07")
+		os.Exit(1)
+	}
+	if (startDate == "") != (endDate == "") {
+		fmt.Println("Error: both start and end are required to export a period")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	points, err := dao.NewPointDao(db).SelectAll()
+	if err != nil {
+		log.Fatalf("Failed to load points: %v", err)
+	}
+
+	err = migrations.CheckTravelsDetailsColumns(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the travels are loaded with their service details, which become the routes and the trip names
+	var from, to time.Time
+	if startDate != "" {
+		from, to = util.ParseDate(startDate), util.ParseDate(endDate).AddDate(0, 0, 1)
+	}
+	var travels []*tables.Transfer
+	err = dao.NewTravelDao(db).ForEach(from, to, func(travel *tables.Transfer) error {
+		travels = append(travels, travel)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to load travels: %v", err)
+	}
+
+	options := gtfs.ExportOptions{
+		AgencyName: "persedimai",
+		AgencyURL:  "https://darbelis.eu",
+		RouteType:  routeType,
+		Kilometres: kilometres == "yes" || kilometres == "auto" && gtfs.NeedsKilometres(points),
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	summary, err := gtfs.WriteFeed(file, points, travels, options)
+	if err != nil {
+		_ = file.Close()
+		log.Fatalf("Failed to write the feed: %v", err)
+	}
+	err = file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Exported %d stops, %d routes, %d trips on %d service days to %s\n",
+		summary.Stops, summary.Routes, summary.Trips, summary.ServiceDays, output)
+	if options.Kilometres {
+		fmt.Println("The coordinates were converted from kilometres into degrees")
+	}
+	if summary.SkippedTravels > 0 {
+		fmt.Printf("Skipped %d travels, %d of them of unknown points\n", summary.SkippedTravels, summary.UnknownPointIds)
+	}
+}
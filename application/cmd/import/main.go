@@ -0,0 +1,279 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/dataset"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// import reads the points and/or the travels of CSV or NDJSON files written by the export command, and the fares,
+// and upserts them in batches. Invalid rows (empty or duplicate ids, travels of unknown points or arriving before the
+// departure, invalid fares) are skipped and reported, the command exits with code 2 when there were any.
+// Clustered tables have to be recreated with createclusters afterwards.
+func main() {
+	var environment string
+	var pointsPath string
+	var travelsPath string
+	var faresPath string
+	var format string
+	var batchSize int
+	var dryRun bool
+	var maxErrors int
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&pointsPath, "points", "", "Path of the points file to import")
+	flag.StringVar(&travelsPath, "travels", "", "Path of the travels file to import")
+	flag.StringVar(&faresPath, "fares", "", "Path of the fares file to import")
+	flag.StringVar(&format, "format", "", "csv or ndjson (default: by the file extension)")
+	flag.IntVar(&batchSize, "batch", 1000, "Rows inserted by one statement")
+	flag.BoolVar(&dryRun, "dry-run", false, "Validate the files without saving")
+	flag.IntVar(&maxErrors, "max-errors", 20, "Invalid rows to print")
+	flag.Parse()
+
+	if pointsPath == "" && travelsPath == "" && faresPath == "" {
+		fmt.Println("Error: nothing to do, give points, travels and/or fares file")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Println("  import -env dev -points points.csv -travels travels.ndjson -dry-run")
+		fmt.Println("  import -env dev -fares fares.csv")
+		os.Exit(1)
+	}
+	if batchSize <= 0 {
+		fmt.Println("Error: batch must be positive")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	// travels may refer to the points of the database
+	existing, err := dao.NewPointDao(db).SelectAll()
+	if err != nil {
+		log.Fatalf("Failed to load points: %v", err)
+	}
+	ids := make([]string, len(existing))
+	for i, point := range existing {
+		ids[i] = point.ID
+	}
+	validator := dataset.NewValidator(ids)
+
+	if pointsPath != "" {
+		count, err := importPoints(db, validator, pointsPath, format, batchSize, dryRun)
+		if err != nil {
+			log.Fatalf("Failed to import points: %v", err)
+		}
+		fmt.Printf("Imported %d points of %s\n", count, pointsPath)
+	}
+	pointErrors := len(validator.Errors)
+
+	if travelsPath != "" {
+		if !dryRun {
+			err = prepareTravelsTable(db, travelsPath, format)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		count, err := importTravels(db, validator, travelsPath, format, batchSize, dryRun)
+		if err != nil {
+			log.Fatalf("Failed to import travels: %v", err)
+		}
+		fmt.Printf("Imported %d travels of %s\n", count, travelsPath)
+	}
+	travelErrors := len(validator.Errors) - pointErrors
+
+	if faresPath != "" {
+		if !dryRun {
+			err = migrations.CreateFaresTable(db)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		count, err := importFares(db, validator, faresPath, format, batchSize, dryRun)
+		if err != nil {
+			log.Fatalf("Failed to import fares: %v", err)
+		}
+		fmt.Printf("Imported %d fares of %s\n", count, faresPath)
+	}
+
+	if dryRun {
+		fmt.Println("Dry run, nothing was saved")
+	}
+
+	if len(validator.Errors) > 0 {
+		fmt.Printf("Skipped %d invalid points, %d invalid travels and %d invalid fares:\n",
+			pointErrors, travelErrors, len(validator.Errors)-pointErrors-travelErrors)
+		for i, validationError := range validator.Errors {
+			if i == maxErrors {
+				fmt.Printf("  ... and %d more\n", len(validator.Errors)-maxErrors)
+				break
+			}
+			fmt.Printf("  %v\n", validationError)
+		}
+		os.Exit(2)
+	}
+}
+
+func importPoints(db *database.Database, validator *dataset.Validator, path, format string, batchSize int, dryRun bool) (int, error) {
+	pointDao := dao.NewPointDao(db)
+	count := 0
+	var batch []*tables.Point
+	save := func() error {
+		count += len(batch)
+		if !dryRun && len(batch) > 0 {
+			err := pointDao.UpsertMany(batch)
+			if err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := readFile(path, format, func(file *os.File, fileFormat dataset.Format) error {
+		return dataset.ReadPoints(file, fileFormat, func(line int, point *tables.Point) error {
+			if !validator.ValidatePoint(line, point) {
+				return nil
+			}
+			batch = append(batch, point)
+			if len(batch) < batchSize {
+				return nil
+			}
+			return save()
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, save()
+}
+
+// prepareTravelsTable adds the details columns and the month partitions of the departures of the file to travels,
+// the file is read once more for its departure range
+func prepareTravelsTable(db *database.Database, path, format string) error {
+	err := migrations.PartitionTravelsTable(db)
+	if err != nil {
+		return err
+	}
+
+	err = migrations.AddTravelsDetailsColumns(db)
+	if err != nil {
+		return err
+	}
+
+	var from, to time.Time
+	err = readFile(path, format, func(file *os.File, fileFormat dataset.Format) error {
+		return dataset.ReadTravels(file, fileFormat, func(line int, travel *tables.Transfer) error {
+			if from.IsZero() || travel.Departure.Before(from) {
+				from = travel.Departure
+			}
+			if travel.Departure.After(to) {
+				to = travel.Departure
+			}
+			return nil
+		})
+	})
+	if err != nil || from.IsZero() {
+		return err
+	}
+
+	return migrations.NewPartitionsManager(db).EnsureMonthPartitions(migrations.TravelsPartitionedTable, from, to)
+}
+
+func importTravels(db *database.Database, validator *dataset.Validator, path, format string, batchSize int, dryRun bool) (int, error) {
+	travelDao := dao.NewTravelDao(db)
+	count := 0
+	var batch []*tables.Transfer
+	save := func() error {
+		count += len(batch)
+		if !dryRun && len(batch) > 0 {
+			err := travelDao.UpsertMany(batch)
+			if err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := readFile(path, format, func(file *os.File, fileFormat dataset.Format) error {
+		return dataset.ReadTravels(file, fileFormat, func(line int, travel *tables.Transfer) error {
+			if !validator.ValidateTravel(line, travel) {
+				return nil
+			}
+			batch = append(batch, travel)
+			if len(batch) < batchSize {
+				return nil
+			}
+			return save()
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, save()
+}
+
+func importFares(db *database.Database, validator *dataset.Validator, path, format string, batchSize int, dryRun bool) (int, error) {
+	faresDao := dao.NewFaresDao(db)
+	count := 0
+	var batch []*tables.Fare
+	save := func() error {
+		count += len(batch)
+		if !dryRun && len(batch) > 0 {
+			err := faresDao.Upsert(batch)
+			if err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := readFile(path, format, func(file *os.File, fileFormat dataset.Format) error {
+		return dataset.ReadFares(file, fileFormat, func(line int, fare *tables.Fare) error {
+			if !validator.ValidateFare(line, fare) {
+				return nil
+			}
+			batch = append(batch, fare)
+			if len(batch) < batchSize {
+				return nil
+			}
+			return save()
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, save()
+}
+
+func readFile(path string, format string, read func(file *os.File, fileFormat dataset.Format) error) error {
+	fileFormat, err := dataset.FormatOf(path, format)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	return read(file, fileFormat)
+}
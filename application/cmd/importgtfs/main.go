@@ -0,0 +1,134 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/gtfs"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// batchSize is the amount of rows inserted by one statement
+const batchSize = 1000
+
+// importgtfs imports the stops of a static GTFS feed (trains, buses) as points and expands its service calendars
+// into dated travels between consecutive stops, so the searches combine them with the flights.
+// Clustered tables have to be recreated with createclusters afterwards.
+func main() {
+	var environment string
+	var zipPath string
+	var prefix string
+	var timezone string
+	var startDate string
+	var endDate string
+	var walkDistance float64
+	var walkInterval time.Duration
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&zipPath, "zip", "", "Path to the GTFS zip file")
+	flag.StringVar(&prefix, "prefix", "", "Feed name prefixing the ids of the points and travels, e.g. LTG")
+	flag.StringVar(&timezone, "timezone", "", "Timezone of the stop times (default: agency_timezone of agency.txt)")
+	flag.StringVar(&startDate, "start", "", "First service date to expand in YYYY-MM-DD format (default: the first date of the feed)")
+	flag.StringVar(&endDate, "end", "", "Last service date to expand in YYYY-MM-DD format (default: the last date of the feed)")
+	flag.Float64Var(&walkDistance, "walk-km", 1, "Distance in km of the stops and other points (airports, stops of other feeds) linked by walks, 0 links only the transfers.txt stops")
+	flag.DurationVar(&walkInterval, "walk-interval", 15*time.Minute, "Interval of the walk departures")
+	flag.Parse()
+
+	if zipPath == "" || prefix == "" {
+		fmt.Println("Error: zip and prefix are required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Println("  importgtfs -zip ./gtfs.zip -prefix LTG -start 2026-02-01 -end 2026-02-28")
+		os.Exit(1)
+	}
+
+	var location *time.Location
+	var err error
+	if timezone != "" {
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			log.Fatalf("Invalid timezone: %v", err)
+		}
+	}
+
+	feed, err := gtfs.ReadFeed(zipPath, location)
+	if err != nil {
+		log.Fatalf("Failed to read the feed: %v", err)
+	}
+
+	from, to, err := feed.ServiceDates()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if startDate != "" {
+		from = util.ParseDate(startDate)
+	}
+	if endDate != "" {
+		to = util.ParseDate(endDate)
+	}
+	if to.Before(from) {
+		log.Fatalf("The end %s is before the start %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	points := feed.Points(prefix)
+	pointDao := dao.NewPointDao(db)
+	for i := 0; i < len(points); i += batchSize {
+		err = pointDao.UpsertMany(points[i:min(i+batchSize, len(points))])
+		if err != nil {
+			log.Fatalf("Failed to save points: %v", err)
+		}
+	}
+	fmt.Printf("Imported %d stops of %d trips\n", len(points), len(feed.Trips))
+
+	others, err := pointDao.SelectAll()
+	if err != nil {
+		log.Fatalf("Failed to load points: %v", err)
+	}
+	walks := feed.Walks(prefix, others, walkDistance)
+	fmt.Printf("Linked %d walks between the stops and the nearby points\n", len(walks))
+
+	err = migrations.PartitionTravelsTable(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// a day around the range for the trips running past midnight and the conversion to UTC
+	err = migrations.NewPartitionsManager(db).EnsureMonthPartitions(migrations.TravelsPartitionedTable,
+		from.AddDate(0, 0, -1), to.AddDate(0, 0, 2))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = migrations.AddTravelsDetailsColumns(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	travelDao := dao.NewTravelDao(db)
+	total := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		travels := append(feed.ExpandDay(prefix, day), gtfs.ExpandWalks(prefix, walks, day, walkInterval)...)
+		for i := 0; i < len(travels); i += batchSize {
+			err = travelDao.UpsertMany(travels[i:min(i+batchSize, len(travels))])
+			if err != nil {
+				log.Fatalf("Failed to save travels: %v", err)
+			}
+		}
+		total += len(travels)
+	}
+
+	fmt.Printf("Expanded the trips and walks into %d travels from %s to %s\n", total, from.Format(time.DateOnly), to.Format(time.DateOnly))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/openflights"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// batchSize is the amount of rows inserted by one statement
+const batchSize = 1000
+
+// importopenflights imports the airports and the airline routes network from the OpenFlights data files
+// (airports.dat, airlines.dat, routes.dat and countries.dat of https://github.com/jpatokal/openflights/tree/master/data)
+// without an Aviation Edge key. The airports imported from Aviation Edge are kept unless -overwrite is given.
+func main() {
+	var environment string
+	var dir string
+	var europe bool
+	var overwrite bool
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&dir, "dir", "", "Directory of the OpenFlights .dat files")
+	flag.BoolVar(&europe, "europe", false, "Import the airports of the European countries only")
+	flag.BoolVar(&overwrite, "overwrite", false, "Overwrite the airports already in the database")
+	flag.Parse()
+
+	if dir == "" {
+		fmt.Println("Error: dir parameter is required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  importopenflights -dir ./openflights -europe")
+		os.Exit(1)
+	}
+
+	var countryCodes []string
+	if europe {
+		countryCodes = util.EuropeanCountryCodes
+	}
+
+	network, err := openflights.ReadNetwork(dir, countryCodes)
+	if err != nil {
+		fmt.Printf("Error reading OpenFlights data: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Read %d airports (%d skipped without IATA code or country) and %d routes (%d skipped of other airports)\n",
+		len(network.Airports), network.SkippedAirports, len(network.Routes), network.SkippedRoutes)
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateAirportsTable(db)
+	if err != nil {
+		fmt.Printf("Error creating airports table: %v\n", err)
+		os.Exit(1)
+	}
+	err = migrations.CreateNetworkRoutesTable(db)
+	if err != nil {
+		fmt.Printf("Error creating network_routes table: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateAirlinesTable(db)
+	if err != nil {
+		fmt.Printf("Error creating airlines table: %v\n", err)
+		os.Exit(1)
+	}
+
+	airportsDao := dao.NewAirportsDao(db)
+
+	airports := network.Airports
+	if !overwrite {
+		existing, err := airportsDao.GetAll()
+		if err != nil {
+			fmt.Printf("Error loading airports: %v\n", err)
+			os.Exit(1)
+		}
+
+		existingCodes := make(map[string]bool, len(existing))
+		for _, airport := range existing {
+			existingCodes[airport.CodeIataAirport] = true
+			if airport.CodeIcaoAirport != "" {
+				existingCodes[airport.CodeIcaoAirport] = true
+			}
+		}
+
+		airports = nil
+		for _, airport := range network.Airports {
+			if !existingCodes[airport.CodeIataAirport] && (airport.CodeIcaoAirport == "" || !existingCodes[airport.CodeIcaoAirport]) {
+				airports = append(airports, airport)
+			}
+		}
+		fmt.Printf("%d airports are already in the database\n", len(network.Airports)-len(airports))
+	}
+
+	for start := 0; start < len(airports); start += batchSize {
+		err = airportsDao.Upsert(airports[start:min(start+batchSize, len(airports))])
+		if err != nil {
+			fmt.Printf("Error saving airports: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	networkRoutesDao := dao.NewNetworkRoutesDao(db)
+	for start := 0; start < len(network.Routes); start += batchSize {
+		err = networkRoutesDao.UpsertRoutes(network.Routes[start:min(start+batchSize, len(network.Routes))])
+		if err != nil {
+			fmt.Printf("Error saving routes: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// the OpenFlights airlines have negative ids, the airlines collected from Aviation Edge are kept
+	err = dao.NewAirlinesDao(db).Upsert(network.Airlines)
+	if err != nil {
+		fmt.Printf("Error saving airlines: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Imported %d airports, %d airlines and %d routes\n", len(airports), len(network.Airlines), len(network.Routes))
+}
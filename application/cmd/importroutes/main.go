@@ -0,0 +1,191 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/bridge"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/import"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"log"
+	"os"
+	"strings"
+)
+
+// importroutes imports airline routes into the routes table and expands them into dated travels,
+// which builds the whole network without per day schedule requests.
+// Clustered tables have to be recreated with createclusters afterwards.
+func main() {
+	var environment string
+	var airlines string
+	var departures string
+	var daysFromSchedules bool
+	var startDate string
+	var endDate string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&airlines, "airlines", "", "Comma separated airline IATA codes to import the routes of")
+	flag.StringVar(&departures, "departures", "", "Comma separated departure airport IATA codes to import the routes of")
+	flag.BoolVar(&daysFromSchedules, "days-from-schedules", false, "Set the operating days of the routes from the collected flight schedules")
+	flag.StringVar(&startDate, "start", "", "First local departure date of the travels to expand in YYYY-MM-DD format")
+	flag.StringVar(&endDate, "end", "", "Last local departure date of the travels to expand in YYYY-MM-DD format")
+	flag.Parse()
+
+	if airlines == "" && departures == "" && !daysFromSchedules && startDate == "" {
+		fmt.Println("Error: nothing to do, give airlines or departures to import and/or start and end to expand")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  importroutes -airlines BT,LO")
+		fmt.Println("  importroutes -days-from-schedules -start 2026-02-01 -end 2026-02-28")
+		os.Exit(1)
+	}
+
+	if (startDate == "") != (endDate == "") {
+		fmt.Println("Error: both start and end are required to expand the routes")
+		os.Exit(1)
+	}
+
+	var db *database.Database
+	var err error
+	if airlines != "" || departures != "" {
+		err = godotenv.Load()
+		if err != nil {
+			fmt.Println("Warning: .env file not found, using environment variables")
+		}
+
+		di.InitializeSingletons(environment)
+		db = di.DatabaseInstance
+	} else {
+		fmt.Printf("Connecting to database environment: %s\n", environment)
+		db, err = di.NewDatabase(environment)
+		if err != nil {
+			fmt.Printf("Error connecting to database: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	err = migrations.CreateRoutesTable(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	routesDao := dao.NewRoutesDao(db)
+
+	if airlines != "" || departures != "" {
+		importRoutes(routesDao, airlines, departures)
+	}
+
+	if daysFromSchedules {
+		updated, err := routesDao.UpdateDaysOfWeekFromSchedules()
+		if err != nil {
+			log.Fatalf("Failed to set operating days: %v", err)
+		}
+		fmt.Printf("Operating days set for %d routes\n", updated)
+	}
+
+	if startDate != "" {
+		expandRoutes(db, routesDao, startDate, endDate)
+	}
+}
+
+func importRoutes(routesDao *dao.RoutesDao, airlines, departures string) {
+	client := di.Wrap(di.GetAviationEdgeClient)
+
+	var params []aviation_edge.AirlineRoutesParams
+	for _, airline := range splitCodes(airlines) {
+		params = append(params, aviation_edge.AirlineRoutesParams{AirlineIata: airline})
+	}
+	for _, departure := range splitCodes(departures) {
+		params = append(params, aviation_edge.AirlineRoutesParams{DepartureIata: departure})
+	}
+
+	for _, p := range params {
+		routes, err := client.GetAirlineRoutes(p)
+		if err != nil {
+			log.Fatalf("Failed to get routes of %+v: %v", p, err)
+		}
+
+		err = routesDao.UpsertRoutes(routes)
+		if err != nil {
+			log.Fatalf("Failed to save routes: %v", err)
+		}
+		fmt.Printf("Imported %d routes of %+v\n", len(routes), p)
+	}
+}
+
+func expandRoutes(db *database.Database, routesDao *dao.RoutesDao, startDate, endDate string) {
+	airports, err := dao.NewAirportsDao(db).GetAll()
+	if err != nil {
+		log.Fatalf("Failed to load airports: %v", err)
+	}
+	expander := bridge.NewTimetableExpander(_import.NewScheduleTimeNormalizer(_import.NewAirportTimezones(airports)))
+
+	routes, err := routesDao.FindAll()
+	if err != nil {
+		log.Fatalf("Failed to load routes: %v", err)
+	}
+
+	schedulesBridge := bridge.NewSchedulesBridge(db)
+	_, err = schedulesBridge.UpsertPoints()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = migrations.PartitionTravelsTable(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// a day around the range for the departures shifted by the conversion to UTC
+	err = migrations.NewPartitionsManager(db).EnsureMonthPartitions(migrations.TravelsPartitionedTable,
+		util.ParseDate(startDate).AddDate(0, 0, -1), util.ParseDate(endDate).AddDate(0, 0, 1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = migrations.AddTravelsDetailsColumns(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	travelDao := dao.NewTravelDao(db)
+	total := 0
+	for _, route := range routes {
+		var travels []*tables.Transfer
+		travels, err = expander.Expand(route, startDate, endDate)
+		if err != nil {
+			log.Printf("Skipping %v", err)
+			continue
+		}
+		if len(travels) == 0 {
+			continue
+		}
+
+		err = travelDao.UpsertMany(travels)
+		if err != nil {
+			log.Fatalf("Failed to save travels: %v", err)
+		}
+		total += len(travels)
+	}
+
+	fmt.Printf("Expanded %d routes into %d travels from %s to %s\n", len(routes), total, startDate, endDate)
+}
+
+func splitCodes(codes string) []string {
+	var result []string
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		if code != "" {
+			result = append(result, strings.ToUpper(code))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/delays"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// pollflightstatus polls the flight tracker for the flights departing from and arriving to the airports
+// and appends their statuses and delays to flight_status_snapshots, until interrupted or once with -once.
+// The snapshots are aggregated into delay_stats by delaystats.
+func main() {
+	var environment string
+	var airports string
+	var interval time.Duration
+	var once bool
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&airports, "airports", "", "Comma separated airport IATA codes to poll the flights of")
+	flag.DurationVar(&interval, "interval", 10*time.Minute, "Time between the polls")
+	flag.BoolVar(&once, "once", false, "Poll once and exit")
+	flag.Parse()
+
+	var codes []string
+	for _, code := range strings.Split(airports, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code != "" {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		fmt.Println("Error: airports parameter is required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  pollflightstatus -airports VNO,RIX -interval 10m")
+		fmt.Println("  pollflightstatus -airports VNO -once")
+		os.Exit(1)
+	}
+
+	err := godotenv.Load()
+	if err != nil {
+		fmt.Println("Warning: .env file not found, using environment variables")
+	}
+
+	di.InitializeSingletons(environment)
+
+	err = migrations.CreateFlightStatusSnapshotsTable(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = migrations.AddFlightStatusSnapshotsScheduledColumn(di.DatabaseInstance)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := di.Wrap(di.GetAviationEdgeClient)
+	snapshotsDao := di.Wrap(di.GetFlightStatusSnapshotsDao)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		polledAt := time.Now()
+		total := 0
+		for _, code := range codes {
+			snapshots, err := pollAirport(client, code, polledAt)
+			if err != nil {
+				log.Printf("Failed to poll %s: %v", code, err)
+				continue
+			}
+
+			err = snapshotsDao.InsertSnapshots(snapshots)
+			if err != nil {
+				log.Fatalf("Failed to save snapshots: %v", err)
+			}
+			total += len(snapshots)
+		}
+		fmt.Printf("%s: saved %d flight statuses of %d airports\n", polledAt.Format(time.DateTime), total, len(codes))
+
+		if once {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("\nInterrupted")
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// pollAirport gets the flights departing from and arriving to the airport, the flights seen by both queries are kept once
+func pollAirport(client *aviation_edge.AviationEdgeApiClient, code string, polledAt time.Time) ([]*tables.FlightStatusSnapshot, error) {
+	var flights []aviation_edge.FlightTrackerResponse
+	for _, params := range []aviation_edge.FlightTrackerParams{{DepIata: code}, {ArrIata: code}} {
+		response, err := client.GetFlightTracker(params)
+		if errors.Is(err, aviation_edge.ErrNoData) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		flights = append(flights, response...)
+	}
+
+	seen := make(map[string]bool)
+	var snapshots []*tables.FlightStatusSnapshot
+	for _, snapshot := range delays.SnapshotsOf(flights, polledAt) {
+		key := snapshot.FlightIataNumber + snapshot.DepIataCode + snapshot.ArrIataCode
+		if !seen[key] {
+			seen[key] = true
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/import"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// reconcileschedules matches the arrival schedules collected for an airport with the departure schedules
+// of the same flights collected at their departure airports, fills the gaps of both records and reports
+// the inconsistencies and the flights found at one end only.
+func main() {
+	var environment string
+	var airportCode string
+	var startDate string
+	var endDate string
+	var dryRun bool
+	var verbose bool
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&airportCode, "airport", "", "Arrival airport IATA code (e.g., VNO)")
+	flag.StringVar(&startDate, "start", "", "First local arrival date in YYYY-MM-DD format")
+	flag.StringVar(&endDate, "end", "", "Last local arrival date in YYYY-MM-DD format")
+	flag.BoolVar(&dryRun, "dry-run", false, "Only report, do not save the filled gaps")
+	flag.BoolVar(&verbose, "v", false, "List the unmatched flights")
+	flag.Parse()
+
+	if airportCode == "" || startDate == "" || endDate == "" {
+		fmt.Println("Error: airport, start and end parameters are required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	schedulesDao := dao.NewAviationEdgeFlightSchedulesDao(db)
+
+	departures, err := schedulesDao.FindByArrivalAirport("departure", airportCode, startDate, endDate)
+	if err != nil {
+		fmt.Printf("Error loading departure schedules: %v\n", err)
+		os.Exit(1)
+	}
+
+	arrivals, err := schedulesDao.FindByArrivalAirport("arrival", airportCode, startDate, endDate)
+	if err != nil {
+		fmt.Printf("Error loading arrival schedules: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Reconciling %d departure and %d arrival schedules of flights to %s from %s to %s\n",
+		len(departures), len(arrivals), airportCode, startDate, endDate)
+
+	report := _import.ReconcileSchedules(departures, arrivals)
+
+	if !dryRun {
+		err = schedulesDao.UpsertFlightSchedules(report.Updated)
+		if err != nil {
+			fmt.Printf("Error saving reconciled schedules: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Matched flights: %d, records with filled gaps: %d\n", report.Matched, len(report.Updated))
+
+	fmt.Printf("\nInconsistencies: %d\n", len(report.Inconsistencies))
+	for _, inconsistency := range report.Inconsistencies {
+		fmt.Printf("  %s %s %s → %s %s: departure %q, arrival %q\n",
+			inconsistency.FlightIataNumber, inconsistency.Date, inconsistency.DepIataCode, inconsistency.ArrIataCode,
+			inconsistency.Field, inconsistency.DepartureValue, inconsistency.ArrivalValue)
+	}
+
+	fmt.Println("\nUnmatched flights per airport:")
+	for _, airport := range report.Airports() {
+		unmatched := report.Unmatched[airport]
+		fmt.Printf("  %s: %d missing in departures, %d missing in arrivals\n",
+			airport, len(unmatched.MissingDepartures), len(unmatched.MissingArrivals))
+
+		if !verbose {
+			continue
+		}
+		for _, s := range unmatched.MissingDepartures {
+			fmt.Printf("    departure missing: %s %s %s → %s\n", s.Flight.IataNumber, s.Departure.ScheduledTimeLocal, s.Departure.IataCode, s.Arrival.IataCode)
+		}
+		for _, s := range unmatched.MissingArrivals {
+			fmt.Printf("    arrival missing: %s %s %s → %s\n", s.Flight.IataNumber, s.Departure.ScheduledTimeLocal, s.Departure.IataCode, s.Arrival.IataCode)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/migrations"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// retention drops month partitions of travels and of the clustered tables
+// which contain only departures older than the configured age. The departures written into pmax,
+// past the last month partition, are split into their month partitions first to be dropped in time.
+func main() {
+	var environment string
+	var keepMonths int
+	var dryRun bool
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.IntVar(&keepMonths, "keep-months", 12, "Amount of months before the current one to keep")
+	flag.BoolVar(&dryRun, "dry-run", false, "Only print the partitions which would be dropped")
+	flag.Parse()
+
+	if keepMonths < 0 {
+		fmt.Println("Error: keep-months must not be negative")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	cutoff := migrations.MonthStart(time.Now()).AddDate(0, -keepMonths, 0)
+	fmt.Printf("Dropping partitions with departures before %s\n", cutoff.Format(time.DateOnly))
+
+	partitionsManager := migrations.NewPartitionsManager(db)
+	// the clustered tables hold the departures of travels
+	from, to, ok, err := partitionsManager.GetTravelsDepartureRange()
+	if err != nil {
+		fmt.Printf("Error reading the departures of travels: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, table := range migrations.GetMonthPartitionedTables() {
+		partitions, err := partitionsManager.GetPartitionNames(table.TableName)
+		if err != nil {
+			fmt.Printf("Error reading partitions of %s: %v\n", table.TableName, err)
+			os.Exit(1)
+		}
+		if len(partitions) == 0 {
+			fmt.Printf("%s: not created\n", table.TableName)
+			continue
+		}
+
+		// the dry run drops from the partitions the split would create
+		if ok && dryRun {
+			for _, month := range migrations.MonthsToAdd(partitions, from, to) {
+				partitions = append(partitions[:len(partitions)-1], migrations.MonthPartitionName(month), migrations.MAX_PARTITION_NAME)
+			}
+		} else if ok {
+			err = partitionsManager.EnsureMonthPartitions(table, from, to)
+			if err != nil {
+				fmt.Printf("Error splitting %s of %s: %v\n", migrations.MAX_PARTITION_NAME, table.TableName, err)
+				os.Exit(1)
+			}
+			partitions, err = partitionsManager.GetPartitionNames(table.TableName)
+			if err != nil {
+				fmt.Printf("Error reading partitions of %s: %v\n", table.TableName, err)
+				os.Exit(1)
+			}
+		}
+
+		names := migrations.FilterPartitionsOlderThan(partitions, cutoff)
+		if len(names) == 0 {
+			fmt.Printf("%s: nothing to drop\n", table.TableName)
+			continue
+		}
+
+		fmt.Printf("%s: dropping %v\n", table.TableName, names)
+		if dryRun {
+			continue
+		}
+
+		err = partitionsManager.DropPartitions(table.TableName, names)
+		if err != nil {
+			fmt.Printf("Error dropping partitions: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println("Retention finished.")
+}
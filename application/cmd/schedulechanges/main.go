@@ -0,0 +1,85 @@
+package main
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// schedulechanges lists the changes of the flight schedules (added and removed flights, time changes,
+// cancellations and aircraft swaps) found by the repeated imports of an airport or a route
+func main() {
+	var environment string
+	var airportCode string
+	var toAirportCode string
+	var since string
+	var changeType string
+
+	flag.StringVar(&environment, "env", "dev", "Database environment (dev, test, prod)")
+	flag.StringVar(&airportCode, "airport", "", "Airport IATA code (e.g., VNO), departure airport of the route with -to")
+	flag.StringVar(&toAirportCode, "to", "", "Arrival airport IATA code of the route (optional)")
+	flag.StringVar(&since, "since", "", "Import date in YYYY-MM-DD format to list the changes from")
+	flag.StringVar(&changeType, "type", "", "Change type to list: added, removed, time, cancelled or aircraft (optional)")
+	flag.Parse()
+
+	if airportCode == "" || since == "" {
+		fmt.Println("Error: airport and since parameters are required")
+		fmt.Println("\nUsage:")
+		flag.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  schedulechanges -airport VNO -since 2026-01-01")
+		fmt.Println("  schedulechanges -airport VNO -to RIX -since 2026-01-01 -type time")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to database environment: %s\n", environment)
+	db, err := di.NewDatabase(environment)
+	if err != nil {
+		fmt.Printf("Error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = migrations.CreateFlightScheduleChangesTable(db)
+	if err != nil {
+		fmt.Printf("Error creating flight_schedule_changes table: %v\n", err)
+		os.Exit(1)
+	}
+
+	changesDao := dao.NewFlightScheduleChangesDao(db)
+
+	var changes []*tables.ScheduleChange
+	if toAirportCode != "" {
+		changes, err = changesDao.FindByRoute(airportCode, toAirportCode, since)
+	} else {
+		changes, err = changesDao.FindByAirport(airportCode, since)
+	}
+	if err != nil {
+		fmt.Printf("Error loading schedule changes: %v\n", err)
+		os.Exit(1)
+	}
+
+	count := 0
+	for _, change := range changes {
+		if changeType != "" && change.ChangeType != changeType {
+			continue
+		}
+		count++
+
+		fmt.Printf("%s %-9s %-8s %s %s → %s %s", change.ImportedAt, change.ChangeType, change.FlightIataNumber,
+			change.DepDate, change.DepIataCode, change.ArrIataCode, change.Type)
+		switch {
+		case change.Field != "":
+			fmt.Printf(" %s: %q → %q\n", change.Field, change.BeforeValue, change.AfterValue)
+		case change.ChangeType == tables.ScheduleChangeRemoved:
+			fmt.Printf(" was at %s\n", change.BeforeValue)
+		default:
+			fmt.Printf(" at %s\n", change.AfterValue)
+		}
+	}
+
+	fmt.Printf("\nChanges: %d\n", count)
+}
@@ -8,8 +8,10 @@ import (
 	"darbelis.eu/persedimai/internal/web/api"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -69,16 +71,38 @@ func InitializeSingletons(defaultEnv string) {
 	}
 }
 
+// GetAviationEdgeClient creates the client, its rate limit and retries budget may be set by
+// AVIATION_EDGE_REQUESTS_PER_SECOND and AVIATION_EDGE_RETRY_BUDGET environment variables
 func GetAviationEdgeClient() *aviation_edge.AviationEdgeApiClient {
-	return aviation_edge.NewAviationEdgeApiClient(ApiKey)
+	client := aviation_edge.NewAviationEdgeApiClient(ApiKey)
+
+	requestsPerSecond, err := strconv.ParseFloat(os.Getenv("AVIATION_EDGE_REQUESTS_PER_SECOND"), 64)
+	if err == nil && requestsPerSecond > 0 {
+		client.RateLimiter = aviation_edge.NewRateLimiter(requestsPerSecond, int(math.Ceil(requestsPerSecond)))
+	}
+
+	retryBudget, err := strconv.Atoi(os.Getenv("AVIATION_EDGE_RETRY_BUDGET"))
+	if err == nil && retryBudget >= 0 {
+		client.RetryPolicy.Budget = retryBudget
+	}
+
+	return client
 }
 
 func GetDataCollector() *_import.DataCollector {
-	return _import.NewDataCollector(Wrap(GetAviationEdgeClient), Wrap(GetScheduleConsumer), Wrap(GetAirportsDao), Wrap(GetAirportsMetaDao))
+	return _import.NewDataCollector(Wrap(GetAviationEdgeClient), Wrap(GetScheduleConsumer), Wrap(GetAirportsDao), Wrap(GetAirportsMetaDao), Wrap(GetAirportsHistoryMetaDao))
 }
 
 func GetScheduleConsumer() aviation_edge.ScheduleConsumer {
-	return dao.NewDatabaseScheduleConsumer(Wrap(GetFlightSchedulesDao))
+	return dao.NewDatabaseScheduleConsumer(Wrap(GetFlightSchedulesDao), Wrap(GetFlightCodesharesDao), Wrap(GetFlightScheduleChangesDao))
+}
+
+func GetFlightScheduleChangesDao() *dao.FlightScheduleChangesDao {
+	return dao.NewFlightScheduleChangesDao(DatabaseInstance)
+}
+
+func GetFlightCodesharesDao() *dao.FlightCodesharesDao {
+	return dao.NewFlightCodesharesDao(DatabaseInstance)
 }
 
 func GetFlightSchedulesDao() *dao.AviationEdgeFlightSchedulesDao {
@@ -91,3 +115,11 @@ func GetAirportsDao() *dao.AirportsDao {
 func GetAirportsMetaDao() *dao.AirportsMetaDao {
 	return dao.NewAirportsMetaDao(DatabaseInstance)
 }
+
+func GetAirportsHistoryMetaDao() *dao.AirportsMetaDao {
+	return dao.NewAirportsHistoryMetaDao(DatabaseInstance)
+}
+
+func GetFlightStatusSnapshotsDao() *dao.FlightStatusSnapshotsDao {
+	return dao.NewFlightStatusSnapshotsDao(DatabaseInstance)
+}
@@ -2,7 +2,9 @@ package integration_tests
 
 import (
 	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/aviation_edge"
 	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
 	"darbelis.eu/persedimai/internal/tables"
 	"testing"
 	"time"
@@ -289,3 +291,88 @@ func TestAirportsMetaDaoGetFirstWithNullDates(t *testing.T) {
 		t.Errorf("Expected airport code 'CDG' or 'JFK', got '%s'", result4.AirportCode)
 	}
 }
+
+func TestAirportsMetaDaoImportedDays(t *testing.T) {
+	// Setup database
+	db, err := di.NewDatabase("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	airportsMetaDao := dao.NewAirportsMetaDao(db)
+	err = airportsMetaDao.CreateTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrations.CreateFlightSchedulesTable(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrations.AddFlightSchedulesLocalTimesColumns(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ClearTestDatabase(db, "airports_meta", "airports_meta_days", "flight_schedules") {
+		t.Fatal("Failed to clear airports_meta tables")
+	}
+
+	// The departures of the range imported before the days were tracked, 2025-12-02 was not imported
+	schedulesDao := dao.NewAviationEdgeFlightSchedulesDao(db)
+	var schedules []*aviation_edge.ScheduleResponse
+	for _, day := range []string{"2025-12-01", "2025-12-03"} {
+		schedules = append(schedules, &aviation_edge.ScheduleResponse{
+			Type:      "departure",
+			Status:    "future",
+			Departure: aviation_edge.Departure{IataCode: "VNO", ScheduledTime: day + "T10:00:00.000"},
+			Arrival:   aviation_edge.Arrival{IataCode: "RIX", ScheduledTime: day + "T11:00:00.000"},
+			Airline:   aviation_edge.Airline{Name: "airBaltic", IataCode: "BT"},
+			Flight:    aviation_edge.Flight{Number: "342", IataNumber: "BT342"},
+		})
+	}
+	err = schedulesDao.UpsertFlightSchedules(schedules)
+	if err != nil {
+		t.Fatalf("UpsertFlightSchedules failed: %v", err)
+	}
+
+	// A range imported before the days were tracked is backfilled by the days of its departures
+	from := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 3, 0, 0, 0, 0, time.UTC)
+	err = airportsMetaDao.Upsert(&tables.AirportMeta{AirportCode: "VNO", ImportedFrom: &from, ImportedTo: &to}, true)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	err = airportsMetaDao.BackfillDays()
+	if err != nil {
+		t.Fatalf("BackfillDays failed: %v", err)
+	}
+
+	err = airportsMetaDao.MarkDayImported("VNO", "departure", "2025-12-05")
+	if err != nil {
+		t.Fatalf("MarkDayImported failed: %v", err)
+	}
+	err = airportsMetaDao.MarkDayImported("VNO", "arrival", "2025-12-04")
+	if err != nil {
+		t.Fatalf("MarkDayImported failed: %v", err)
+	}
+
+	days, err := airportsMetaDao.GetImportedDays("VNO", "departure", "2025-12-02", "2025-12-10")
+	if err != nil {
+		t.Fatalf("GetImportedDays failed: %v", err)
+	}
+	if len(days) != 2 || !days["2025-12-03"] || !days["2025-12-05"] {
+		t.Errorf("Expected departure days 2025-12-03 and 2025-12-05, got %v", days)
+	}
+
+	// The airports having tracked days are not backfilled again
+	err = airportsMetaDao.BackfillDays()
+	if err != nil {
+		t.Fatalf("BackfillDays failed: %v", err)
+	}
+	days, _ = airportsMetaDao.GetImportedDays("VNO", "departure", "2025-12-01", "2025-12-31")
+	if len(days) != 3 {
+		t.Errorf("Expected 3 departure days, got %v", days)
+	}
+}
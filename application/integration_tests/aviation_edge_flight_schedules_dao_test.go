@@ -21,6 +21,16 @@ func TestUpsertFlightSchedules(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	err = migrations.AddFlightSchedulesLocalTimesColumns(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrations.AddTypeToFlightSchedulesUniqueKey(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// Clear table before test
 	if !ClearTestDatabase(db, "flight_schedules") {
 		t.Fatal("Failed to clear flight_schedules table")
@@ -0,0 +1,117 @@
+package integration_tests
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/aviation_edge/fake"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/import"
+	"darbelis.eu/persedimai/internal/migrations"
+	"testing"
+)
+
+// TestDataCollector collects the schedules from the fake Aviation Edge server, no API key is needed
+func TestDataCollector(t *testing.T) {
+	db, err := di.NewDatabase("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, migrate := range []func() error{
+		func() error { return migrations.CreateAirportsTable(db) },
+		func() error { return migrations.CreateFlightSchedulesTable(db) },
+		func() error { return migrations.AddFlightSchedulesLocalTimesColumns(db) },
+		func() error { return migrations.AddTypeToFlightSchedulesUniqueKey(db) },
+		func() error { return migrations.CreateFlightCodesharesTable(db) },
+		func() error { return migrations.CreateFlightScheduleChangesTable(db) },
+	} {
+		err = migrate()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !ClearTestDatabase(db, "airports", "flight_schedules", "flight_codeshares", "flight_schedule_changes") {
+		t.Fatal("Failed to clear tables")
+	}
+
+	vnoRix := aviation_edge.ScheduleResponse{
+		Departure: aviation_edge.Departure{IataCode: "VNO", ScheduledTime: "23:30"},
+		Arrival:   aviation_edge.Arrival{IataCode: "RIX", ScheduledTime: "00:30", Terminal: "1"},
+		Airline:   aviation_edge.Airline{Name: "airBaltic", IataCode: "BT"},
+		Flight:    aviation_edge.Flight{Number: "344", IataNumber: "BT344"},
+	}
+	landed := vnoRix
+	landed.Status = "landed"
+	landed.Departure.ScheduledTime = "2025-12-01t23:30:00.000"
+	landed.Departure.ActualTime = "2025-12-01t23:45:00.000"
+	landed.Departure.Delay = aviation_edge.DelayValue{Value: "15"}
+	landed.Arrival.ScheduledTime = "2025-12-02t00:30:00.000"
+
+	airports := []aviation_edge.AirportResponse{
+		{CodeIataAirport: "VNO", NameAirport: "Vilnius", CodeIso2Country: "LT", Timezone: "Europe/Vilnius"},
+		{CodeIataAirport: "RIX", NameAirport: "Riga", CodeIso2Country: "LV", Timezone: "Europe/Riga"},
+	}
+	server := fake.NewServer("test-key", fake.Data{
+		Airports: airports,
+		Future:   map[string][]aviation_edge.ScheduleResponse{"2026-01-15": {vnoRix}},
+		History:  []aviation_edge.ScheduleResponse{landed},
+	})
+	defer server.Close()
+
+	airportsDao := dao.NewAirportsDao(db)
+	err = airportsDao.Upsert([]*aviation_edge.AirportResponse{&airports[0], &airports[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schedulesDao := dao.NewAviationEdgeFlightSchedulesDao(db)
+	collector := _import.NewDataCollector(
+		server.NewClient(),
+		dao.NewDatabaseScheduleConsumer(schedulesDao, dao.NewFlightCodesharesDao(db), dao.NewFlightScheduleChangesDao(db)),
+		airportsDao,
+		dao.NewAirportsMetaDao(db),
+		dao.NewAirportsHistoryMetaDao(db),
+	)
+
+	err = collector.CollectDepartureSchedules("VNO", "2026-01-15", "2026-01-16")
+	if err != nil {
+		t.Fatalf("CollectDepartureSchedules failed: %v", err)
+	}
+	err = collector.CollectArrivalSchedules("RIX", "2026-01-15", "2026-01-15")
+	if err != nil {
+		t.Fatalf("CollectArrivalSchedules failed: %v", err)
+	}
+	err = collector.CollectHistoricalSchedules("VNO", "2025-12-01", "2025-12-01")
+	if err != nil {
+		t.Fatalf("CollectHistoricalSchedules failed: %v", err)
+	}
+
+	schedules, err := schedulesDao.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(schedules) != 3 {
+		t.Fatalf("Expected 3 schedules (future departure and arrival, historical departure), got %d", len(schedules))
+	}
+
+	for _, schedule := range schedules {
+		switch {
+		case schedule.Status == "landed":
+			if schedule.Departure.ActualTime != "2025-12-01 21:45:00" || schedule.Departure.Delay.Value != "15" {
+				t.Errorf("Expected actual departure 2025-12-01 21:45:00 delayed by 15, got %s delayed by %s",
+					schedule.Departure.ActualTime, schedule.Departure.Delay.Value)
+			}
+		case schedule.Type == "departure":
+			if schedule.Departure.ScheduledTime != "2026-01-15 21:30:00" || schedule.Arrival.ScheduledTime != "2026-01-15 22:30:00" {
+				t.Errorf("Expected 2026-01-15 21:30:00 → 22:30:00, got %s → %s", schedule.Departure.ScheduledTime, schedule.Arrival.ScheduledTime)
+			}
+		case schedule.Type == "arrival":
+			// collected for the arrival day, the flight departs the evening before
+			if schedule.Departure.ScheduledTimeLocal != "2026-01-14 23:30:00" {
+				t.Errorf("Expected local departure 2026-01-14 23:30:00, got %s", schedule.Departure.ScheduledTimeLocal)
+			}
+		}
+	}
+}
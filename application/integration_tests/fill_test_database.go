@@ -42,6 +42,11 @@ func (d *DatabaseFiller) FillDatabase(db *database.Database) error {
 		return err
 	}
 
+	err = migrations.AddTravelsDetailsColumns(d.db)
+	if err != nil {
+		return err
+	}
+
 	// Clear existing data
 	log.Println("Clearing existing data...")
 	ClearTestDatabase(d.db, "points")
@@ -94,6 +99,11 @@ func (d *DatabaseFiller) FillDatabase(db *database.Database) error {
 	d.speed = 1000.0
 	d.restHours = 24
 
+	err = migrations.NewPartitionsManager(d.db).EnsureMonthPartitions(migrations.TravelsPartitionedTable, *d.fromDate, *d.toDate)
+	if err != nil {
+		return err
+	}
+
 	d.travelDao = dao.NewTravelDao(d.db)
 	d.travelDbConsumer = generator.NewTravelConsumer(d.travelDao, 500)
 
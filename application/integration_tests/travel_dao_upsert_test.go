@@ -0,0 +1,67 @@
+package integration_tests
+
+import (
+	"darbelis.eu/persedimai/di"
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+func TestTravelDaoUpsertMany(t *testing.T) {
+	// Setup database
+	db, err := di.NewDatabase("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrations.CreateTravelsTable(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	departure := time.Date(2027, 3, 1, 10, 0, 0, 0, time.UTC)
+	err = migrations.NewPartitionsManager(db).EnsureMonthPartitions(migrations.TravelsPartitionedTable, departure, departure.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	travelDao := dao.NewTravelDao(db)
+	travel := &tables.Transfer{ID: "LTG_T1_20270301_1", From: "LTG:VLN", To: "LTG:KNS", Departure: departure, Arrival: departure.Add(time.Hour)}
+	err = travelDao.UpsertMany([]*tables.Transfer{travel})
+	if err != nil {
+		t.Fatalf("UpsertMany failed: %v", err)
+	}
+
+	// The travel of the same id rescheduled to another month replaces the old row
+	rescheduled := *travel
+	rescheduled.Departure = departure.AddDate(0, 1, 0)
+	rescheduled.Arrival = rescheduled.Departure.Add(time.Hour)
+	err = travelDao.UpsertMany([]*tables.Transfer{&rescheduled})
+	if err != nil {
+		t.Fatalf("UpsertMany failed: %v", err)
+	}
+
+	travels, err := travelDao.SelectAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(travels) != 1 || !travels[0].Departure.Equal(rescheduled.Departure) {
+		t.Errorf("Expected the rescheduled travel only, got %v", travels)
+	}
+
+	// Inserting it again with another departure keeps the id once too
+	err = travelDao.InsertMany([]*tables.Transfer{travel})
+	if err != nil {
+		t.Fatalf("InsertMany failed: %v", err)
+	}
+
+	travels, err = travelDao.SelectAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(travels) != 1 || !travels[0].Departure.Equal(departure) {
+		t.Errorf("Expected the inserted travel only, got %v", travels)
+	}
+}
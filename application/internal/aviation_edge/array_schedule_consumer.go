@@ -0,0 +1,17 @@
+package aviation_edge
+
+import "sync"
+
+// ArrayScheduleConsumer keeps the consumed schedules in memory
+type ArrayScheduleConsumer struct {
+	Schedules []ScheduleResponse
+	mu        sync.Mutex
+}
+
+func (a *ArrayScheduleConsumer) Consume(schedules []ScheduleResponse) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.Schedules = append(a.Schedules, schedules...)
+	return nil
+}
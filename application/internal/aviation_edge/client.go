@@ -2,8 +2,10 @@ package aviation_edge
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"time"
@@ -11,12 +13,18 @@ import (
 
 const (
 	BaseURL = "https://aviation-edge.com/v2/public"
+
+	DefaultRequestsPerSecond = 5
 )
 
 type AviationEdgeApiClient struct {
 	APIKey     string
 	HTTPClient *http.Client
 	BaseURL    string
+
+	// RateLimiter and RetryPolicy are optional, without them every request is sent once right away
+	RateLimiter *RateLimiter
+	RetryPolicy *RetryPolicy
 }
 
 func NewAviationEdgeApiClient(apiKey string) *AviationEdgeApiClient {
@@ -26,6 +34,8 @@ func NewAviationEdgeApiClient(apiKey string) *AviationEdgeApiClient {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		RateLimiter: NewRateLimiter(DefaultRequestsPerSecond, DefaultRequestsPerSecond),
+		RetryPolicy: NewDefaultRetryPolicy(),
 	}
 }
 
@@ -46,25 +56,57 @@ func (c *AviationEdgeApiClient) buildURL(endpoint string, params map[string]stri
 	return u.String()
 }
 
+// doRequest sends the GET request respecting the rate limit and repeats it on the transient failures
+// while the retry policy allows
 func (c *AviationEdgeApiClient) doRequest(urlStr string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		body, retryAfter, err := c.doRequestOnce(urlStr)
+		if err == nil {
+			return body, nil
+		}
+
+		var requestErr *RequestError
+		retry := c.RetryPolicy != nil && attempt < c.RetryPolicy.MaxAttempts &&
+			errors.As(err, &requestErr) && requestErr.Transient()
+		if !retry || !c.RetryPolicy.takeRetry() {
+			return nil, err
+		}
+
+		delay := c.RetryPolicy.Delay(attempt, retryAfter)
+		log.Printf("Request attempt %d failed, retrying in %s: %v", attempt, delay, err)
+		c.RetryPolicy.wait(delay)
+	}
+}
+
+// doRequestOnce returns the body of a successful response, or the error and the Retry-After delay of the response
+func (c *AviationEdgeApiClient) doRequestOnce(urlStr string) ([]byte, time.Duration, error) {
+	if c.RateLimiter != nil {
+		c.RateLimiter.Wait()
+	}
+
 	resp, err := c.HTTPClient.Get(urlStr)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, 0, &RequestError{Err: fmt.Errorf("HTTP request failed: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	// Always read body - needed for both success and error responses
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, 0, &RequestError{Err: fmt.Errorf("failed to read response body: %w", err)}
 	}
 
 	// Handle non-200 status codes with error response parsing
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(body, resp.StatusCode, urlStr)
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), c.handleErrorResponse(body, resp.StatusCode, urlStr)
+	}
+
+	// the API answers some errors, the rate limit among them, with status 200, they are retried the same way
+	if err := c.checkForErrorResponse(body); err != nil {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), err
 	}
 
-	return body, nil
+	return body, 0, nil
 }
 
 // handleErrorResponse processes non-200 API responses
@@ -77,7 +119,8 @@ func (c *AviationEdgeApiClient) handleErrorResponse(body []byte, statusCode int,
 	if err := json.Unmarshal(body, &apiError); err == nil {
 		// Check if we got meaningful error data
 		if apiError.IsError() {
-			return fmt.Errorf("API error (%d): %s", statusCode, apiError.ErrorMessage())
+			return newRequestError(statusCode, apiError.ErrorMessage(),
+				fmt.Errorf("API error (%d): %s", statusCode, apiError.ErrorMessage()))
 		}
 	}
 
@@ -86,8 +129,8 @@ func (c *AviationEdgeApiClient) handleErrorResponse(body []byte, statusCode int,
 	if err := json.Unmarshal(body, &anyJSON); err == nil {
 		// Valid JSON but unexpected structure
 		logPath := logUnexpectedResponse(body, statusCode, urlStr)
-		return fmt.Errorf("API returned unexpected JSON format (status %d), response logged to %s",
-			statusCode, logPath)
+		return newRequestError(statusCode, "", fmt.Errorf("API returned unexpected JSON format (status %d), response logged to %s",
+			statusCode, logPath))
 	}
 
 	// 3. Not JSON at all (plain text, HTML, etc.)
@@ -99,8 +142,8 @@ func (c *AviationEdgeApiClient) handleErrorResponse(body []byte, statusCode int,
 		snippet = snippet[:100] + "..."
 	}
 
-	return fmt.Errorf("API returned non-JSON response (status %d): %q, full response logged to %s",
-		statusCode, snippet, logPath)
+	return newRequestError(statusCode, "", fmt.Errorf("API returned non-JSON response (status %d): %q, full response logged to %s",
+		statusCode, snippet, logPath))
 }
 
 // checkForErrorResponse checks if the body contains an API error response
@@ -109,7 +152,7 @@ func (c *AviationEdgeApiClient) handleErrorResponse(body []byte, statusCode int,
 func (c *AviationEdgeApiClient) checkForErrorResponse(body []byte) error {
 	var apiError ErrorResponse
 	if err := json.Unmarshal(body, &apiError); err == nil && apiError.IsError() {
-		return fmt.Errorf("API error: %s", apiError.ErrorMessage())
+		return newRequestError(http.StatusOK, apiError.ErrorMessage(), fmt.Errorf("API error: %s", apiError.ErrorMessage()))
 	}
 	return nil
 }
@@ -0,0 +1,87 @@
+package aviation_edge
+
+import "strings"
+
+// CodeshareLink relates a marketing flight number to the operating flight it is sold on
+type CodeshareLink struct {
+	OperatingFlightIata    string
+	MarketingFlightIata    string
+	MarketingAirlineIata   string
+	DepartureIata          string
+	DepartureScheduledTime string
+}
+
+// IsCodeshare is true for the records of a marketing carrier, Codeshared then holds the operating flight
+func (sr *ScheduleResponse) IsCodeshare() bool {
+	return sr.Codeshared != nil && sr.Codeshared.Flight.IataNumber != ""
+}
+
+// OperatingFlightIataNumber returns the flight number of the physical flight in upper case
+func (sr *ScheduleResponse) OperatingFlightIataNumber() string {
+	if sr.IsCodeshare() {
+		return strings.ToUpper(sr.Codeshared.Flight.IataNumber)
+	}
+
+	return strings.ToUpper(sr.Flight.IataNumber)
+}
+
+// physicalFlightKey identifies a physical flight by its operating number, departure airport and time
+func (sr *ScheduleResponse) physicalFlightKey() string {
+	return sr.OperatingFlightIataNumber() + "|" + sr.Departure.IataCode + "|" + sr.Departure.ScheduledTime
+}
+
+// DeduplicateCodeshares keeps one operating flight record per physical flight and returns the links
+// of the marketing flight numbers to it. When the batch has only marketing records of a flight,
+// the operating record is built of the first of them.
+func DeduplicateCodeshares(schedules []ScheduleResponse) ([]ScheduleResponse, []*CodeshareLink) {
+	var operating []ScheduleResponse
+	var links []*CodeshareLink
+	indexes := make(map[string]int)
+	fromMarketing := make(map[string]bool)
+
+	for _, schedule := range schedules {
+		key := schedule.physicalFlightKey()
+
+		if schedule.IsCodeshare() {
+			links = append(links, &CodeshareLink{
+				OperatingFlightIata:    schedule.OperatingFlightIataNumber(),
+				MarketingFlightIata:    strings.ToUpper(schedule.Flight.IataNumber),
+				MarketingAirlineIata:   strings.ToUpper(schedule.Airline.IataCode),
+				DepartureIata:          schedule.Departure.IataCode,
+				DepartureScheduledTime: schedule.Departure.ScheduledTime,
+			})
+
+			if _, exists := indexes[key]; !exists {
+				indexes[key] = len(operating)
+				fromMarketing[key] = true
+				operating = append(operating, operatingOfCodeshare(schedule))
+			}
+			continue
+		}
+
+		index, exists := indexes[key]
+		if !exists {
+			indexes[key] = len(operating)
+			operating = append(operating, schedule)
+			continue
+		}
+
+		// the real operating record replaces the one built of a marketing record
+		if fromMarketing[key] {
+			operating[index] = schedule
+			fromMarketing[key] = false
+		}
+	}
+
+	return operating, links
+}
+
+// operatingOfCodeshare converts a marketing record into the record of the operating flight
+func operatingOfCodeshare(schedule ScheduleResponse) ScheduleResponse {
+	schedule.Airline = schedule.Codeshared.Airline
+	schedule.Flight = schedule.Codeshared.Flight
+	schedule.Flight.IataNumber = strings.ToUpper(schedule.Flight.IataNumber)
+	schedule.Codeshared = nil
+
+	return schedule
+}
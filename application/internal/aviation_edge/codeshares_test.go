@@ -0,0 +1,70 @@
+package aviation_edge
+
+import "testing"
+
+func makeCodeshareSchedule(flight, airline, operatingFlight, operatingAirline string) ScheduleResponse {
+	schedule := ScheduleResponse{
+		Departure: Departure{IataCode: "VNO", ScheduledTime: "2026-01-15 08:00:00"},
+		Arrival:   Arrival{IataCode: "FRA", ScheduledTime: "2026-01-15 10:00:00"},
+		Airline:   Airline{IataCode: airline, Name: airline},
+		Flight:    Flight{IataNumber: flight},
+	}
+
+	if operatingFlight != "" {
+		schedule.Codeshared = &Codeshared{
+			Airline: Airline{IataCode: operatingAirline, Name: operatingAirline},
+			Flight:  Flight{IataNumber: operatingFlight},
+		}
+	}
+
+	return schedule
+}
+
+func TestDeduplicateCodeshares(t *testing.T) {
+	t.Run("operating record is kept", func(t *testing.T) {
+		schedules := []ScheduleResponse{
+			makeCodeshareSchedule("UA9000", "UA", "lh883", "LH"),
+			makeCodeshareSchedule("LH883", "LH", "", ""),
+			makeCodeshareSchedule("AC4000", "AC", "lh883", "LH"),
+		}
+
+		operating, links := DeduplicateCodeshares(schedules)
+
+		if len(operating) != 1 {
+			t.Fatalf("Expected 1 operating flight, got %d", len(operating))
+		}
+		if operating[0].Flight.IataNumber != "LH883" || operating[0].Codeshared != nil {
+			t.Errorf("Expected operating record LH883 without codeshare, got %v", operating[0])
+		}
+		if len(links) != 2 {
+			t.Fatalf("Expected 2 links, got %d", len(links))
+		}
+		if links[0].OperatingFlightIata != "LH883" || links[0].MarketingFlightIata != "UA9000" {
+			t.Errorf("Expected UA9000 → LH883 link, got %v", links[0])
+		}
+	})
+
+	t.Run("operating record built of marketing one", func(t *testing.T) {
+		operating, links := DeduplicateCodeshares([]ScheduleResponse{
+			makeCodeshareSchedule("UA9000", "UA", "lh883", "LH"),
+		})
+
+		if len(operating) != 1 || len(links) != 1 {
+			t.Fatalf("Expected 1 operating flight and 1 link, got %d and %d", len(operating), len(links))
+		}
+		if operating[0].Flight.IataNumber != "LH883" || operating[0].Airline.IataCode != "LH" {
+			t.Errorf("Expected LH883 operated by LH, got %s by %s", operating[0].Flight.IataNumber, operating[0].Airline.IataCode)
+		}
+	})
+
+	t.Run("different flights are kept", func(t *testing.T) {
+		operating, links := DeduplicateCodeshares([]ScheduleResponse{
+			makeCodeshareSchedule("LH883", "LH", "", ""),
+			makeCodeshareSchedule("BT341", "BT", "", ""),
+		})
+
+		if len(operating) != 2 || len(links) != 0 {
+			t.Errorf("Expected 2 operating flights and no links, got %d and %d", len(operating), len(links))
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package aviation_edge
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Typed errors of the API, check them with errors.Is
+var (
+	ErrRateLimited = errors.New("rate limited")
+	ErrInvalidKey  = errors.New("invalid API key")
+	ErrNoData      = errors.New("no data")
+)
+
+// RequestError is a failed request. StatusCode is 0 when no response was received (connection errors, timeouts),
+// Kind is the typed error the response was recognised as or nil.
+type RequestError struct {
+	StatusCode int
+	Kind       error
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+// Transient tells if the request may succeed when repeated: rate limited, server errors and no response
+func (e *RequestError) Transient() bool {
+	return e.Kind == ErrRateLimited || e.StatusCode == 0 || e.StatusCode >= http.StatusInternalServerError
+}
+
+func newRequestError(statusCode int, message string, err error) *RequestError {
+	return &RequestError{StatusCode: statusCode, Kind: classifyError(statusCode, message), Err: err}
+}
+
+// invalidKeyMessage is the message of the API rejecting the key
+const invalidKeyMessage = "invalid api key"
+
+// classifyError recognises the typed error by the status code or the message of the API
+// (it answers "No Record Found" or "Invalid API key" with status 200 too). Other messages mentioning the key,
+// e.g. of a missing parameter, are not taken for an invalid key.
+func classifyError(statusCode int, message string) error {
+	lower := strings.ToLower(message)
+
+	switch {
+	case statusCode == http.StatusTooManyRequests || strings.Contains(lower, "too many requests") || strings.Contains(lower, "rate limit"):
+		return ErrRateLimited
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden ||
+		strings.TrimSpace(lower) == invalidKeyMessage:
+		return ErrInvalidKey
+	case statusCode == http.StatusNotFound || strings.Contains(lower, "no record") || strings.Contains(lower, "no data"):
+		return ErrNoData
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package fake
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Data is what the fake server answers with
+type Data struct {
+	Airports []aviation_edge.AirportResponse
+	// Timetable is the current schedules
+	Timetable []aviation_edge.ScheduleResponse
+	// Future schedules by date (YYYY-MM-DD), their times are "HH:MM" as of the real endpoint
+	Future map[string][]aviation_edge.ScheduleResponse
+	// History schedules with full local date times
+	History []aviation_edge.ScheduleResponse
+	Routes  []aviation_edge.RouteResponse
+}
+
+// Server is a local Aviation Edge API implementing the endpoints used by the collectors:
+// timetable, flightsFuture, flightsHistory, airportDatabase and routes.
+// Like the real API it answers "No Record Found" with status 200 when nothing matches.
+type Server struct {
+	*httptest.Server
+
+	Key  string
+	Data Data
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func NewServer(key string, data Data) *Server {
+	s := &Server{Key: key, Data: data}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/timetable", s.handleTimetable)
+	mux.HandleFunc("/flightsFuture", s.handleFuture)
+	mux.HandleFunc("/flightsHistory", s.handleHistory)
+	mux.HandleFunc("/airportDatabase", s.handleAirports)
+	mux.HandleFunc("/routes", s.handleRoutes)
+
+	s.Server = httptest.NewServer(s.authorize(mux))
+
+	return s
+}
+
+// NewClient creates a client of the server without rate limiting and retries
+func (s *Server) NewClient() *aviation_edge.AviationEdgeApiClient {
+	client := aviation_edge.NewAviationEdgeApiClient(s.Key)
+	client.BaseURL = s.URL
+	client.RateLimiter = nil
+	client.RetryPolicy = nil
+
+	return client
+}
+
+// Requests returns the paths of the received requests
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.mu.Unlock()
+
+		if r.URL.Query().Get("key") != s.Key {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"success": false, "error": "Invalid API key"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleTimetable(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	writeFound(w, filterSchedules(s.Data.Timetable, query.Get("iataCode"), query.Get("type"), func(aviation_edge.ScheduleResponse) bool {
+		return true
+	}))
+}
+
+func (s *Server) handleFuture(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	writeFound(w, filterSchedules(s.Data.Future[query.Get("date")], query.Get("iataCode"), query.Get("type"), func(aviation_edge.ScheduleResponse) bool {
+		return true
+	}))
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	dateFrom := query.Get("date_from")
+	dateTo := query.Get("date_to")
+	if dateTo == "" {
+		dateTo = dateFrom
+	}
+
+	writeFound(w, filterSchedules(s.Data.History, query.Get("code"), query.Get("type"), func(schedule aviation_edge.ScheduleResponse) bool {
+		scheduled := schedule.Departure.ScheduledTime
+		if query.Get("type") == "arrival" {
+			scheduled = schedule.Arrival.ScheduledTime
+		}
+		date := scheduled[:min(10, len(scheduled))]
+
+		return date >= dateFrom && date <= dateTo
+	}))
+}
+
+func (s *Server) handleAirports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var airports []aviation_edge.AirportResponse
+	for _, airport := range s.Data.Airports {
+		if matches(query.Get("codeIataAirport"), airport.CodeIataAirport) && matches(query.Get("codeIso2Country"), airport.CodeIso2Country) {
+			airports = append(airports, airport)
+		}
+	}
+
+	writeFound(w, airports)
+}
+
+func (s *Server) handleRoutes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var routes []aviation_edge.RouteResponse
+	for _, route := range s.Data.Routes {
+		if matches(query.Get("airlineIata"), route.AirlineIata) && matches(query.Get("departureIata"), route.DepartureIata) {
+			routes = append(routes, route)
+		}
+	}
+
+	writeFound(w, routes)
+}
+
+// filterSchedules keeps the schedules of the airport by the type ("departure" or "arrival") passing the filter
+func filterSchedules(schedules []aviation_edge.ScheduleResponse, iataCode, scheduleType string, filter func(aviation_edge.ScheduleResponse) bool) []aviation_edge.ScheduleResponse {
+	var result []aviation_edge.ScheduleResponse
+	for _, schedule := range schedules {
+		airport := schedule.Departure.IataCode
+		if scheduleType == "arrival" {
+			airport = schedule.Arrival.IataCode
+		}
+
+		if matches(iataCode, airport) && filter(schedule) {
+			schedule.Type = scheduleType
+			result = append(result, schedule)
+		}
+	}
+
+	return result
+}
+
+// matches is true when the parameter is not given or equals the value
+func matches(parameter, value string) bool {
+	return parameter == "" || strings.EqualFold(parameter, value)
+}
+
+func writeFound[T any](w http.ResponseWriter, found []T) {
+	if len(found) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"success": false, "error": "No Record Found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, found)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,102 @@
+package fake
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"errors"
+	"testing"
+)
+
+func makeData() Data {
+	vnoRix := aviation_edge.ScheduleResponse{
+		Departure: aviation_edge.Departure{IataCode: "VNO", ScheduledTime: "06:05"},
+		Arrival:   aviation_edge.Arrival{IataCode: "RIX", ScheduledTime: "07:05"},
+		Flight:    aviation_edge.Flight{IataNumber: "BT342"},
+	}
+	landed := aviation_edge.ScheduleResponse{
+		Status:    "landed",
+		Departure: aviation_edge.Departure{IataCode: "VNO", ScheduledTime: "2025-12-01t06:05:00.000", ActualTime: "2025-12-01t06:20:00.000", Delay: aviation_edge.DelayValue{Value: "15"}},
+		Arrival:   aviation_edge.Arrival{IataCode: "RIX", ScheduledTime: "2025-12-01t07:05:00.000"},
+		Flight:    aviation_edge.Flight{IataNumber: "BT342"},
+	}
+
+	return Data{
+		Airports: []aviation_edge.AirportResponse{
+			{CodeIataAirport: "VNO", CodeIso2Country: "LT", Timezone: "Europe/Vilnius"},
+			{CodeIataAirport: "RIX", CodeIso2Country: "LV", Timezone: "Europe/Riga"},
+		},
+		Timetable: []aviation_edge.ScheduleResponse{vnoRix},
+		Future:    map[string][]aviation_edge.ScheduleResponse{"2026-01-15": {vnoRix}},
+		History:   []aviation_edge.ScheduleResponse{landed},
+		Routes: []aviation_edge.RouteResponse{
+			{AirlineIata: "BT", FlightNumber: "342", DepartureIata: "VNO", DepartureTime: "06:05:00", ArrivalIata: "RIX", ArrivalTime: "07:05:00"},
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer("test-key", makeData())
+	defer server.Close()
+	client := server.NewClient()
+
+	t.Run("future departures and arrivals", func(t *testing.T) {
+		departures, err := client.GetFutureSchedules(aviation_edge.FutureSchedulesParams{IataCode: "VNO", Type: "departure", Date: "2026-01-15"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(departures) != 1 || departures[0].Type != "departure" {
+			t.Errorf("Expected 1 departure, got %v", departures)
+		}
+
+		arrivals, err := client.GetFutureSchedules(aviation_edge.FutureSchedulesParams{IataCode: "RIX", Type: "arrival", Date: "2026-01-15"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(arrivals) != 1 || arrivals[0].Type != "arrival" {
+			t.Errorf("Expected 1 arrival, got %v", arrivals)
+		}
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		_, err := client.GetFutureSchedules(aviation_edge.FutureSchedulesParams{IataCode: "VNO", Type: "departure", Date: "2026-01-16"})
+		if !errors.Is(err, aviation_edge.ErrNoData) {
+			t.Errorf("Expected ErrNoData, got %v", err)
+		}
+	})
+
+	t.Run("history with delays", func(t *testing.T) {
+		schedules, err := client.GetHistoricalSchedules(aviation_edge.HistoricalSchedulesParams{Code: "VNO", Type: "departure", DateFrom: "2025-12-01"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(schedules) != 1 || schedules[0].Departure.Delay.Value != "15" {
+			t.Errorf("Expected 1 schedule delayed by 15, got %v", schedules)
+		}
+	})
+
+	t.Run("timetable, airports and routes", func(t *testing.T) {
+		timetable, err := client.GetFlightSchedules(aviation_edge.FlightSchedulesParams{IataCode: "VNO", Type: "departure"})
+		if err != nil || len(timetable) != 1 {
+			t.Errorf("Expected 1 timetable schedule, got %d, %v", len(timetable), err)
+		}
+
+		airports, err := client.GetAirports(aviation_edge.AirportsParams{CodeIso2Country: "LV"})
+		if err != nil || len(airports) != 1 || airports[0].CodeIataAirport != "RIX" {
+			t.Errorf("Expected airport RIX, got %v, %v", airports, err)
+		}
+
+		routes, err := client.GetAirlineRoutes(aviation_edge.AirlineRoutesParams{AirlineIata: "BT"})
+		if err != nil || len(routes) != 1 {
+			t.Errorf("Expected 1 route, got %d, %v", len(routes), err)
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		client := server.NewClient()
+		client.APIKey = "wrong"
+
+		_, err := client.GetAirports(aviation_edge.AirportsParams{})
+		if !errors.Is(err, aviation_edge.ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey, got %v", err)
+		}
+	})
+}
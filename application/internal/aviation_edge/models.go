@@ -30,6 +30,15 @@ func (d *DelayValue) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON writes the delay as a string like the schedule endpoints, null when it is unknown
+func (d DelayValue) MarshalJSON() ([]byte, error) {
+	if d.Value == "" {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.Value)
+}
+
 func (d DelayValue) String() string {
 	return d.Value
 }
@@ -72,6 +81,11 @@ type Departure struct {
 	ActualTime      string     `json:"actualTime"`
 	EstimatedRunway string     `json:"estimatedRunway"`
 	ActualRunway    string     `json:"actualRunway"`
+
+	// Local times of the airport, set when the times above are normalised to UTC
+	ScheduledTimeLocal string `json:"-"`
+	EstimatedTimeLocal string `json:"-"`
+	ActualTimeLocal    string `json:"-"`
 }
 
 type Arrival struct {
@@ -86,6 +100,11 @@ type Arrival struct {
 	ActualTime      string     `json:"actualTime"`
 	EstimatedRunway string     `json:"estimatedRunway"`
 	ActualRunway    string     `json:"actualRunway"`
+
+	// Local times of the airport, set when the times above are normalised to UTC
+	ScheduledTimeLocal string `json:"-"`
+	EstimatedTimeLocal string `json:"-"`
+	ActualTimeLocal    string `json:"-"`
 }
 
 type Aircraft struct {
@@ -0,0 +1,170 @@
+package aviation_edge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type RecordMode int
+
+const (
+	// ModeReplay answers from the recorded fixtures only, a missing fixture is an error
+	ModeReplay RecordMode = iota
+	// ModeRecord sends every request and records the response
+	ModeRecord
+	// ModeReplayOrRecord answers from the fixture when it is recorded, otherwise sends the request and records it
+	ModeReplayOrRecord
+)
+
+// Fixture is a recorded response
+type Fixture struct {
+	StatusCode int             `json:"statusCode"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	// Text is the body which is not JSON
+	Text string `json:"text,omitempty"`
+}
+
+// RecordingTransport records the API responses to files of Dir and replays them, so that the client
+// and the collectors can be tested offline. The API key is not part of the file names and is scrubbed
+// of the recorded bodies.
+type RecordingTransport struct {
+	Dir  string
+	Mode RecordMode
+	// Next sends the requests being recorded, http.DefaultTransport when nil
+	Next http.RoundTripper
+}
+
+func NewRecordingTransport(dir string, mode RecordMode) *RecordingTransport {
+	return &RecordingTransport{Dir: dir, Mode: mode}
+}
+
+// NewReplayingClient creates a client answering from the fixtures of the directory
+func NewReplayingClient(dir string) *AviationEdgeApiClient {
+	client := NewAviationEdgeApiClient("replay")
+	client.HTTPClient.Transport = NewRecordingTransport(dir, ModeReplay)
+	client.RateLimiter = nil
+	client.RetryPolicy = nil
+
+	return client
+}
+
+func (t *RecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.Dir, FixtureName(request))
+
+	if t.Mode != ModeRecord {
+		fixture, err := readFixture(path)
+		if err == nil {
+			return fixture.response(request), nil
+		}
+		if t.Mode == ModeReplay || !os.IsNotExist(err) {
+			return nil, fmt.Errorf("no fixture to replay %s: %w", path, err)
+		}
+	}
+
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	response, err := next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	key := request.URL.Query().Get("key")
+	if key != "" {
+		body = bytes.ReplaceAll(body, []byte(key), []byte("***"))
+	}
+
+	fixture := &Fixture{StatusCode: response.StatusCode}
+	if json.Valid(body) {
+		fixture.Body = body
+	} else {
+		fixture.Text = string(body)
+	}
+
+	err = writeFixture(path, fixture)
+	if err != nil {
+		return nil, err
+	}
+
+	return fixture.response(request), nil
+}
+
+var fixtureNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_=.,-]+`)
+
+// FixtureName is the file name of the request: the endpoint followed by the sorted query parameters without the key
+func FixtureName(request *http.Request) string {
+	query := request.URL.Query()
+	query.Del("key")
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{filepath.Base(request.URL.Path)}
+	for _, name := range names {
+		parts = append(parts, name+"="+query.Get(name))
+	}
+
+	return fixtureNameUnsafe.ReplaceAllString(strings.Join(parts, "_"), "-") + ".json"
+}
+
+func readFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &Fixture{}
+	err = json.Unmarshal(content, fixture)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+func writeFixture(path string, fixture *Fixture) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+func (f *Fixture) response(request *http.Request) *http.Response {
+	body := []byte(f.Text)
+	if f.Body != nil {
+		body = f.Body
+	}
+
+	return &http.Response{
+		StatusCode: f.StatusCode,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    request,
+	}
+}
@@ -0,0 +1,72 @@
+package aviation_edge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"codeIataAirport": "VNO", "nameAirport": "Vilnius ` + r.URL.Query().Get("key") + `"}]`))
+	}))
+	dir := t.TempDir()
+
+	recording := NewAviationEdgeApiClient("secret-key")
+	recording.BaseURL = server.URL
+	recording.HTTPClient.Transport = NewRecordingTransport(dir, ModeRecord)
+
+	_, err := recording.GetAirports(AirportsParams{CodeIataAirport: "VNO"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	server.Close()
+
+	path := filepath.Join(dir, "airportDatabase_codeIataAirport=VNO.json")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected fixture %s, got %v", path, err)
+	}
+	if strings.Contains(string(content), "secret-key") {
+		t.Errorf("Expected the key to be scrubbed, got %s", content)
+	}
+
+	t.Run("replays the recorded response", func(t *testing.T) {
+		replaying := NewReplayingClient(dir)
+		replaying.BaseURL = server.URL
+
+		airports, err := replaying.GetAirports(AirportsParams{CodeIataAirport: "VNO"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(airports) != 1 || airports[0].NameAirport != "Vilnius ***" {
+			t.Errorf("Expected airport Vilnius ***, got %v", airports)
+		}
+	})
+
+	t.Run("missing fixture", func(t *testing.T) {
+		_, err := NewReplayingClient(dir).GetAirports(AirportsParams{CodeIataAirport: "RIX"})
+		if err == nil || !strings.Contains(err.Error(), "no fixture") {
+			t.Errorf("Expected missing fixture error, got %v", err)
+		}
+	})
+}
+
+func TestReplayFixtures(t *testing.T) {
+	client := NewReplayingClient(filepath.Join("testdata", "fixtures"))
+
+	schedules, err := client.GetFutureSchedules(FutureSchedulesParams{IataCode: "VNO", Type: "departure", Date: "2026-01-15"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(schedules) != 1 {
+		t.Fatalf("Expected 1 schedule, got %d", len(schedules))
+	}
+	if schedules[0].Flight.IataNumber != "BT342" || schedules[0].Departure.ScheduledTime != "06:05" {
+		t.Errorf("Expected BT342 at 06:05, got %s at %s", schedules[0].Flight.IataNumber, schedules[0].Departure.ScheduledTime)
+	}
+}
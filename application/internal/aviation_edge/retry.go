@@ -0,0 +1,122 @@
+package aviation_edge
+
+import (
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket letting Burst requests go at once and Rate requests per second on average
+type RateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewRateLimiter(ratePerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:   ratePerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// Wait blocks until a request may be sent
+func (l *RateLimiter) Wait() {
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+
+	// the token is taken in advance, the next callers wait for the refill after this one
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		l.sleep(delay)
+	}
+}
+
+// RetryPolicy repeats the transient failures (see RequestError.Transient) with exponential backoff and jitter
+type RetryPolicy struct {
+	// MaxAttempts of one request including the first one
+	MaxAttempts int
+	// BaseDelay before the first retry, doubled for every next one up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Budget is the amount of retries shared by all the requests of the client,
+	// so that a long run stops on a lasting outage instead of retrying every request
+	Budget int
+
+	retries int
+	mu      sync.Mutex
+	sleep   func(time.Duration)
+}
+
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+		Budget:      100,
+	}
+}
+
+// takeRetry uses one retry of the budget, false when it is spent
+func (p *RetryPolicy) takeRetry() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.retries >= p.Budget {
+		return false
+	}
+	p.retries++
+
+	return true
+}
+
+// Delay before the retry following the failed attempt (1 based), a random one of the upper half of the backoff.
+// The Retry-After of a rate limited response is respected when it is longer.
+func (p *RetryPolicy) Delay(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := p.BaseDelay << (attempt - 1)
+	if backoff > p.MaxDelay || backoff <= 0 {
+		backoff = p.MaxDelay
+	}
+
+	delay := backoff/2 + rand.N(backoff/2+1)
+
+	return max(delay, retryAfter)
+}
+
+func (p *RetryPolicy) wait(delay time.Duration) {
+	if p.sleep != nil {
+		p.sleep(delay)
+		return
+	}
+	time.Sleep(delay)
+}
+
+// parseRetryAfter reads the Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
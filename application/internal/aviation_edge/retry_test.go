@@ -0,0 +1,214 @@
+package aviation_edge
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// sequenceTransport answers the requests with the given responses in turn, the last one repeatedly
+type sequenceTransport struct {
+	responses []*http.Response
+	errs      []error
+	calls     int
+}
+
+func (m *sequenceTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	i := min(m.calls, len(m.responses)-1)
+	m.calls++
+	if m.errs != nil && m.errs[i] != nil {
+		return nil, m.errs[i]
+	}
+	return m.responses[i], nil
+}
+
+func newResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Header:     make(http.Header),
+	}
+}
+
+func newRetryingClient(transport *sequenceTransport, delays *[]time.Duration) *AviationEdgeApiClient {
+	policy := NewDefaultRetryPolicy()
+	policy.sleep = func(d time.Duration) { *delays = append(*delays, d) }
+
+	return &AviationEdgeApiClient{
+		APIKey:      "test-api-key",
+		BaseURL:     BaseURL,
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: policy,
+	}
+}
+
+func TestDoRequest_Retries(t *testing.T) {
+	t.Run("server error is retried", func(t *testing.T) {
+		var delays []time.Duration
+		transport := &sequenceTransport{responses: []*http.Response{
+			newResponse(503, "Service Unavailable"),
+			newResponse(200, `[{"codeIataAirport": "VNO"}]`),
+		}}
+		client := newRetryingClient(transport, &delays)
+
+		airports, err := client.GetAirports(AirportsParams{CodeIataAirport: "VNO"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(airports) != 1 {
+			t.Errorf("Expected 1 airport, got %d", len(airports))
+		}
+		if transport.calls != 2 {
+			t.Errorf("Expected 2 calls, got %d", transport.calls)
+		}
+		if len(delays) != 1 || delays[0] < 500*time.Millisecond || delays[0] > time.Second {
+			t.Errorf("Expected one delay between 500ms and 1s, got %v", delays)
+		}
+	})
+
+	t.Run("rate limited respects Retry-After", func(t *testing.T) {
+		var delays []time.Duration
+		limited := newResponse(429, `{"error": "Too Many Requests"}`)
+		limited.Header.Set("Retry-After", "7")
+		transport := &sequenceTransport{responses: []*http.Response{limited, newResponse(200, `[]`)}}
+		client := newRetryingClient(transport, &delays)
+
+		_, err := client.GetAirports(AirportsParams{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(delays) != 1 || delays[0] != 7*time.Second {
+			t.Errorf("Expected delay of 7s, got %v", delays)
+		}
+	})
+
+	t.Run("rate limit in a status 200 body is retried", func(t *testing.T) {
+		var delays []time.Duration
+		transport := &sequenceTransport{responses: []*http.Response{
+			newResponse(200, `{"error": "Too Many Requests"}`),
+			newResponse(200, `[{"codeIataAirport": "VNO"}]`),
+		}}
+		client := newRetryingClient(transport, &delays)
+
+		airports, err := client.GetAirports(AirportsParams{CodeIataAirport: "VNO"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(airports) != 1 || transport.calls != 2 || len(delays) != 1 {
+			t.Errorf("Expected 1 airport of 2 calls with 1 delay, got %d airports of %d calls with delays %v", len(airports), transport.calls, delays)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var delays []time.Duration
+		transport := &sequenceTransport{
+			responses: []*http.Response{nil},
+			errs:      []error{errors.New("connection refused")},
+		}
+		client := newRetryingClient(transport, &delays)
+
+		_, err := client.GetAirports(AirportsParams{})
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+		if transport.calls != 5 {
+			t.Errorf("Expected 5 calls, got %d", transport.calls)
+		}
+	})
+
+	t.Run("retry budget is shared", func(t *testing.T) {
+		var delays []time.Duration
+		transport := &sequenceTransport{responses: []*http.Response{newResponse(500, `{"error": "Internal"}`)}}
+		client := newRetryingClient(transport, &delays)
+		client.RetryPolicy.Budget = 3
+
+		_, _ = client.GetAirports(AirportsParams{})
+		_, _ = client.GetAirports(AirportsParams{})
+		if transport.calls != 5 {
+			t.Errorf("Expected 5 calls (4 of the first request, 1 of the second), got %d", transport.calls)
+		}
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		var delays []time.Duration
+		transport := &sequenceTransport{responses: []*http.Response{newResponse(401, `{"error": "Unauthorized"}`)}}
+		client := newRetryingClient(transport, &delays)
+
+		_, err := client.GetAirports(AirportsParams{})
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey, got %v", err)
+		}
+		if transport.calls != 1 {
+			t.Errorf("Expected 1 call, got %d", transport.calls)
+		}
+	})
+}
+
+func TestTypedErrors(t *testing.T) {
+	t.Run("no record found with status 200", func(t *testing.T) {
+		client := newMockClient(200, `{"error": "No Record Found", "success": false}`)
+
+		_, err := client.GetFutureSchedules(FutureSchedulesParams{IataCode: "VNO", Type: "departure", Date: "2026-01-15"})
+		if !errors.Is(err, ErrNoData) {
+			t.Errorf("Expected ErrNoData, got %v", err)
+		}
+	})
+
+	t.Run("invalid key with status 200", func(t *testing.T) {
+		client := newMockClient(200, `{"success": false, "error": "Invalid API key"}`)
+
+		_, err := client.GetAirports(AirportsParams{})
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey, got %v", err)
+		}
+	})
+
+	t.Run("other messages mentioning the key with status 200", func(t *testing.T) {
+		client := newMockClient(200, `{"success": false, "error": "Missing parameter: the api key and the date are required"}`)
+
+		_, err := client.GetAirports(AirportsParams{})
+		if err == nil || errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected an error other than ErrInvalidKey, got %v", err)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		client := newMockClient(429, `Too Many Requests`)
+
+		_, err := client.GetAirports(AirportsParams{})
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected ErrRateLimited, got %v", err)
+		}
+	})
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept time.Duration
+
+	limiter := NewRateLimiter(2, 2)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(d time.Duration) { slept += d; now = now.Add(d) }
+
+	limiter.Wait()
+	limiter.Wait()
+	if slept != 0 {
+		t.Errorf("Expected the burst to pass without waiting, waited %s", slept)
+	}
+
+	limiter.Wait()
+	if slept != 500*time.Millisecond {
+		t.Errorf("Expected to wait 500ms, waited %s", slept)
+	}
+
+	now = now.Add(10 * time.Second)
+	slept = 0
+	limiter.Wait()
+	limiter.Wait()
+	if slept != 0 {
+		t.Errorf("Expected the refilled burst to pass without waiting, waited %s", slept)
+	}
+}
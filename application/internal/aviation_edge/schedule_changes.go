@@ -0,0 +1,116 @@
+package aviation_edge
+
+import "darbelis.eu/persedimai/internal/tables"
+
+// ScheduleChanges are the differences of the fetched schedules from the stored ones of the same airport days
+type ScheduleChanges struct {
+	Changes []*tables.ScheduleChange
+	// Superseded are the stored records not updated by the upsert of the fetched ones:
+	// the flights of another departure time now and the flights removed from the schedules
+	Superseded []*ScheduleResponse
+}
+
+// changeKey matches the records of a flight by the schedule type, operating number, airports and local departure
+// date, so that the flights with changed times are matched
+func changeKey(s *ScheduleResponse) string {
+	return s.Type + "|" + s.OperatingFlightIataNumber() + "|" + s.Departure.IataCode + "|" + s.Arrival.IataCode + "|" + localDepartureDate(s)
+}
+
+func localDepartureDate(s *ScheduleResponse) string {
+	date := s.Departure.ScheduledTimeLocal
+	if date == "" {
+		date = s.Departure.ScheduledTime
+	}
+
+	return date[:min(10, len(date))]
+}
+
+// DetectScheduleChanges compares the fetched operating flights with the stored ones of the same airport days.
+// The stored records must be all the records of the days the fetched ones were collected for, the flights
+// missing in the fetched ones are reported as removed.
+func DetectScheduleChanges(stored []*ScheduleResponse, fetched []ScheduleResponse) *ScheduleChanges {
+	result := &ScheduleChanges{}
+
+	storedByKey := make(map[string][]*ScheduleResponse)
+	for _, s := range stored {
+		key := changeKey(s)
+		storedByKey[key] = append(storedByKey[key], s)
+	}
+
+	for i := range fetched {
+		f := &fetched[i]
+		key := changeKey(f)
+		candidates := storedByKey[key]
+		if len(candidates) == 0 {
+			result.Changes = append(result.Changes, newScheduleChange(tables.ScheduleChangeAdded, f, "", "", f.Departure.ScheduledTime))
+			continue
+		}
+
+		// the record of the same time is updated by the upsert, otherwise the first one is replaced
+		index := 0
+		for j, candidate := range candidates {
+			if candidate.Departure.ScheduledTime == f.Departure.ScheduledTime {
+				index = j
+				break
+			}
+		}
+		s := candidates[index]
+		storedByKey[key] = append(candidates[:index:index], candidates[index+1:]...)
+
+		result.Changes = append(result.Changes, compareSchedules(s, f)...)
+		if s.Departure.ScheduledTime != f.Departure.ScheduledTime {
+			result.Superseded = append(result.Superseded, s)
+		}
+	}
+
+	for _, s := range stored {
+		for _, left := range storedByKey[changeKey(s)] {
+			if left == s {
+				result.Changes = append(result.Changes, newScheduleChange(tables.ScheduleChangeRemoved, s, "", s.Departure.ScheduledTime, ""))
+				result.Superseded = append(result.Superseded, s)
+			}
+		}
+	}
+
+	return result
+}
+
+// compareSchedules reports the time, cancellation and aircraft changes of a flight
+func compareSchedules(s, f *ScheduleResponse) []*tables.ScheduleChange {
+	var changes []*tables.ScheduleChange
+
+	if s.Departure.ScheduledTime != f.Departure.ScheduledTime {
+		changes = append(changes, newScheduleChange(tables.ScheduleChangeTime, f, "dep_scheduled_time", s.Departure.ScheduledTime, f.Departure.ScheduledTime))
+	}
+	if s.Arrival.ScheduledTime != f.Arrival.ScheduledTime && s.Arrival.ScheduledTime != "" && f.Arrival.ScheduledTime != "" {
+		changes = append(changes, newScheduleChange(tables.ScheduleChangeTime, f, "arr_scheduled_time", s.Arrival.ScheduledTime, f.Arrival.ScheduledTime))
+	}
+
+	if (s.Status == "cancelled") != (f.Status == "cancelled") {
+		changes = append(changes, newScheduleChange(tables.ScheduleChangeCancelled, f, "status", s.Status, f.Status))
+	}
+
+	// the missing values are not changes, the schedules are filled closer to the departure
+	if s.Aircraft.IcaoCode != f.Aircraft.IcaoCode && s.Aircraft.IcaoCode != "" && f.Aircraft.IcaoCode != "" {
+		changes = append(changes, newScheduleChange(tables.ScheduleChangeAircraft, f, "aircraft_icao_code", s.Aircraft.IcaoCode, f.Aircraft.IcaoCode))
+	}
+	if s.Aircraft.RegNumber != f.Aircraft.RegNumber && s.Aircraft.RegNumber != "" && f.Aircraft.RegNumber != "" {
+		changes = append(changes, newScheduleChange(tables.ScheduleChangeAircraft, f, "aircraft_reg_number", s.Aircraft.RegNumber, f.Aircraft.RegNumber))
+	}
+
+	return changes
+}
+
+func newScheduleChange(changeType string, s *ScheduleResponse, field, before, after string) *tables.ScheduleChange {
+	return &tables.ScheduleChange{
+		ChangeType:       changeType,
+		Type:             s.Type,
+		FlightIataNumber: s.OperatingFlightIataNumber(),
+		DepIataCode:      s.Departure.IataCode,
+		ArrIataCode:      s.Arrival.IataCode,
+		DepDate:          localDepartureDate(s),
+		Field:            field,
+		BeforeValue:      before,
+		AfterValue:       after,
+	}
+}
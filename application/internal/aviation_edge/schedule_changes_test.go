@@ -0,0 +1,62 @@
+package aviation_edge
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+)
+
+func makeChangeSchedule(flight, depTime, arrTime string) ScheduleResponse {
+	return ScheduleResponse{
+		Type:      "departure",
+		Status:    "future",
+		Departure: Departure{IataCode: "VNO", ScheduledTime: depTime, ScheduledTimeLocal: depTime},
+		Arrival:   Arrival{IataCode: "RIX", ScheduledTime: arrTime},
+		Flight:    Flight{IataNumber: flight},
+		Aircraft:  Aircraft{IcaoCode: "BCS3"},
+	}
+}
+
+func TestDetectScheduleChanges(t *testing.T) {
+	unchanged := makeChangeSchedule("BT342", "2026-01-15 06:05:00", "2026-01-15 07:05:00")
+	retimed := makeChangeSchedule("BT344", "2026-01-15 12:00:00", "2026-01-15 13:00:00")
+	cancelled := makeChangeSchedule("BT346", "2026-01-15 18:00:00", "2026-01-15 19:00:00")
+	removed := makeChangeSchedule("BT348", "2026-01-15 20:00:00", "2026-01-15 21:00:00")
+	stored := []*ScheduleResponse{&unchanged, &retimed, &cancelled, &removed}
+
+	fetchedRetimed := makeChangeSchedule("BT344", "2026-01-15 12:30:00", "2026-01-15 13:30:00")
+	fetchedCancelled := makeChangeSchedule("BT346", "2026-01-15 18:00:00", "2026-01-15 19:00:00")
+	fetchedCancelled.Status = "cancelled"
+	fetchedCancelled.Aircraft.IcaoCode = "A320"
+	fetchedUnchanged := makeChangeSchedule("BT342", "2026-01-15 06:05:00", "2026-01-15 07:05:00")
+	fetchedUnchanged.Aircraft.IcaoCode = ""
+	added := makeChangeSchedule("BT350", "2026-01-15 22:00:00", "2026-01-15 23:00:00")
+
+	result := DetectScheduleChanges(stored, []ScheduleResponse{fetchedUnchanged, fetchedRetimed, fetchedCancelled, added})
+
+	expected := []tables.ScheduleChange{
+		{ChangeType: tables.ScheduleChangeTime, FlightIataNumber: "BT344", Field: "dep_scheduled_time", BeforeValue: "2026-01-15 12:00:00", AfterValue: "2026-01-15 12:30:00"},
+		{ChangeType: tables.ScheduleChangeTime, FlightIataNumber: "BT344", Field: "arr_scheduled_time", BeforeValue: "2026-01-15 13:00:00", AfterValue: "2026-01-15 13:30:00"},
+		{ChangeType: tables.ScheduleChangeCancelled, FlightIataNumber: "BT346", Field: "status", BeforeValue: "future", AfterValue: "cancelled"},
+		{ChangeType: tables.ScheduleChangeAircraft, FlightIataNumber: "BT346", Field: "aircraft_icao_code", BeforeValue: "BCS3", AfterValue: "A320"},
+		{ChangeType: tables.ScheduleChangeAdded, FlightIataNumber: "BT350", AfterValue: "2026-01-15 22:00:00"},
+		{ChangeType: tables.ScheduleChangeRemoved, FlightIataNumber: "BT348", BeforeValue: "2026-01-15 20:00:00"},
+	}
+
+	if len(result.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %v", len(expected), len(result.Changes), result.Changes)
+	}
+	for i, e := range expected {
+		c := result.Changes[i]
+		if c.ChangeType != e.ChangeType || c.FlightIataNumber != e.FlightIataNumber || c.Field != e.Field ||
+			c.BeforeValue != e.BeforeValue || c.AfterValue != e.AfterValue {
+			t.Errorf("Expected change %v, got %v", e, *c)
+		}
+		if c.DepIataCode != "VNO" || c.ArrIataCode != "RIX" || c.DepDate != "2026-01-15" {
+			t.Errorf("Expected VNO → RIX on 2026-01-15, got %s → %s on %s", c.DepIataCode, c.ArrIataCode, c.DepDate)
+		}
+	}
+
+	if len(result.Superseded) != 2 || result.Superseded[0] != &retimed || result.Superseded[1] != &removed {
+		t.Errorf("Expected the retimed and removed records superseded, got %v", result.Superseded)
+	}
+}
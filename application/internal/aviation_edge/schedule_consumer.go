@@ -2,7 +2,7 @@ package aviation_edge
 
 // ScheduleConsumer interface for processing schedule data as it's collected
 type ScheduleConsumer interface {
-	// Consume processes a batch of schedule responses
+	// Consume processes a batch of schedule responses, all the schedules of one airport day fetched by one request
 	// Returns error if processing fails
 	Consume(schedules []ScheduleResponse) error
 }
@@ -0,0 +1,245 @@
+package bridge
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/migrations"
+	"darbelis.eu/persedimai/internal/tables"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SCHEDULES_SYNC_NAME is the name of the flight_schedules → travels synchronisation in the sync_state table
+const SCHEDULES_SYNC_NAME = "flight_schedules"
+
+// SchedulesBridge converts imported airports into points and flight schedules into travels,
+// so that the search strategies can run over the real flights.
+// Conversion is idempotent: airport IATA codes are point ids and travel ids are built of the flight number,
+// the scheduled departure and the airports, so repeated runs update the same rows.
+type SchedulesBridge struct {
+	db *database.Database
+}
+
+func NewSchedulesBridge(db *database.Database) *SchedulesBridge {
+	return &SchedulesBridge{db: db}
+}
+
+// SyncResult holds the amounts of rows changed by a synchronisation
+type SyncResult struct {
+	Points  int64
+	Travels int64
+	// DeletedTravels of the schedules removed, rescheduled or cancelled since the previous sync
+	DeletedTravels int64
+	// Since is the updated_at of flight schedules the travels were synced from, zero for a full sync
+	Since time.Time
+	Until time.Time
+}
+
+// CreateSyncStateTableSQL returns the table keeping the last synchronised updated_at of the source tables
+func (b *SchedulesBridge) CreateSyncStateTableSQL() string {
+	return `create table if not exists sync_state (
+		name varchar(64) not null primary key,
+		synced_until datetime not null
+	)`
+}
+
+// UpsertPointsSQL creates or updates points of all airports, longitude is x and latitude is y
+func (b *SchedulesBridge) UpsertPointsSQL() string {
+	return `insert into points (id, name, x, y)
+		select a.code_iata_airport, a.name_airport, a.longitude_airport, a.latitude_airport
+		from airports a
+		on duplicate key update name = values(name), x = values(x), y = values(y)`
+}
+
+// TravelIdSQL builds the id of a travel of a flight schedule row aliased fs.
+// Codeshare records get the id of their operating flight, so every physical flight becomes one travel.
+// tables.FlightTravelID and tables.ParseFlightTravelID are its Go counterparts.
+func (b *SchedulesBridge) TravelIdSQL() string {
+	return `concat(upper(coalesce(nullif(fs.codeshared_flight_iata, ''), fs.flight_iata_number)), '_', date_format(fs.dep_scheduled_time, '%Y%m%d%H%i'), '_', fs.dep_iata_code, fs.arr_iata_code)`
+}
+
+// UpsertTravelsSQL creates or updates travels of the departure records of flight schedules updated since the given
+// time (zero for all). The airline and the service number of a travel are the operating ones, as codeshare records
+// refer to the operating flight. Only flights between known airports are converted, cancelled flights, flights
+// not arriving after the departure and records stored in local times before the times were normalised to UTC
+// (without local times, see _import.BackfillLocalTimes) are skipped.
+func (b *SchedulesBridge) UpsertTravelsSQL(since time.Time) string {
+	sinceCondition := ""
+	if !since.IsZero() {
+		sinceCondition = fmt.Sprintf("and fs.updated_at >= '%s'", since.Format(time.DateTime))
+	}
+
+	return fmt.Sprintf(`insert into travels (id, from_point, to_point, departure, arrival, airline, service_number, mode, vehicle_type, terminal, departure_cl, arrival_cl, departure8_cl, arrival8_cl)
+		select %s, fs.dep_iata_code, fs.arr_iata_code, fs.dep_scheduled_time, fs.arr_scheduled_time,
+			upper(coalesce(nullif(fs.codeshared_airline_iata, ''), fs.airline_iata_code)),
+			upper(coalesce(nullif(fs.codeshared_flight_iata, ''), fs.flight_iata_number)),
+			'%s', nullif(fs.aircraft_iata_code, ''), nullif(fs.dep_terminal, ''),
+			floor(unix_timestamp(fs.dep_scheduled_time) / %d),
+			floor(unix_timestamp(fs.arr_scheduled_time) / %d),
+			floor(unix_timestamp(fs.dep_scheduled_time) / %d),
+			floor(unix_timestamp(fs.arr_scheduled_time) / %d)
+		from flight_schedules fs
+		join points p1 on p1.id = fs.dep_iata_code
+		join points p2 on p2.id = fs.arr_iata_code
+		where fs.type = 'departure'
+			and fs.status <> 'cancelled'
+			and fs.arr_scheduled_time > fs.dep_scheduled_time
+			and fs.dep_scheduled_time_local is not null
+			%s
+		on duplicate key update
+			from_point = values(from_point),
+			to_point = values(to_point),
+			arrival = values(arrival),
+			airline = values(airline),
+			service_number = values(service_number),
+			vehicle_type = values(vehicle_type),
+			terminal = values(terminal),
+			arrival_cl = values(arrival_cl),
+			arrival8_cl = values(arrival8_cl)`,
+		b.TravelIdSQL(), tables.TransportModeFlight,
+		migrations.CLUSTER_SECONDS, migrations.CLUSTER_SECONDS,
+		migrations.CLUSTER8_SECONDS, migrations.CLUSTER8_SECONDS,
+		sinceCondition)
+}
+
+// DeleteStaleTravelsSQL deletes the travels of the flights logged in flight_schedule_changes as removed, rescheduled
+// or cancelled since the given time (zero for all), which are no longer converted from a departure record.
+// The travel ids embed the departure time, so the travels of the old times are not updated by UpsertTravelsSQL.
+// The travels of the flight in a day around its local departure date are checked, as the travels are in UTC.
+func (b *SchedulesBridge) DeleteStaleTravelsSQL(since time.Time) string {
+	sinceCondition := ""
+	if !since.IsZero() {
+		sinceCondition = fmt.Sprintf("and c.imported_at >= '%s'", since.Format(time.DateTime))
+	}
+
+	return fmt.Sprintf(`delete t from travels t
+		join flight_schedule_changes c
+			on t.id like concat(upper(c.flight_iata_number), '\_%%')
+			and t.from_point = c.dep_iata_code and t.to_point = c.arr_iata_code
+			and t.departure >= c.dep_date - interval 1 day and t.departure < c.dep_date + interval 2 day
+		where c.type = 'departure'
+			and c.change_type in ('%s', '%s', '%s')
+			%s
+			and not exists (
+				select 1 from flight_schedules fs
+				where fs.dep_iata_code = t.from_point
+					and fs.dep_scheduled_time = t.departure
+					and fs.type = 'departure'
+					and fs.status <> 'cancelled'
+					and fs.arr_scheduled_time > fs.dep_scheduled_time
+					and %s = t.id
+			)`,
+		tables.ScheduleChangeRemoved, tables.ScheduleChangeTime, tables.ScheduleChangeCancelled,
+		sinceCondition, b.TravelIdSQL())
+}
+
+// Sync converts airports and the flight schedules changed since the previous sync and deletes the travels of the
+// schedules gone since. When full is true all the flight schedules are converted.
+// The flight_schedule_changes table must exist, see migrations.CreateFlightScheduleChangesTable.
+func (b *SchedulesBridge) Sync(full bool) (*SyncResult, error) {
+	dbConn, err := b.db.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = dbConn.Exec(b.CreateSyncStateTableSQL())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+
+	if !full {
+		result.Since, err = b.getSyncedUntil(dbConn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var until sql.NullTime
+	err = dbConn.QueryRow("select max(updated_at) from flight_schedules").Scan(&until)
+	if err != nil {
+		return nil, err
+	}
+	if !until.Valid {
+		log.Println("No flight schedules to sync")
+		return result, nil
+	}
+	result.Until = until.Time
+
+	err = b.ensurePartitions(dbConn, result.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Points, err = b.UpsertPoints()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := b.UpsertTravelsSQL(result.Since)
+	log.Println("Running sql : " + sqlQuery)
+	sqlResult, err := dbConn.Exec(sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert travels : %w", err)
+	}
+	result.Travels, _ = sqlResult.RowsAffected()
+
+	sqlQuery = b.DeleteStaleTravelsSQL(result.Since)
+	log.Println("Running sql : " + sqlQuery)
+	sqlResult, err = dbConn.Exec(sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete stale travels : %w", err)
+	}
+	result.DeletedTravels, _ = sqlResult.RowsAffected()
+
+	_, err = dbConn.Exec(`insert into sync_state (name, synced_until) values (?, ?)
+		on duplicate key update synced_until = values(synced_until)`, SCHEDULES_SYNC_NAME, result.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpsertPoints creates or updates the points of all airports, returns the amount of affected rows
+func (b *SchedulesBridge) UpsertPoints() (int64, error) {
+	dbConn, err := b.db.GetConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	sqlResult, err := dbConn.Exec(b.UpsertPointsSQL())
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert points : %w", err)
+	}
+
+	return sqlResult.RowsAffected()
+}
+
+func (b *SchedulesBridge) getSyncedUntil(dbConn *sql.DB) (time.Time, error) {
+	var syncedUntil time.Time
+	err := dbConn.QueryRow("select synced_until from sync_state where name = ?", SCHEDULES_SYNC_NAME).Scan(&syncedUntil)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+
+	return syncedUntil, err
+}
+
+// ensurePartitions adds month partitions to travels for the departures being synced
+func (b *SchedulesBridge) ensurePartitions(dbConn *sql.DB, since time.Time) error {
+	var minDeparture, maxDeparture sql.NullTime
+	err := dbConn.QueryRow("select min(dep_scheduled_time), max(dep_scheduled_time) from flight_schedules where updated_at >= ?", since).
+		Scan(&minDeparture, &maxDeparture)
+	if err != nil {
+		return err
+	}
+
+	if !minDeparture.Valid || !maxDeparture.Valid {
+		return nil
+	}
+
+	return migrations.NewPartitionsManager(b.db).EnsureMonthPartitions(migrations.TravelsPartitionedTable, minDeparture.Time, maxDeparture.Time)
+}
@@ -0,0 +1,65 @@
+package bridge
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedulesBridge_UpsertTravelsSQL(t *testing.T) {
+	bridge := NewSchedulesBridge(nil)
+
+	t.Run("full sync", func(t *testing.T) {
+		sql := bridge.UpsertTravelsSQL(time.Time{})
+		if strings.Contains(sql, "updated_at") {
+			t.Errorf("Expected no updated_at condition for a full sync, got %s", sql)
+		}
+		if !strings.Contains(sql, "'%Y%m%d%H%i'") {
+			t.Errorf("Expected travel id built of the departure time, got %s", sql)
+		}
+		if !strings.Contains(sql, "coalesce(nullif(fs.codeshared_flight_iata, ''), fs.flight_iata_number)") {
+			t.Errorf("Expected codeshares to get the id of the operating flight, got %s", sql)
+		}
+		if !strings.Contains(sql, "upper(coalesce(nullif(fs.codeshared_airline_iata, ''), fs.airline_iata_code))") {
+			t.Errorf("Expected travels to get the operating airline, got %s", sql)
+		}
+		if !strings.Contains(sql, "'flight', nullif(fs.aircraft_iata_code, ''), nullif(fs.dep_terminal, '')") {
+			t.Errorf("Expected flight mode, aircraft type and terminal of the travels, got %s", sql)
+		}
+		if !strings.Contains(sql, "fs.type = 'departure'") {
+			t.Errorf("Expected the departure records converted only, got %s", sql)
+		}
+		if !strings.Contains(sql, "floor(unix_timestamp(fs.dep_scheduled_time) / 3600)") {
+			t.Errorf("Expected departure cluster calculated, got %s", sql)
+		}
+	})
+
+	t.Run("incremental sync", func(t *testing.T) {
+		since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		sql := bridge.UpsertTravelsSQL(since)
+		if !strings.Contains(sql, "fs.updated_at >= '2026-01-02 03:04:05'") {
+			t.Errorf("Expected updated_at condition, got %s", sql)
+		}
+	})
+}
+
+func TestSchedulesBridge_DeleteStaleTravelsSQL(t *testing.T) {
+	bridge := NewSchedulesBridge(nil)
+
+	sql := bridge.DeleteStaleTravelsSQL(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if !strings.Contains(sql, "c.change_type in ('removed', 'time', 'cancelled')") {
+		t.Errorf("Expected the removed, rescheduled and cancelled flights, got %s", sql)
+	}
+	if !strings.Contains(sql, "c.imported_at >= '2026-01-02 03:04:05'") {
+		t.Errorf("Expected the changes since the previous sync, got %s", sql)
+	}
+	if !strings.Contains(sql, `like concat(upper(c.flight_iata_number), '\_%')`) {
+		t.Errorf("Expected the travels of the flight number, got %s", sql)
+	}
+	if !strings.Contains(sql, bridge.TravelIdSQL()+" = t.id") {
+		t.Errorf("Expected the travels still converted from a departure record kept, got %s", sql)
+	}
+	if strings.Contains(bridge.DeleteStaleTravelsSQL(time.Time{}), "imported_at") {
+		t.Error("Expected all the changes of a full sync")
+	}
+}
@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/import"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimetableExpander expands the undated airline routes into dated travels, one per operating day.
+// Travel ids are built the same way as of the flight schedules (see TravelIdSQL), so the travels
+// of a route and of the collected schedules of the same flight do not duplicate each other.
+type TimetableExpander struct {
+	normalizer *_import.ScheduleTimeNormalizer
+}
+
+func NewTimetableExpander(normalizer *_import.ScheduleTimeNormalizer) *TimetableExpander {
+	return &TimetableExpander{normalizer: normalizer}
+}
+
+// Expand returns the travels of the route departing in the local days from dateFrom to dateTo (YYYY-MM-DD)
+// inclusive, skipping the days not in the operating days of the route
+func (e *TimetableExpander) Expand(route *tables.Route, dateFrom, dateTo string) ([]*tables.Transfer, error) {
+	days, err := util.GenerateDateRange(dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var travels []*tables.Transfer
+	for _, day := range days {
+		if !OperatesOn(route.DaysOfWeek, util.ParseDate(day).Weekday()) {
+			continue
+		}
+
+		schedule := &aviation_edge.ScheduleResponse{
+			Departure: aviation_edge.Departure{IataCode: route.DepartureIata, ScheduledTime: route.DepartureTime},
+			Arrival:   aviation_edge.Arrival{IataCode: route.ArrivalIata, ScheduledTime: route.ArrivalTime},
+		}
+		err = e.normalizer.Normalize(schedule, day)
+		if err != nil {
+			return nil, fmt.Errorf("route %s %s → %s: %w", route.FlightIataNumber(), route.DepartureIata, route.ArrivalIata, err)
+		}
+
+		departure := util.ParseDateTime(schedule.Departure.ScheduledTime)
+		arrival := util.ParseDateTime(schedule.Arrival.ScheduledTime)
+
+		travels = append(travels, &tables.Transfer{
+			ID:            tables.FlightTravelID(route.FlightIataNumber(), departure, route.DepartureIata, route.ArrivalIata),
+			From:          route.DepartureIata,
+			To:            route.ArrivalIata,
+			Departure:     departure,
+			Arrival:       arrival,
+			Airline:       strings.ToUpper(route.AirlineIata),
+			ServiceNumber: strings.ToUpper(route.FlightIataNumber()),
+			Mode:          tables.TransportModeFlight,
+		})
+	}
+
+	return travels, nil
+}
+
+// OperatesOn tells if the ISO weekdays pattern (like "135") contains the weekday, an empty pattern means every day
+func OperatesOn(daysOfWeek string, weekday time.Weekday) bool {
+	if daysOfWeek == "" {
+		return true
+	}
+
+	isoWeekday := int(weekday)
+	if weekday == time.Sunday {
+		isoWeekday = 7
+	}
+
+	return strings.Contains(daysOfWeek, strconv.Itoa(isoWeekday))
+}
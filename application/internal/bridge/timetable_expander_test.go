@@ -0,0 +1,66 @@
+package bridge
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/import"
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+func TestTimetableExpander_Expand(t *testing.T) {
+	timezones := _import.NewAirportTimezones([]*aviation_edge.AirportResponse{
+		{CodeIataAirport: "VNO", Timezone: "Europe/Vilnius"},
+		{CodeIataAirport: "JFK", Timezone: "America/New_York"},
+	})
+	expander := NewTimetableExpander(_import.NewScheduleTimeNormalizer(timezones))
+
+	t.Run("every day", func(t *testing.T) {
+		route := &tables.Route{AirlineIata: "LY", FlightNumber: "5102", DepartureIata: "JFK", DepartureTime: "18:00:00", ArrivalIata: "VNO", ArrivalTime: "09:30:00"}
+		travels, err := expander.Expand(route, "2026-01-15", "2026-01-17")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(travels) != 3 {
+			t.Fatalf("Expected 3 travels, got %d", len(travels))
+		}
+		if travels[0].ID != "LY5102_202601152300_JFKVNO" {
+			t.Errorf("Expected id LY5102_202601152300_JFKVNO, got %s", travels[0].ID)
+		}
+		if travels[0].Airline != "LY" || travels[0].ServiceNumber != "LY5102" || travels[0].Mode != "flight" {
+			t.Errorf("Expected LY flight LY5102, got %s %s %s", travels[0].Airline, travels[0].Mode, travels[0].ServiceNumber)
+		}
+		if !travels[0].Arrival.Equal(time.Date(2026, 1, 16, 7, 30, 0, 0, time.UTC)) {
+			t.Errorf("Expected arrival 2026-01-16 07:30 UTC, got %s", travels[0].Arrival)
+		}
+	})
+
+	t.Run("operating days", func(t *testing.T) {
+		// 2026-01-12 is Monday
+		route := &tables.Route{AirlineIata: "LY", FlightNumber: "5101", DepartureIata: "VNO", DepartureTime: "10:00", ArrivalIata: "JFK", ArrivalTime: "11:30", DaysOfWeek: "37"}
+		travels, err := expander.Expand(route, "2026-01-12", "2026-01-18")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(travels) != 2 {
+			t.Fatalf("Expected 2 travels, got %d", len(travels))
+		}
+		if travels[0].Departure.Format(time.DateOnly) != "2026-01-14" || travels[1].Departure.Format(time.DateOnly) != "2026-01-18" {
+			t.Errorf("Expected departures on 2026-01-14 and 2026-01-18, got %s and %s", travels[0].Departure, travels[1].Departure)
+		}
+	})
+}
+
+func TestOperatesOn(t *testing.T) {
+	if !OperatesOn("", time.Wednesday) {
+		t.Error("Expected empty pattern to operate every day")
+	}
+	if !OperatesOn("67", time.Sunday) {
+		t.Error("Expected Sunday to be the 7th ISO weekday")
+	}
+	if OperatesOn("12345", time.Saturday) {
+		t.Error("Expected no operation on Saturday")
+	}
+}
@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/util"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AirlinesDao keeps the airlines of Aviation Edge airline database and of OpenFlights
+type AirlinesDao struct {
+	database *database.Database
+}
+
+func NewAirlinesDao(database *database.Database) *AirlinesDao {
+	return &AirlinesDao{database: database}
+}
+
+func (dao *AirlinesDao) GetTableFields() []string {
+	return []string{
+		"airline_id",
+		"name_airline",
+		"code_iata_airline",
+		"code_icao_airline",
+		"callsign",
+		"status_airline",
+		"type",
+		"size_airline",
+		"age_fleet",
+		"founding",
+		"code_hub",
+		"name_country",
+		"code_iso2_country",
+	}
+}
+
+// Upsert inserts the airlines or updates the ones with the same airline_id
+func (dao *AirlinesDao) Upsert(airlines []*aviation_edge.AirlineResponse) error {
+	if len(airlines) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(airlines))
+	for i, airline := range airlines {
+		values := []string{
+			strconv.Itoa(airline.AirlineID),
+			airline.NameAirline,
+			strings.ToUpper(airline.CodeIataAirline),
+			strings.ToUpper(airline.CodeIcaoAirline),
+			airline.CallSign,
+			airline.StatusAirline,
+			airline.Type,
+			strconv.Itoa(airline.SizeAirline),
+			fmt.Sprintf("%f", airline.AgeFleet),
+			strconv.Itoa(airline.Founding),
+			airline.CodeHub,
+			airline.NameCountry,
+			airline.CodeIso2Country,
+		}
+		lines[i] = "(" + strings.Join(util.ArrayMap(values, util.QuoteStringOrNull), ",") + ")"
+	}
+
+	fields := dao.GetTableFields()
+	updates := make([]string, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		updates = append(updates, field+" = VALUES("+field+")")
+	}
+
+	sqlQuery := `INSERT INTO airlines (` + strings.Join(fields, ", ") + `) VALUES ` +
+		strings.Join(lines, ",\n") + ` ON DUPLICATE KEY UPDATE ` + strings.Join(updates, ", ")
+
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindNames returns the airline names by the given IATA codes, the codes not found are left out
+func (dao *AirlinesDao) FindNames(codes []string) (map[string]string, error) {
+	names := make(map[string]string)
+	if len(codes) == 0 {
+		return names, nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT code_iata_airline, MIN(name_airline)
+		FROM airlines
+		WHERE code_iata_airline IN (` + strings.Join(util.ArrayMap(codes, util.QuoteString), ",") + `)
+		GROUP BY code_iata_airline`
+
+	rows, err := connection.Query(sqlQuery)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, name string
+		err = rows.Scan(&code, &name)
+		if err != nil {
+			return nil, err
+		}
+		names[code] = name
+	}
+
+	return names, rows.Err()
+}
@@ -4,36 +4,84 @@ import (
 	"darbelis.eu/persedimai/internal/database"
 	"darbelis.eu/persedimai/internal/tables"
 	"database/sql"
+	"errors"
+	"time"
 )
 
+// AirportsMetaDao tracks the imported days per airport and schedule type and the state of the imports per airport,
+// imported_from and imported_to are the first and the last imported day, the days between them may be missing.
+// The future schedules are tracked in airports_meta and airports_meta_days, the historical ones
+// in airports_history_meta and airports_history_meta_days of the same structure.
 type AirportsMetaDao struct {
-	database *database.Database
+	database  *database.Database
+	table     string
+	daysTable string
+	// schedulesCondition selects the flight_schedules rows of the tracked schedules
+	schedulesCondition string
 }
 
 func NewAirportsMetaDao(database *database.Database) *AirportsMetaDao {
-	return &AirportsMetaDao{database: database}
+	return &AirportsMetaDao{database: database, table: "airports_meta", daysTable: "airports_meta_days",
+		schedulesCondition: "fs.status = 'future'"}
 }
 
-// CreateTable creates the airports_meta table if it doesn't exist
+func NewAirportsHistoryMetaDao(database *database.Database) *AirportsMetaDao {
+	return &AirportsMetaDao{database: database, table: "airports_history_meta", daysTable: "airports_history_meta_days",
+		schedulesCondition: "fs.status <> 'future'"}
+}
+
+// CreateTable creates the metadata table if it doesn't exist
 func (dao *AirportsMetaDao) CreateTable() error {
 	conn, err := dao.database.GetConnection()
 	if err != nil {
 		return err
 	}
 
-	sqlQuery := `CREATE TABLE IF NOT EXISTS airports_meta (
+	sqlQuery := `CREATE TABLE IF NOT EXISTS ` + dao.table + ` (
 		-- Primary key
 		airport_code VARCHAR(3) PRIMARY KEY COMMENT 'IATA airport code',
 
 		-- Import tracking
-		imported_from DATETIME COMMENT 'Start date of imported data',
-		imported_to DATETIME COMMENT 'End date of imported data',
+		imported_from DATETIME COMMENT 'first imported day',
+		imported_to DATETIME COMMENT 'last imported day, the days between may be missing',
+
+		-- State of the last import, the imported days are tracked in the days table
+		import_status VARCHAR(16) COMMENT 'in_progress, done or failed',
+		last_error TEXT COMMENT 'error of the failed import',
 
 		-- Metadata
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'record creation timestamp',
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'record update timestamp'
 	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Metadata tracking for airport data imports'`
 
+	_, err = conn.Exec(sqlQuery)
+	if err != nil {
+		return err
+	}
+
+	// the tables created before the import state tracking, the collected range of the unfinished imports
+	// is replaced by the imported days
+	sqlQuery = `ALTER TABLE ` + dao.table + `
+		ADD COLUMN IF NOT EXISTS import_status VARCHAR(16) COMMENT 'in_progress, done or failed' AFTER imported_to,
+		ADD COLUMN IF NOT EXISTS last_error TEXT COMMENT 'error of the failed import' AFTER import_status,
+		DROP COLUMN IF EXISTS collected_from,
+		DROP COLUMN IF EXISTS collected_to`
+
+	_, err = conn.Exec(sqlQuery)
+	if err != nil {
+		return err
+	}
+
+	sqlQuery = `CREATE TABLE IF NOT EXISTS ` + dao.daysTable + ` (
+		airport_code VARCHAR(3) NOT NULL COMMENT 'IATA airport code',
+		type VARCHAR(32) NOT NULL COMMENT 'departure or arrival schedules',
+		day DATE NOT NULL COMMENT 'imported local day',
+
+		imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'import time',
+
+		PRIMARY KEY (airport_code, type, day)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Imported days per airport and schedule type'`
+
 	_, err = conn.Exec(sqlQuery)
 
 	return err
@@ -49,14 +97,14 @@ func (dao *AirportsMetaDao) Upsert(meta *tables.AirportMeta, updateDates bool) e
 
 	var sqlQuery string
 	if updateDates {
-		sqlQuery = `INSERT INTO airports_meta (airport_code, imported_from, imported_to)
+		sqlQuery = `INSERT INTO ` + dao.table + ` (airport_code, imported_from, imported_to)
 			VALUES (?, ?, ?)
 			ON DUPLICATE KEY UPDATE
 				imported_from = VALUES(imported_from),
 				imported_to = VALUES(imported_to),
 				updated_at = CURRENT_TIMESTAMP`
 	} else {
-		sqlQuery = `INSERT INTO airports_meta (airport_code, imported_from, imported_to)
+		sqlQuery = `INSERT INTO ` + dao.table + ` (airport_code, imported_from, imported_to)
 			VALUES (?, ?, ?)
 			ON DUPLICATE KEY UPDATE
 				updated_at = CURRENT_TIMESTAMP`
@@ -74,39 +122,18 @@ func (dao *AirportsMetaDao) Get(airportCode string) (*tables.AirportMeta, error)
 		return nil, err
 	}
 
-	sqlQuery := `SELECT airport_code, imported_from, imported_to
-		FROM airports_meta
+	sqlQuery := `SELECT ` + airportMetaColumns + `
+		FROM ` + dao.table + `
 		WHERE airport_code = ?
 		LIMIT 1`
 
-	row := conn.QueryRow(sqlQuery, airportCode)
-
-	meta := &tables.AirportMeta{}
-	var importedFrom, importedTo sql.NullTime
-
-	err = row.Scan(
-		&meta.AirportCode,
-		&importedFrom,
-		&importedTo,
-	)
+	meta, err := scanAirportMeta(conn.QueryRow(sqlQuery, airportCode))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 
-	if err != nil {
-		return nil, err
-	}
-
-	// Assign nullable time fields
-	if importedFrom.Valid {
-		meta.ImportedFrom = &importedFrom.Time
-	}
-	if importedTo.Valid {
-		meta.ImportedTo = &importedTo.Time
-	}
-
-	return meta, nil
+	return meta, err
 }
 
 // CountWithNullDates counts airports with null imported_from and imported_to
@@ -116,7 +143,7 @@ func (dao *AirportsMetaDao) CountWithNullDates() (int, error) {
 		return 0, err
 	}
 
-	sqlQuery := `SELECT COUNT(*) FROM airports_meta WHERE imported_from IS NULL AND imported_to IS NULL`
+	sqlQuery := `SELECT COUNT(*) FROM ` + dao.table + ` WHERE imported_from IS NULL AND imported_to IS NULL`
 
 	var count int
 	err = conn.QueryRow(sqlQuery).Scan(&count)
@@ -134,7 +161,7 @@ func (dao *AirportsMetaDao) CountWithNonNullDates() (int, error) {
 		return 0, err
 	}
 
-	sqlQuery := `SELECT COUNT(*) FROM airports_meta WHERE imported_from IS NOT NULL AND imported_to IS NOT NULL`
+	sqlQuery := `SELECT COUNT(*) FROM ` + dao.table + ` WHERE imported_from IS NOT NULL AND imported_to IS NOT NULL`
 
 	var count int
 	err = conn.QueryRow(sqlQuery).Scan(&count)
@@ -152,37 +179,203 @@ func (dao *AirportsMetaDao) GetFirstWithNullDates() (*tables.AirportMeta, error)
 		return nil, err
 	}
 
-	sqlQuery := `SELECT airport_code, imported_from, imported_to
-		FROM airports_meta
+	sqlQuery := `SELECT ` + airportMetaColumns + `
+		FROM ` + dao.table + `
 		WHERE imported_from IS NULL AND imported_to IS NULL
 		LIMIT 1`
 
-	row := conn.QueryRow(sqlQuery)
+	meta, err := scanAirportMeta(conn.QueryRow(sqlQuery))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return meta, err
+}
+
+// GetPending retrieves the airports never imported and the ones whose import was interrupted or failed,
+// the interrupted ones first to be resumed
+func (dao *AirportsMetaDao) GetPending() ([]*tables.AirportMeta, error) {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
 
+	sqlQuery := `SELECT ` + airportMetaColumns + `
+		FROM ` + dao.table + `
+		WHERE (imported_from IS NULL AND imported_to IS NULL)
+			OR import_status IN ('` + tables.ImportStatusInProgress + `', '` + tables.ImportStatusFailed + `')
+		ORDER BY import_status = '` + tables.ImportStatusInProgress + `' DESC, airport_code`
+
+	rows, err := conn.Query(sqlQuery)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	var metas []*tables.AirportMeta
+	for rows.Next() {
+		meta, err := scanAirportMeta(rows)
+		if err != nil {
+			return nil, err
+		}
+		metas = append(metas, meta)
+	}
+
+	return metas, rows.Err()
+}
+
+// StartImport marks the import of the airport as running
+func (dao *AirportsMetaDao) StartImport(airportCode string) error {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `INSERT INTO ` + dao.table + ` (airport_code, import_status)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE
+			import_status = VALUES(import_status),
+			last_error = NULL`
+
+	_, err = conn.Exec(sqlQuery, airportCode, tables.ImportStatusInProgress)
+
+	return err
+}
+
+// FinishImport marks the import as done and stores the first and the last imported day of the airport
+func (dao *AirportsMetaDao) FinishImport(airportCode string) error {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `UPDATE ` + dao.table + ` m
+		SET imported_from = (SELECT MIN(d.day) FROM ` + dao.daysTable + ` d WHERE d.airport_code = m.airport_code),
+			imported_to = (SELECT MAX(d.day) FROM ` + dao.daysTable + ` d WHERE d.airport_code = m.airport_code),
+			import_status = ?,
+			last_error = NULL
+		WHERE airport_code = ?`
+
+	_, err = conn.Exec(sqlQuery, tables.ImportStatusDone, airportCode)
+
+	return err
+}
+
+// FailImport marks the import as failed, the imported days are kept to resume it
+func (dao *AirportsMetaDao) FailImport(airportCode string, importErr error) error {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `UPDATE ` + dao.table + `
+		SET import_status = ?, last_error = ?
+		WHERE airport_code = ?`
+
+	_, err = conn.Exec(sqlQuery, tables.ImportStatusFailed, importErr.Error(), airportCode)
+
+	return err
+}
+
+// GetImportedDays returns the imported days (YYYY-MM-DD) of the airport schedules of the type in the range
+func (dao *AirportsMetaDao) GetImportedDays(airportCode string, scheduleType string, dateFrom, dateTo string) (map[string]bool, error) {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT day FROM ` + dao.daysTable + `
+		WHERE airport_code = ? AND type = ? AND day BETWEEN ? AND ?`
+
+	rows, err := conn.Query(sqlQuery, airportCode, scheduleType, dateFrom, dateTo)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	days := make(map[string]bool)
+	for rows.Next() {
+		var day time.Time
+		err = rows.Scan(&day)
+		if err != nil {
+			return nil, err
+		}
+		days[day.Format(time.DateOnly)] = true
+	}
+
+	return days, rows.Err()
+}
+
+// MarkDayImported stores the day as imported for the airport schedules of the type
+func (dao *AirportsMetaDao) MarkDayImported(airportCode string, scheduleType string, day string) error {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `INSERT INTO ` + dao.daysTable + ` (airport_code, type, day)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE imported_at = CURRENT_TIMESTAMP`
+
+	_, err = conn.Exec(sqlQuery, airportCode, scheduleType, day)
+
+	return err
+}
+
+// BackfillDays marks the days of the ranges imported before the days were tracked as imported departures
+// for the airports without tracked days. The ranges were widened over the days not imported, so only the days
+// having departures of the airport in flight_schedules are marked, the others are imported again.
+func (dao *AirportsMetaDao) BackfillDays() error {
+	conn, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `INSERT IGNORE INTO ` + dao.daysTable + ` (airport_code, type, day)
+		SELECT DISTINCT m.airport_code, 'departure', DATE(COALESCE(fs.dep_scheduled_time_local, fs.dep_scheduled_time))
+		FROM ` + dao.table + ` m
+		JOIN flight_schedules fs ON fs.dep_iata_code = m.airport_code AND fs.type = 'departure' AND ` + dao.schedulesCondition + `
+		WHERE m.imported_from IS NOT NULL AND m.imported_to IS NOT NULL
+			AND DATE(COALESCE(fs.dep_scheduled_time_local, fs.dep_scheduled_time)) BETWEEN DATE(m.imported_from) AND DATE(m.imported_to)
+			AND NOT EXISTS (SELECT 1 FROM ` + dao.daysTable + ` d WHERE d.airport_code = m.airport_code)`
+
+	_, err = conn.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+const airportMetaColumns = `airport_code, imported_from, imported_to, import_status, last_error`
+
+// scanAirportMeta scans the airportMetaColumns of the row
+func scanAirportMeta(row interface{ Scan(dest ...any) error }) (*tables.AirportMeta, error) {
 	meta := &tables.AirportMeta{}
 	var importedFrom, importedTo sql.NullTime
+	var importStatus, lastError sql.NullString
 
-	err = row.Scan(
+	err := row.Scan(
 		&meta.AirportCode,
 		&importedFrom,
 		&importedTo,
+		&importStatus,
+		&lastError,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-
 	if err != nil {
 		return nil, err
 	}
 
-	// Assign nullable time fields
+	// Assign nullable fields
 	if importedFrom.Valid {
 		meta.ImportedFrom = &importedFrom.Time
 	}
 	if importedTo.Valid {
 		meta.ImportedTo = &importedTo.Time
 	}
+	meta.ImportStatus = importStatus.String
+	meta.LastError = lastError.String
 
 	return meta, nil
 }
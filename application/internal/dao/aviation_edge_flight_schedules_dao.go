@@ -4,10 +4,12 @@ import (
 	"database/sql"
 	"darbelis.eu/persedimai/internal/aviation_edge"
 	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/delays"
 	"darbelis.eu/persedimai/internal/util"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type AviationEdgeFlightSchedulesDao struct {
@@ -63,6 +65,12 @@ func (*AviationEdgeFlightSchedulesDao) GetTableFields() []string {
 		"codeshared_flight_number",
 		"codeshared_flight_iata",
 		"codeshared_flight_icao",
+		"dep_scheduled_time_local",
+		"dep_estimated_time_local",
+		"dep_actual_time_local",
+		"arr_scheduled_time_local",
+		"arr_estimated_time_local",
+		"arr_actual_time_local",
 	}
 }
 
@@ -78,6 +86,14 @@ func (dao *AviationEdgeFlightSchedulesDao) UpsertFlightSchedules(schedules []*av
 		return err
 	}
 
+	return dao.upsertFlightSchedules(connection, schedules)
+}
+
+func (dao *AviationEdgeFlightSchedulesDao) upsertFlightSchedules(executor sqlExecutor, schedules []*aviation_edge.ScheduleResponse) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
 	// Build value lines for each schedule
 	lines := make([]string, len(schedules))
 	for i, schedule := range schedules {
@@ -94,7 +110,7 @@ func (dao *AviationEdgeFlightSchedulesDao) UpsertFlightSchedules(schedules []*av
 		valuesSubSql + ` ON DUPLICATE KEY UPDATE ` + updatesSubSql + `,
 		updated_at = CURRENT_TIMESTAMP`
 
-	_, err = connection.Exec(sqlQuery)
+	_, err := executor.Exec(sqlQuery)
 
 	if err != nil {
 		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
@@ -165,24 +181,215 @@ func (dao *AviationEdgeFlightSchedulesDao) ToArray(sr *aviation_edge.ScheduleRes
 		sr.GetFlightNumber(),
 		sr.GetFlightIataNumber(),
 		sr.GetFlightIcaoNumber(),
+
+		// Local times
+		sr.Departure.ScheduledTimeLocal,
+		sr.Departure.EstimatedTimeLocal,
+		sr.Departure.ActualTimeLocal,
+		sr.Arrival.ScheduledTimeLocal,
+		sr.Arrival.EstimatedTimeLocal,
+		sr.Arrival.ActualTimeLocal,
 	}
 }
 
 // GetAll retrieves all flight schedules from the database
 func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleResponse, error) {
+	return dao.querySchedules("1 = 1")
+}
+
+// FindByArrivalAirport retrieves the schedules of the given type ("departure" or "arrival") of the flights
+// arriving to the airport in the local days from dateFrom to dateTo (YYYY-MM-DD) inclusive
+func (dao *AviationEdgeFlightSchedulesDao) FindByArrivalAirport(scheduleType string, arrIataCode string, dateFrom, dateTo string) ([]*aviation_edge.ScheduleResponse, error) {
+	return dao.querySchedules(`type = ? AND arr_iata_code = ?
+		AND arr_scheduled_time_local >= ? AND arr_scheduled_time_local < DATE_ADD(?, INTERVAL 1 DAY)`,
+		scheduleType, arrIataCode, dateFrom, dateTo)
+}
+
+// FindByDepartureAirport retrieves the schedules of the given type of the flights departing from the airport
+// in the local days from dateFrom to dateTo (YYYY-MM-DD) inclusive
+func (dao *AviationEdgeFlightSchedulesDao) FindByDepartureAirport(scheduleType string, depIataCode string, dateFrom, dateTo string) ([]*aviation_edge.ScheduleResponse, error) {
+	return dao.querySchedules(`type = ? AND dep_iata_code = ?
+		AND dep_scheduled_time_local >= ? AND dep_scheduled_time_local < DATE_ADD(?, INTERVAL 1 DAY)`,
+		scheduleType, depIataCode, dateFrom, dateTo)
+}
+
+// FindDelayObservations retrieves the outcomes of the past operating flights departing in the UTC days
+// from dateFrom to dateTo (YYYY-MM-DD) from their departure records
+func (dao *AviationEdgeFlightSchedulesDao) FindDelayObservations(dateFrom, dateTo string) ([]*delays.Observation, error) {
 	connection, err := dao.database.GetConnection()
 	if err != nil {
 		return nil, err
 	}
 
-	fields := dao.GetTableFields()
-	fieldsSubSql := strings.Join(fields, ", ")
-	sqlQuery := fmt.Sprintf("SELECT %s FROM flight_schedules ORDER BY id", fieldsSubSql)
+	sqlQuery := `SELECT flight_iata_number, dep_iata_code, arr_iata_code, DATE(dep_scheduled_time), status, dep_delay, arr_delay
+		FROM flight_schedules
+		WHERE type = 'departure' AND (codeshared_flight_iata IS NULL OR codeshared_flight_iata = '')
+			AND dep_scheduled_time >= ? AND dep_scheduled_time < DATE_ADD(?, INTERVAL 1 DAY)
+			AND dep_scheduled_time < UTC_TIMESTAMP()`
+
+	rows, err := connection.Query(sqlQuery, dateFrom, dateTo)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	return scanObservations(rows)
+}
+
+// DeleteSchedules deletes the records by their unique key
+func (dao *AviationEdgeFlightSchedulesDao) DeleteSchedules(schedules []*aviation_edge.ScheduleResponse) error {
+	if len(schedules) == 0 {
+		return nil
+	}
 
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	return dao.deleteSchedules(connection, schedules)
+}
+
+func (dao *AviationEdgeFlightSchedulesDao) deleteSchedules(executor sqlExecutor, schedules []*aviation_edge.ScheduleResponse) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	conditions := util.ArrayMap(schedules, uniqueKeyValues)
+
+	sqlQuery := `DELETE FROM flight_schedules
+		WHERE (` + uniqueKeyColumns + `) IN (` + strings.Join(conditions, ",\n") + `)`
+
+	_, err := executor.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// uniqueKeyColumns are the columns of the unique_schedule key
+const uniqueKeyColumns = "flight_iata_number, dep_scheduled_time, dep_iata_code, arr_iata_code, type"
+
+// uniqueKeyValues builds the quoted values of the unique key of the schedule in the order of uniqueKeyColumns
+func uniqueKeyValues(schedule *aviation_edge.ScheduleResponse) string {
+	values := util.ArrayMap([]string{
+		schedule.Flight.IataNumber,
+		schedule.Departure.ScheduledTime,
+		schedule.Departure.IataCode,
+		schedule.Arrival.IataCode,
+		schedule.Type,
+	}, util.QuoteStringOrNull)
+
+	return "(" + strings.Join(values, ",") + ")"
+}
+
+// FindDepartureAirportsWithoutLocalTimes returns the departure airports of the records stored before the times were
+// normalised to UTC, which hold the local times in the UTC columns and have no local ones
+func (dao *AviationEdgeFlightSchedulesDao) FindDepartureAirportsWithoutLocalTimes() ([]string, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT DISTINCT dep_iata_code FROM flight_schedules WHERE dep_scheduled_time_local IS NULL ORDER BY dep_iata_code`
 	rows, err := connection.Query(sqlQuery)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		err = rows.Scan(&code)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// FindWithoutLocalTimes returns the records of the departure airport stored before the times were normalised to UTC
+func (dao *AviationEdgeFlightSchedulesDao) FindWithoutLocalTimes(depIataCode string) ([]*aviation_edge.ScheduleResponse, error) {
+	return dao.querySchedules("dep_iata_code = ? AND dep_scheduled_time_local IS NULL", depIataCode)
+}
+
+// ReplaceWithNormalized sets the times of the records stored before the times were normalised to UTC to their
+// normalised counterparts, originals[i] being replaced by normalized[i], in one transaction. The records of which
+// a normalised copy has been collected since are deleted instead.
+func (dao *AviationEdgeFlightSchedulesDao) ReplaceWithNormalized(originals, normalized []*aviation_edge.ScheduleResponse) error {
+	if len(originals) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	tx, err := connection.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, original := range originals {
+		schedule := normalized[i]
+		var assignments []string
+		for _, value := range [][2]string{
+			{"dep_scheduled_time", schedule.Departure.ScheduledTime},
+			{"dep_estimated_time", schedule.Departure.EstimatedTime},
+			{"dep_actual_time", schedule.Departure.ActualTime},
+			{"dep_estimated_runway", schedule.Departure.EstimatedRunway},
+			{"dep_actual_runway", schedule.Departure.ActualRunway},
+			{"arr_scheduled_time", schedule.Arrival.ScheduledTime},
+			{"arr_estimated_time", schedule.Arrival.EstimatedTime},
+			{"arr_actual_time", schedule.Arrival.ActualTime},
+			{"arr_estimated_runway", schedule.Arrival.EstimatedRunway},
+			{"arr_actual_runway", schedule.Arrival.ActualRunway},
+			{"dep_scheduled_time_local", schedule.Departure.ScheduledTimeLocal},
+			{"dep_estimated_time_local", schedule.Departure.EstimatedTimeLocal},
+			{"dep_actual_time_local", schedule.Departure.ActualTimeLocal},
+			{"arr_scheduled_time_local", schedule.Arrival.ScheduledTimeLocal},
+			{"arr_estimated_time_local", schedule.Arrival.EstimatedTimeLocal},
+			{"arr_actual_time_local", schedule.Arrival.ActualTimeLocal},
+		} {
+			assignments = append(assignments, value[0]+" = "+util.QuoteStringOrNull(value[1]))
+		}
+
+		// the update is ignored when the normalised key is taken by a record collected since
+		condition := `(` + uniqueKeyColumns + `) = ` + uniqueKeyValues(original) + ` AND dep_scheduled_time_local IS NULL`
+		for _, sqlQuery := range []string{
+			`UPDATE IGNORE flight_schedules SET ` + strings.Join(assignments, ", ") + ` WHERE ` + condition,
+			`DELETE FROM flight_schedules WHERE ` + condition,
+		} {
+			_, err = tx.Exec(sqlQuery)
+			if err != nil {
+				return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (dao *AviationEdgeFlightSchedulesDao) querySchedules(where string, args ...any) ([]*aviation_edge.ScheduleResponse, error) {
+	connection, err := dao.database.GetConnection()
 	if err != nil {
 		return nil, err
 	}
+
+	fields := dao.GetTableFields()
+	fieldsSubSql := strings.Join(fields, ", ")
+	sqlQuery := fmt.Sprintf("SELECT %s FROM flight_schedules WHERE %s ORDER BY id", fieldsSubSql, where)
+
+	rows, err := connection.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
 	defer rows.Close()
 
 	var schedules []*aviation_edge.ScheduleResponse
@@ -201,6 +408,8 @@ func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleRe
 			aircraftIataCode, aircraftModelCode, aircraftModelText                   sql.NullString
 			codesharedAirlineName, codesharedAirlineIata, codesharedAirlineIcao      sql.NullString
 			codesharedFlightNumber, codesharedFlightIata, codesharedFlightIcao       sql.NullString
+			depScheduledTimeLocal, depEstimatedTimeLocal, depActualTimeLocal         sql.NullString
+			arrScheduledTimeLocal, arrEstimatedTimeLocal, arrActualTimeLocal         sql.NullString
 		)
 
 		err := rows.Scan(
@@ -245,6 +454,12 @@ func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleRe
 			&codesharedFlightNumber,
 			&codesharedFlightIata,
 			&codesharedFlightIcao,
+			&depScheduledTimeLocal,
+			&depEstimatedTimeLocal,
+			&depActualTimeLocal,
+			&arrScheduledTimeLocal,
+			&arrEstimatedTimeLocal,
+			&arrActualTimeLocal,
 		)
 		if err != nil {
 			return nil, err
@@ -259,6 +474,9 @@ func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleRe
 		schedule.Departure.ActualTime = depActualTime.String
 		schedule.Departure.EstimatedRunway = depEstimatedRunway.String
 		schedule.Departure.ActualRunway = depActualRunway.String
+		schedule.Departure.ScheduledTimeLocal = depScheduledTimeLocal.String
+		schedule.Departure.EstimatedTimeLocal = depEstimatedTimeLocal.String
+		schedule.Departure.ActualTimeLocal = depActualTimeLocal.String
 
 		schedule.Arrival.IcaoCode = arrIcaoCode.String
 		schedule.Arrival.Terminal = arrTerminal.String
@@ -269,6 +487,9 @@ func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleRe
 		schedule.Arrival.ActualTime = arrActualTime.String
 		schedule.Arrival.EstimatedRunway = arrEstimatedRunway.String
 		schedule.Arrival.ActualRunway = arrActualRunway.String
+		schedule.Arrival.ScheduledTimeLocal = arrScheduledTimeLocal.String
+		schedule.Arrival.EstimatedTimeLocal = arrEstimatedTimeLocal.String
+		schedule.Arrival.ActualTimeLocal = arrActualTimeLocal.String
 
 		schedule.Airline.IcaoCode = airlineIcaoCode.String
 		schedule.Flight.IcaoNumber = flightIcaoNumber.String
@@ -296,6 +517,8 @@ func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleRe
 			}
 		}
 
+		formatScannedTimes(schedule)
+
 		schedules = append(schedules, schedule)
 	}
 
@@ -305,3 +528,31 @@ func (dao *AviationEdgeFlightSchedulesDao) GetAll() ([]*aviation_edge.ScheduleRe
 
 	return schedules, nil
 }
+
+// formatScannedTimes brings the DATETIME columns scanned as RFC 3339 strings back to the format they are stored in,
+// so that the read schedules can be compared and upserted again
+func formatScannedTimes(schedule *aviation_edge.ScheduleResponse) {
+	for _, value := range []*string{
+		&schedule.Departure.ScheduledTime,
+		&schedule.Departure.EstimatedTime,
+		&schedule.Departure.ActualTime,
+		&schedule.Departure.EstimatedRunway,
+		&schedule.Departure.ActualRunway,
+		&schedule.Departure.ScheduledTimeLocal,
+		&schedule.Departure.EstimatedTimeLocal,
+		&schedule.Departure.ActualTimeLocal,
+		&schedule.Arrival.ScheduledTime,
+		&schedule.Arrival.EstimatedTime,
+		&schedule.Arrival.ActualTime,
+		&schedule.Arrival.EstimatedRunway,
+		&schedule.Arrival.ActualRunway,
+		&schedule.Arrival.ScheduledTimeLocal,
+		&schedule.Arrival.EstimatedTimeLocal,
+		&schedule.Arrival.ActualTimeLocal,
+	} {
+		parsed, err := time.Parse(time.RFC3339Nano, *value)
+		if err == nil {
+			*value = parsed.Format(time.DateTime)
+		}
+	}
+}
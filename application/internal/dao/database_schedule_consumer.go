@@ -2,28 +2,46 @@ package dao
 
 import (
 	"darbelis.eu/persedimai/internal/aviation_edge"
+	"database/sql"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 )
 
-// DatabaseScheduleConsumer saves schedules to the database using AviationEdgeFlightSchedulesDao
+// DatabaseScheduleConsumer saves schedules to the database using AviationEdgeFlightSchedulesDao.
+// Codeshare records are not saved as separate flights, their flight numbers are linked to the operating flight.
+// A batch holds all the schedules of one airport day as fetched by one request: the departures of the departure
+// airport and local departure day or the arrivals of the arrival airport and local arrival day, so the changes from
+// the stored schedules of the day imported before are logged and the superseded records are deleted.
 type DatabaseScheduleConsumer struct {
-	dao        *AviationEdgeFlightSchedulesDao
-	TotalCount int
-	mu         sync.Mutex
+	dao           *AviationEdgeFlightSchedulesDao
+	codesharesDao *FlightCodesharesDao
+	changesDao    *FlightScheduleChangesDao
+	TotalCount    int
+	mu            sync.Mutex
+}
+
+// sqlExecutor runs the statements of the DAOs on the connection or in a transaction
+type sqlExecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
 }
 
 // TODO call from di package.
 
 // NewDatabaseScheduleConsumer creates a new DatabaseScheduleConsumer with the given DAO
-func NewDatabaseScheduleConsumer(dao *AviationEdgeFlightSchedulesDao) *DatabaseScheduleConsumer {
+func NewDatabaseScheduleConsumer(dao *AviationEdgeFlightSchedulesDao, codesharesDao *FlightCodesharesDao, changesDao *FlightScheduleChangesDao) *DatabaseScheduleConsumer {
 	return &DatabaseScheduleConsumer{
-		dao:        dao,
-		TotalCount: 0,
+		dao:           dao,
+		codesharesDao: codesharesDao,
+		changesDao:    changesDao,
+		TotalCount:    0,
 	}
 }
 
-// Consume saves the schedules to the database using the DAO's UpsertFlightSchedules method
+// Consume saves the schedules of one airport day, the batches of several days are refused as the stored schedules
+// missing in the batch are logged as removed. The schedules, their codeshares, the deletion of the superseded records
+// and the log of the changes are saved in one transaction.
 func (d *DatabaseScheduleConsumer) Consume(schedules []aviation_edge.ScheduleResponse) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -32,20 +50,103 @@ func (d *DatabaseScheduleConsumer) Consume(schedules []aviation_edge.ScheduleRes
 		return nil
 	}
 
+	schedules, links := aviation_edge.DeduplicateCodeshares(schedules)
+
+	changes, err := d.detectChanges(schedules)
+	if err != nil {
+		return err
+	}
+
 	// Convert to pointers for the DAO
 	schedulePtrs := make([]*aviation_edge.ScheduleResponse, len(schedules))
 	for i := range schedules {
 		schedulePtrs[i] = &schedules[i]
 	}
 
-	// Upsert schedules to database
-	err := d.dao.UpsertFlightSchedules(schedulePtrs)
+	connection, err := d.dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	tx, err := connection.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = d.dao.upsertFlightSchedules(tx, schedulePtrs)
+	if err != nil {
+		return err
+	}
+
+	err = d.codesharesDao.upsertCodeshares(tx, links)
+	if err != nil {
+		return err
+	}
+
+	err = d.dao.deleteSchedules(tx, changes.Superseded)
+	if err != nil {
+		return err
+	}
+
+	err = d.changesDao.insertChanges(tx, changes.Changes)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return err
 	}
 
 	d.TotalCount += len(schedules)
-	log.Printf("Inserted/updated %d schedules to database (total: %d)", len(schedules), d.TotalCount)
+	log.Printf("Inserted/updated %d schedules and %d codeshares to database, %d changes (total: %d)", len(schedules), len(links), len(changes.Changes), d.TotalCount)
 
 	return nil
 }
+
+// detectChanges compares the schedules with the stored ones of the airport day of the batch: the departure airport
+// and local departure day for departures, the arrival airport and local arrival day for arrivals.
+// The days imported for the first time have no changes.
+func (d *DatabaseScheduleConsumer) detectChanges(schedules []aviation_edge.ScheduleResponse) (*aviation_edge.ScheduleChanges, error) {
+	result := &aviation_edge.ScheduleChanges{}
+
+	key := airportDayKey(schedules[0])
+	for _, schedule := range schedules[1:] {
+		if other := airportDayKey(schedule); other != key {
+			return nil, fmt.Errorf("a batch holds the schedules of one airport day, got %s and %s", key, other)
+		}
+	}
+
+	parts := strings.Split(key, "|")
+	scheduleType, airport, day := parts[0], parts[1], parts[2]
+	if day == "" {
+		return result, nil
+	}
+
+	var stored []*aviation_edge.ScheduleResponse
+	var err error
+	if scheduleType == "arrival" {
+		stored, err = d.dao.FindByArrivalAirport(scheduleType, airport, day, day)
+	} else {
+		stored, err = d.dao.FindByDepartureAirport(scheduleType, airport, day, day)
+	}
+	if err != nil || len(stored) == 0 {
+		return result, err
+	}
+
+	return aviation_edge.DetectScheduleChanges(stored, schedules), nil
+}
+
+// airportDayKey is the type, airport and local day of the airport day of the schedule
+func airportDayKey(schedule aviation_edge.ScheduleResponse) string {
+	if schedule.Type == "arrival" {
+		return schedule.Type + "|" + schedule.Arrival.IataCode + "|" + localDate(schedule.Arrival.ScheduledTimeLocal)
+	}
+
+	return schedule.Type + "|" + schedule.Departure.IataCode + "|" + localDate(schedule.Departure.ScheduledTimeLocal)
+}
+
+func localDate(dateTime string) string {
+	return dateTime[:min(10, len(dateTime))]
+}
@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// delayStatsBatchSize is the amount of stats inserted by one statement
+const delayStatsBatchSize = 1000
+
+// DelayStatsDao keeps the delay distributions per flight, route and departure airport
+type DelayStatsDao struct {
+	database *database.Database
+}
+
+func NewDelayStatsDao(database *database.Database) *DelayStatsDao {
+	return &DelayStatsDao{database: database}
+}
+
+// ReplaceAll replaces the stats of the previous computation in one transaction
+func (dao *DelayStatsDao) ReplaceAll(stats []*tables.DelayStat) error {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	tx, err := connection.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec("DELETE FROM delay_stats")
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(stats); start += delayStatsBatchSize {
+		batch := stats[start:min(start+delayStatsBatchSize, len(stats))]
+		lines := make([]string, len(batch))
+		for i, s := range batch {
+			values := util.ArrayMap([]string{s.Scope, s.FlightIataNumber, s.DepIataCode, s.ArrIataCode}, util.QuoteString)
+			lines[i] = fmt.Sprintf("(%s, %d, %d, %f, %d, %d, %d, %d, %f, '%s', '%s')", strings.Join(values, ","),
+				s.Flights, s.Cancelled, s.MeanDelay, s.P50Delay, s.P75Delay, s.P90Delay, s.P95Delay, s.CancellationRate,
+				s.PeriodFrom, s.PeriodTo)
+		}
+
+		sqlQuery := `INSERT INTO delay_stats
+			(scope, flight_iata_number, dep_iata_code, arr_iata_code, flights, cancelled, mean_delay,
+				p50_delay, p75_delay, p90_delay, p95_delay, cancellation_rate, period_from, period_to)
+			VALUES ` + strings.Join(lines, ",\n")
+
+		_, err = tx.Exec(sqlQuery)
+		if err != nil {
+			return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindFlight retrieves the stats of the flight on the route, nil when it was not observed enough
+func (dao *DelayStatsDao) FindFlight(flightIataNumber, depIataCode, arrIataCode string) (*tables.DelayStat, error) {
+	return dao.findOne(`scope = ? AND flight_iata_number = ? AND dep_iata_code = ? AND arr_iata_code = ?`,
+		tables.DelayScopeFlight, flightIataNumber, depIataCode, arrIataCode)
+}
+
+// FindRoute retrieves the stats of all the flights of the route, nil when it was not observed enough
+func (dao *DelayStatsDao) FindRoute(depIataCode, arrIataCode string) (*tables.DelayStat, error) {
+	return dao.findOne(`scope = ? AND dep_iata_code = ? AND arr_iata_code = ?`, tables.DelayScopeRoute, depIataCode, arrIataCode)
+}
+
+// FindAirport retrieves the stats of the departures of the airport, nil when it was not observed enough
+func (dao *DelayStatsDao) FindAirport(depIataCode string) (*tables.DelayStat, error) {
+	return dao.findOne(`scope = ? AND dep_iata_code = ?`, tables.DelayScopeAirport, depIataCode)
+}
+
+// FindByScope retrieves the stats of the scope, of the departure airport when it is given, the most delayed first
+func (dao *DelayStatsDao) FindByScope(scope string, depIataCode string) ([]*tables.DelayStat, error) {
+	if depIataCode == "" {
+		return dao.query(`scope = ?`, scope)
+	}
+
+	return dao.query(`scope = ? AND dep_iata_code = ?`, scope, depIataCode)
+}
+
+func (dao *DelayStatsDao) findOne(where string, args ...any) (*tables.DelayStat, error) {
+	stats, err := dao.query(where, args...)
+	if err != nil || len(stats) == 0 {
+		return nil, err
+	}
+
+	return stats[0], nil
+}
+
+func (dao *DelayStatsDao) query(where string, args ...any) ([]*tables.DelayStat, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT scope, flight_iata_number, dep_iata_code, arr_iata_code, flights, cancelled, mean_delay,
+			p50_delay, p75_delay, p90_delay, p95_delay, cancellation_rate, period_from, period_to
+		FROM delay_stats
+		WHERE ` + where + `
+		ORDER BY mean_delay DESC, dep_iata_code, arr_iata_code, flight_iata_number`
+
+	rows, err := connection.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	var stats []*tables.DelayStat
+	for rows.Next() {
+		s := &tables.DelayStat{}
+		var periodFrom, periodTo time.Time
+
+		err = rows.Scan(&s.Scope, &s.FlightIataNumber, &s.DepIataCode, &s.ArrIataCode, &s.Flights, &s.Cancelled, &s.MeanDelay,
+			&s.P50Delay, &s.P75Delay, &s.P90Delay, &s.P95Delay, &s.CancellationRate, &periodFrom, &periodTo)
+		if err != nil {
+			return nil, err
+		}
+
+		s.PeriodFrom = periodFrom.Format(time.DateOnly)
+		s.PeriodTo = periodTo.Format(time.DateOnly)
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
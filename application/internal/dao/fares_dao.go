@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FaresDao keeps the fares of the travels and of the routes between points
+type FaresDao struct {
+	database *database.Database
+}
+
+func NewFaresDao(database *database.Database) *FaresDao {
+	return &FaresDao{database: database}
+}
+
+// Upsert inserts the fares or updates the ones with the same id
+func (dao *FaresDao) Upsert(fares []*tables.Fare) error {
+	if len(fares) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(fares))
+	for i, fare := range fares {
+		values := util.ArrayMap([]string{
+			fare.ID,
+			fare.TravelID,
+			fare.From,
+			fare.To,
+			fare.ValidFrom,
+			fare.ValidTo,
+			fare.DaysOfWeek,
+		}, util.QuoteStringOrNull)
+		values = append(values, fmt.Sprintf("%.2f", fare.Amount), util.QuoteString(strings.ToUpper(fare.Currency)))
+		lines[i] = "(" + strings.Join(values, ",") + ")"
+	}
+
+	sqlQuery := `INSERT INTO fares (id, travel_id, from_point, to_point, valid_from, valid_to, days_of_week, amount, currency)
+		VALUES ` + strings.Join(lines, ",\n") + `
+		ON DUPLICATE KEY UPDATE
+			travel_id = VALUES(travel_id),
+			from_point = VALUES(from_point),
+			to_point = VALUES(to_point),
+			valid_from = VALUES(valid_from),
+			valid_to = VALUES(valid_to),
+			days_of_week = VALUES(days_of_week),
+			amount = VALUES(amount),
+			currency = VALUES(currency)`
+
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindForTransfers loads in one query the fares in the currency of the transfers
+// and of the routes between their points, the matching of the route fares is left to fares.Index
+func (dao *FaresDao) FindForTransfers(transfers []*tables.Transfer, currency string) ([]*tables.Fare, error) {
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	routes := make(map[string]bool)
+	for _, transfer := range transfers {
+		ids[util.QuoteString(transfer.ID)] = true
+		routes["("+util.QuoteString(transfer.From)+","+util.QuoteString(transfer.To)+")"] = true
+	}
+
+	sqlQuery := `SELECT id, travel_id, from_point, to_point, valid_from, valid_to, days_of_week, amount, currency
+		FROM fares
+		WHERE currency = ` + util.QuoteString(strings.ToUpper(currency)) + `
+			AND (travel_id IN (` + strings.Join(slices.Collect(maps.Keys(ids)), ",") + `)
+				OR travel_id IS NULL AND (from_point, to_point) IN (` + strings.Join(slices.Collect(maps.Keys(routes)), ",") + `))
+		ORDER BY id`
+
+	rows, err := connection.Query(sqlQuery)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	var fares []*tables.Fare
+	for rows.Next() {
+		fare := &tables.Fare{}
+		var travelId, from, to, daysOfWeek sql.NullString
+		var validFrom, validTo sql.NullTime
+
+		err = rows.Scan(&fare.ID, &travelId, &from, &to, &validFrom, &validTo, &daysOfWeek, &fare.Amount, &fare.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		fare.TravelID = travelId.String
+		fare.From = from.String
+		fare.To = to.String
+		fare.DaysOfWeek = daysOfWeek.String
+		if validFrom.Valid {
+			fare.ValidFrom = validFrom.Time.Format(time.DateOnly)
+		}
+		if validTo.Valid {
+			fare.ValidTo = validTo.Time.Format(time.DateOnly)
+		}
+
+		fares = append(fares, fare)
+	}
+
+	return fares, rows.Err()
+}
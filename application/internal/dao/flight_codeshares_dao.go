@@ -0,0 +1,123 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/util"
+	"errors"
+	"strings"
+	"time"
+)
+
+type FlightCodesharesDao struct {
+	database *database.Database
+}
+
+func NewFlightCodesharesDao(database *database.Database) *FlightCodesharesDao {
+	return &FlightCodesharesDao{database: database}
+}
+
+// UpsertCodeshares stores links of marketing flight numbers to operating flights
+func (dao *FlightCodesharesDao) UpsertCodeshares(links []*aviation_edge.CodeshareLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	return dao.upsertCodeshares(connection, links)
+}
+
+func (dao *FlightCodesharesDao) upsertCodeshares(executor sqlExecutor, links []*aviation_edge.CodeshareLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(links))
+	for i, link := range links {
+		values := util.ArrayMap([]string{
+			link.OperatingFlightIata,
+			link.DepartureIata,
+			link.DepartureScheduledTime,
+			link.MarketingFlightIata,
+			link.MarketingAirlineIata,
+		}, util.QuoteStringOrNull)
+		lines[i] = "(" + strings.Join(values, ",") + ")"
+	}
+
+	sqlQuery := `INSERT INTO flight_codeshares
+		(operating_flight_iata, dep_iata_code, dep_scheduled_time, marketing_flight_iata, marketing_airline_iata)
+		VALUES ` + strings.Join(lines, ",\n") + `
+		ON DUPLICATE KEY UPDATE marketing_airline_iata = VALUES(marketing_airline_iata)`
+
+	_, err := executor.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindMarketingFlights returns the marketing flight numbers of an operating flight
+func (dao *FlightCodesharesDao) FindMarketingFlights(operatingFlightIata, depIataCode string, depScheduledTime time.Time) ([]string, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := connection.Query(`SELECT marketing_flight_iata FROM flight_codeshares
+		WHERE operating_flight_iata = ? AND dep_iata_code = ? AND dep_scheduled_time = ?
+		ORDER BY marketing_flight_iata`, strings.ToUpper(operatingFlightIata), depIataCode, depScheduledTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flights []string
+	for rows.Next() {
+		var flight string
+		err := rows.Scan(&flight)
+		if err != nil {
+			return nil, err
+		}
+		flights = append(flights, flight)
+	}
+
+	return flights, rows.Err()
+}
+
+// DeduplicateStoredSchedules moves codeshare records already stored in flight_schedules into flight_codeshares
+// and deletes the ones which operating flight record is stored too.
+// Returns the amount of deleted records.
+func (dao *FlightCodesharesDao) DeduplicateStoredSchedules() (int64, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = connection.Exec(`INSERT IGNORE INTO flight_codeshares
+		(operating_flight_iata, dep_iata_code, dep_scheduled_time, marketing_flight_iata, marketing_airline_iata)
+		SELECT UPPER(codeshared_flight_iata), dep_iata_code, dep_scheduled_time, UPPER(flight_iata_number), UPPER(airline_iata_code)
+		FROM flight_schedules
+		WHERE codeshared_flight_iata IS NOT NULL AND codeshared_flight_iata <> ''`)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := connection.Exec(`DELETE fs FROM flight_schedules fs
+		JOIN flight_schedules op
+			ON UPPER(op.flight_iata_number) = UPPER(fs.codeshared_flight_iata)
+			AND op.dep_iata_code = fs.dep_iata_code
+			AND op.dep_scheduled_time = fs.dep_scheduled_time
+			AND op.type = fs.type
+			AND (op.codeshared_flight_iata IS NULL OR op.codeshared_flight_iata = '')
+		WHERE fs.codeshared_flight_iata IS NOT NULL AND fs.codeshared_flight_iata <> ''`)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
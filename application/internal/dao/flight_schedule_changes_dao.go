@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// FlightScheduleChangesDao keeps the log of the flight schedules changes found by the repeated imports
+type FlightScheduleChangesDao struct {
+	database *database.Database
+}
+
+func NewFlightScheduleChangesDao(database *database.Database) *FlightScheduleChangesDao {
+	return &FlightScheduleChangesDao{database: database}
+}
+
+// InsertChanges logs the changes with the current import time
+func (dao *FlightScheduleChangesDao) InsertChanges(changes []*tables.ScheduleChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	return dao.insertChanges(connection, changes)
+}
+
+func (dao *FlightScheduleChangesDao) insertChanges(executor sqlExecutor, changes []*tables.ScheduleChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		values := util.ArrayMap([]string{
+			change.ChangeType,
+			change.Type,
+			change.FlightIataNumber,
+			change.DepIataCode,
+			change.ArrIataCode,
+			change.DepDate,
+			change.Field,
+			change.BeforeValue,
+			change.AfterValue,
+		}, util.QuoteStringOrNull)
+		lines[i] = "(" + strings.Join(values, ",") + ")"
+	}
+
+	sqlQuery := `INSERT INTO flight_schedule_changes
+		(change_type, type, flight_iata_number, dep_iata_code, arr_iata_code, dep_date, field, before_value, after_value)
+		VALUES ` + strings.Join(lines, ",\n")
+
+	_, err := executor.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindByAirport retrieves the changes of the flights departing from or arriving to the airport
+// imported since the given date (YYYY-MM-DD)
+func (dao *FlightScheduleChangesDao) FindByAirport(airport string, since string) ([]*tables.ScheduleChange, error) {
+	return dao.queryChanges(`(dep_iata_code = ? OR arr_iata_code = ?) AND imported_at >= ?`, airport, airport, since)
+}
+
+// FindByRoute retrieves the changes of the flights from depIataCode to arrIataCode imported since the given date
+func (dao *FlightScheduleChangesDao) FindByRoute(depIataCode, arrIataCode string, since string) ([]*tables.ScheduleChange, error) {
+	return dao.queryChanges(`dep_iata_code = ? AND arr_iata_code = ? AND imported_at >= ?`, depIataCode, arrIataCode, since)
+}
+
+func (dao *FlightScheduleChangesDao) queryChanges(where string, args ...any) ([]*tables.ScheduleChange, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT change_type, type, flight_iata_number, dep_iata_code, arr_iata_code, dep_date,
+			field, before_value, after_value, imported_at
+		FROM flight_schedule_changes
+		WHERE ` + where + `
+		ORDER BY imported_at, id`
+
+	rows, err := connection.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	var changes []*tables.ScheduleChange
+	for rows.Next() {
+		change := &tables.ScheduleChange{}
+		var depDate, importedAt time.Time
+		var field, beforeValue, afterValue sql.NullString
+
+		err = rows.Scan(
+			&change.ChangeType,
+			&change.Type,
+			&change.FlightIataNumber,
+			&change.DepIataCode,
+			&change.ArrIataCode,
+			&depDate,
+			&field,
+			&beforeValue,
+			&afterValue,
+			&importedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		change.DepDate = depDate.Format(time.DateOnly)
+		change.ImportedAt = importedAt.Format(time.DateTime)
+		change.Field = field.String
+		change.BeforeValue = beforeValue.String
+		change.AfterValue = afterValue.String
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
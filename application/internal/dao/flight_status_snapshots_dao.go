@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/delays"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FlightStatusSnapshotsDao keeps the flight statuses polled from the flight tracker
+type FlightStatusSnapshotsDao struct {
+	database *database.Database
+}
+
+func NewFlightStatusSnapshotsDao(database *database.Database) *FlightStatusSnapshotsDao {
+	return &FlightStatusSnapshotsDao{database: database}
+}
+
+// InsertSnapshots appends the snapshots of a poll
+func (dao *FlightStatusSnapshotsDao) InsertSnapshots(snapshots []*tables.FlightStatusSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		values := util.ArrayMap([]string{
+			s.FlightIataNumber,
+			s.AirlineIataCode,
+			s.DepIataCode,
+			s.ArrIataCode,
+			s.Status,
+			s.DepDelay,
+			s.ArrDelay,
+		}, util.QuoteStringOrNull)
+		lines[i] = fmt.Sprintf("(%s, %s, %f, %f, %f, %t, %s, %s)", strings.Join(values, ","), util.QuoteStringOrNull(s.DepScheduledTime),
+			s.Latitude, s.Longitude, s.Altitude, s.IsGround, util.QuoteStringOrNull(s.UpdatedAt), util.QuoteStringOrNull(s.PolledAt))
+	}
+
+	sqlQuery := `INSERT INTO flight_status_snapshots
+		(flight_iata_number, airline_iata_code, dep_iata_code, arr_iata_code, status, dep_delay, arr_delay, dep_scheduled_time,
+			latitude, longitude, altitude, is_ground, updated_at, polled_at)
+		VALUES ` + strings.Join(lines, ",\n")
+
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindObservations takes the outcomes of the flights departing on the days from dateFrom to dateTo (YYYY-MM-DD),
+// see delays.ObservationsOfSnapshots. The snapshots of a day before and after the period are read too,
+// as the flights crossing midnight are polled on two days.
+func (dao *FlightStatusSnapshotsDao) FindObservations(dateFrom, dateTo string) ([]*delays.Observation, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT flight_iata_number, dep_iata_code, arr_iata_code, status, dep_delay, arr_delay, dep_scheduled_time, polled_at
+		FROM flight_status_snapshots
+		WHERE polled_at >= ? - INTERVAL 1 DAY AND polled_at < ? + INTERVAL 2 DAY
+		ORDER BY flight_iata_number, dep_iata_code, arr_iata_code, polled_at, id`
+
+	rows, err := connection.Query(sqlQuery, dateFrom, dateTo)
+	if err != nil {
+		return nil, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	var snapshots []*tables.FlightStatusSnapshot
+	for rows.Next() {
+		snapshot := &tables.FlightStatusSnapshot{}
+		var depDelay, arrDelay sql.NullString
+		var depScheduledTime sql.NullTime
+		var polledAt time.Time
+
+		err = rows.Scan(&snapshot.FlightIataNumber, &snapshot.DepIataCode, &snapshot.ArrIataCode, &snapshot.Status,
+			&depDelay, &arrDelay, &depScheduledTime, &polledAt)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.DepDelay, snapshot.ArrDelay = depDelay.String, arrDelay.String
+		if depScheduledTime.Valid {
+			snapshot.DepScheduledTime = depScheduledTime.Time.Format(time.DateTime)
+		}
+		snapshot.PolledAt = polledAt.Format(time.DateTime)
+		snapshots = append(snapshots, snapshot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var observations []*delays.Observation
+	for _, observation := range delays.ObservationsOfSnapshots(snapshots) {
+		if observation.Date >= dateFrom && observation.Date <= dateTo {
+			observations = append(observations, observation)
+		}
+	}
+
+	return observations, nil
+}
+
+// scanObservations reads the rows of flight, dep, arr, date, status, dep delay and arr delay columns
+func scanObservations(rows *sql.Rows) ([]*delays.Observation, error) {
+	var observations []*delays.Observation
+	for rows.Next() {
+		var flight, dep, arr, status string
+		var date time.Time
+		var depDelay, arrDelay sql.NullString
+
+		err := rows.Scan(&flight, &dep, &arr, &date, &status, &depDelay, &arrDelay)
+		if err != nil {
+			return nil, err
+		}
+
+		observation, ok := delays.NewObservation(flight, dep, arr, date.Format(time.DateOnly), status, depDelay.String, arrDelay.String)
+		if ok {
+			observations = append(observations, observation)
+		}
+	}
+
+	return observations, rows.Err()
+}
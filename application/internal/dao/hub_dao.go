@@ -0,0 +1,160 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/tables"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type HubDao struct {
+	database *database.Database
+}
+
+func NewHubDao(database *database.Database) *HubDao {
+	return &HubDao{database: database}
+}
+
+// ReplaceHubs replaces all the hubs and their point assignments
+func (dao *HubDao) ReplaceHubs(hubIDs []string, pointHubs []*tables.PointHub) error {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	for _, sqlQuery := range []string{"delete from hubs", "delete from point_hubs", "delete from hub_connections"} {
+		_, err = connection.Exec(sqlQuery)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(hubIDs) == 0 {
+		return nil
+	}
+
+	hubLines := make([]string, len(hubIDs))
+	for i, hubID := range hubIDs {
+		hubLines[i] = fmt.Sprintf("('%s')", database.MysqlRealEscapeString(hubID))
+	}
+
+	sqlQuery := "insert into hubs (point_id) values " + strings.Join(hubLines, ",\n")
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	if len(pointHubs) == 0 {
+		return nil
+	}
+
+	pointHubLines := make([]string, len(pointHubs))
+	for i, pointHub := range pointHubs {
+		pointHubLines[i] = fmt.Sprintf("('%s', '%s', %f)",
+			database.MysqlRealEscapeString(pointHub.PointID),
+			database.MysqlRealEscapeString(pointHub.HubID),
+			pointHub.Distance)
+	}
+
+	sqlQuery = "insert into point_hubs (point_id, hub_id, distance) values " + strings.Join(pointHubLines, ",\n")
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// RebuildHubConnections stores the pairs of hubs having direct travels between them and logs the build in hub_builds
+func (dao *HubDao) RebuildHubConnections() (int64, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = connection.Exec("delete from hub_connections")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := connection.Exec(`insert into hub_connections (from_hub, to_hub)
+		select distinct t.from_point, t.to_point from travels t
+		join hubs h1 on h1.point_id = t.from_point
+		join hubs h2 on h2.point_id = t.to_point`)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = connection.Exec("insert into hub_builds () values ()")
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetLastBuildID returns the id of the last build of the hub connections, 0 when they were never built
+func (dao *HubDao) GetLastBuildID() (int64, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	var buildID int64
+	err = connection.QueryRow("SELECT COALESCE(MAX(id), 0) FROM hub_builds").Scan(&buildID)
+
+	return buildID, err
+}
+
+// SelectHubConnections loads all the connections between hubs
+func (dao *HubDao) SelectHubConnections() ([]*tables.HubConnection, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := connection.Query("SELECT from_hub, to_hub FROM hub_connections")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var connections []*tables.HubConnection
+	for rows.Next() {
+		hubConnection := &tables.HubConnection{}
+		err := rows.Scan(&hubConnection.FromHub, &hubConnection.ToHub)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, hubConnection)
+	}
+
+	return connections, rows.Err()
+}
+
+// FindPointHubIDs returns hubs of the point, the nearest first
+func (dao *HubDao) FindPointHubIDs(pointID string) ([]string, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := connection.Query("SELECT hub_id FROM point_hubs WHERE point_id = ? ORDER BY distance", pointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hubIDs []string
+	for rows.Next() {
+		var hubID string
+		err := rows.Scan(&hubID)
+		if err != nil {
+			return nil, err
+		}
+		hubIDs = append(hubIDs, hubID)
+	}
+
+	return hubIDs, rows.Err()
+}
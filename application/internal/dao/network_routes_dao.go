@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type NetworkRoutesDao struct {
+	database *database.Database
+}
+
+func NewNetworkRoutesDao(database *database.Database) *NetworkRoutesDao {
+	return &NetworkRoutesDao{database: database}
+}
+
+// UpsertRoutes inserts or updates the airport pairs of the airlines
+func (dao *NetworkRoutesDao) UpsertRoutes(routes []*tables.NetworkRoute) error {
+	if len(routes) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(routes))
+	for i, route := range routes {
+		values := util.ArrayMap([]string{
+			route.AirlineCode,
+			route.AirlineIata,
+			route.AirlineIcao,
+			route.DepartureIata,
+			route.ArrivalIata,
+			route.Equipment,
+			route.Source,
+		}, util.QuoteStringOrNull)
+		values = append(values, strconv.FormatBool(route.Codeshare), strconv.Itoa(route.Stops))
+		lines[i] = "(" + strings.Join(values, ",") + ")"
+	}
+
+	sqlQuery := `INSERT INTO network_routes
+		(airline_code, airline_iata, airline_icao, dep_iata_code, arr_iata_code, equipment, source, codeshare, stops)
+		VALUES ` + strings.Join(lines, ",\n") + `
+		ON DUPLICATE KEY UPDATE
+			airline_iata = VALUES(airline_iata),
+			airline_icao = VALUES(airline_icao),
+			equipment = VALUES(equipment),
+			source = VALUES(source),
+			codeshare = VALUES(codeshare),
+			stops = VALUES(stops),
+			updated_at = CURRENT_TIMESTAMP`
+
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindByDepartureAirport returns the routes from the airport
+func (dao *NetworkRoutesDao) FindByDepartureAirport(depIataCode string) ([]*tables.NetworkRoute, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := connection.Query(`SELECT airline_code, airline_iata, airline_icao, dep_iata_code, arr_iata_code,
+			codeshare, stops, equipment, source
+		FROM network_routes WHERE dep_iata_code = ? ORDER BY arr_iata_code, airline_code`, depIataCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routes []*tables.NetworkRoute
+	for rows.Next() {
+		route := &tables.NetworkRoute{}
+		var airlineIata, airlineIcao, equipment sql.NullString
+		err := rows.Scan(&route.AirlineCode, &airlineIata, &airlineIcao, &route.DepartureIata, &route.ArrivalIata,
+			&route.Codeshare, &route.Stops, &equipment, &route.Source)
+		if err != nil {
+			return nil, err
+		}
+		route.AirlineIata = airlineIata.String
+		route.AirlineIcao = airlineIcao.String
+		route.Equipment = equipment.String
+		routes = append(routes, route)
+	}
+
+	return routes, rows.Err()
+}
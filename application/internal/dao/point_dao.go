@@ -84,8 +84,31 @@ func (pointDao *PointDao) Count() (int, error) {
 	return count, nil
 }
 
-func (pointDao *PointDao) UpsertMany(points []*tables.Point) {
-	// TODO
+// UpsertMany inserts the points or updates the names and coordinates of the existing ones
+func (pointDao *PointDao) UpsertMany(points []*tables.Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	connection, err := pointDao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(points))
+	for i, point := range points {
+		lines[i] = fmt.Sprintf("('%s', %f,%f,'%s')", database.MysqlRealEscapeString(point.ID), point.X, point.Y, database.MysqlRealEscapeString(point.Name))
+	}
+
+	sql := "insert into points (ID, x,y,name) values " + strings.Join(lines, ",\n") +
+		"\non duplicate key update name = values(name), x = values(x), y = values(y)"
+
+	_, err = connection.Exec(sql)
+	if err != nil {
+		return errors.New(err.Error() + " for sql " + sql)
+	}
+
+	return nil
 }
 
 // FindByCoordinates finds a point by exact X and Y coordinates
@@ -0,0 +1,133 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+type RoutesDao struct {
+	database *database.Database
+}
+
+func NewRoutesDao(database *database.Database) *RoutesDao {
+	return &RoutesDao{database: database}
+}
+
+// UpsertRoutes inserts or updates the routes, the operating days found before are kept
+func (dao *RoutesDao) UpsertRoutes(routes []aviation_edge.RouteResponse) error {
+	if len(routes) == 0 {
+		return nil
+	}
+
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(routes))
+	for i, route := range routes {
+		values := util.ArrayMap([]string{
+			strings.ToUpper(route.AirlineIata),
+			route.AirlineIcao,
+			route.FlightNumber,
+			route.DepartureIata,
+			route.DepartureIcao,
+			route.DepartureTerminal,
+			route.DepartureTime,
+			route.ArrivalIata,
+			route.ArrivalIcao,
+			route.ArrivalTerminal,
+			route.ArrivalTime,
+			strings.Join(route.Codeshares, ","),
+		}, util.QuoteStringOrNull)
+		lines[i] = "(" + strings.Join(values, ",") + ")"
+	}
+
+	sqlQuery := `INSERT INTO routes
+		(airline_iata, airline_icao, flight_number, dep_iata_code, dep_icao_code, dep_terminal, dep_time,
+		arr_iata_code, arr_icao_code, arr_terminal, arr_time, codeshares)
+		VALUES ` + strings.Join(lines, ",\n") + `
+		ON DUPLICATE KEY UPDATE
+			airline_icao = VALUES(airline_icao),
+			dep_icao_code = VALUES(dep_icao_code),
+			dep_terminal = VALUES(dep_terminal),
+			dep_time = VALUES(dep_time),
+			arr_icao_code = VALUES(arr_icao_code),
+			arr_terminal = VALUES(arr_terminal),
+			arr_time = VALUES(arr_time),
+			codeshares = VALUES(codeshares),
+			updated_at = CURRENT_TIMESTAMP`
+
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// UpdateDaysOfWeekFromSchedules sets the operating days of the routes to the weekdays their flights depart on
+// in the collected departure schedules. Only the routes having schedules of at least a week are updated.
+// Returns the amount of updated routes.
+func (dao *RoutesDao) UpdateDaysOfWeekFromSchedules() (int64, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	sqlQuery := `UPDATE routes r
+		JOIN (
+			SELECT UPPER(flight_iata_number) AS flight_iata_number, dep_iata_code, arr_iata_code,
+				GROUP_CONCAT(DISTINCT WEEKDAY(COALESCE(dep_scheduled_time_local, dep_scheduled_time)) + 1
+					ORDER BY WEEKDAY(COALESCE(dep_scheduled_time_local, dep_scheduled_time)) SEPARATOR '') AS days_of_week
+			FROM flight_schedules
+			WHERE type = 'departure'
+			GROUP BY UPPER(flight_iata_number), dep_iata_code, arr_iata_code
+			HAVING DATEDIFF(MAX(dep_scheduled_time), MIN(dep_scheduled_time)) >= 6
+		) fs ON fs.flight_iata_number = CONCAT(r.airline_iata, r.flight_number)
+			AND fs.dep_iata_code = r.dep_iata_code
+			AND fs.arr_iata_code = r.arr_iata_code
+		SET r.days_of_week = fs.days_of_week`
+
+	result, err := connection.Exec(sqlQuery)
+	if err != nil {
+		return 0, errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return result.RowsAffected()
+}
+
+// FindAll returns all the routes
+func (dao *RoutesDao) FindAll() ([]*tables.Route, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := connection.Query(`SELECT airline_iata, flight_number, dep_iata_code, dep_time, arr_iata_code, arr_time, days_of_week
+		FROM routes ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routes []*tables.Route
+	for rows.Next() {
+		route := &tables.Route{}
+		var daysOfWeek sql.NullString
+		err := rows.Scan(&route.AirlineIata, &route.FlightNumber, &route.DepartureIata, &route.DepartureTime,
+			&route.ArrivalIata, &route.ArrivalTime, &daysOfWeek)
+		if err != nil {
+			return nil, err
+		}
+		route.DaysOfWeek = daysOfWeek.String
+		routes = append(routes, route)
+	}
+
+	return routes, rows.Err()
+}
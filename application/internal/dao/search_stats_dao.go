@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+)
+
+// SearchPair is an origin/destination pair with the amount of searches made for it
+type SearchPair struct {
+	From        string
+	To          string
+	SearchCount int
+}
+
+type SearchStatsDao struct {
+	database *database.Database
+}
+
+func NewSearchStatsDao(database *database.Database) *SearchStatsDao {
+	return &SearchStatsDao{database: database}
+}
+
+// CreateTable creates the table counting searches per origin/destination pair if it doesn't exist
+func (dao *SearchStatsDao) CreateTable() error {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Exec(`create table if not exists search_stats (
+		from_point varchar(64) not null,
+		to_point varchar(64) not null,
+		search_count int not null default 0,
+		last_searched_at timestamp default current_timestamp on update current_timestamp,
+		primary key (from_point, to_point),
+		index idx_search_count (search_count)
+	)`)
+
+	return err
+}
+
+// RegisterSearch increments the search counter of the pair, the table is created by createdb and computepatterns
+func (dao *SearchStatsDao) RegisterSearch(from, to string) error {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Exec(`INSERT INTO search_stats (from_point, to_point, search_count)
+		VALUES (?, ?, 1)
+		ON DUPLICATE KEY UPDATE search_count = search_count + 1`, from, to)
+
+	return err
+}
+
+// GetTopPairs returns the most searched pairs, most searched first
+func (dao *SearchStatsDao) GetTopPairs(limit int) ([]*SearchPair, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := connection.Query(`SELECT from_point, to_point, search_count FROM search_stats
+		ORDER BY search_count DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []*SearchPair
+	for rows.Next() {
+		pair := &SearchPair{}
+		err := rows.Scan(&pair.From, &pair.To, &pair.SearchCount)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, rows.Err()
+}
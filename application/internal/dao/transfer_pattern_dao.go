@@ -0,0 +1,111 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"darbelis.eu/persedimai/internal/transfer_patterns"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type TransferPatternDao struct {
+	database *database.Database
+}
+
+func NewTransferPatternDao(database *database.Database) *TransferPatternDao {
+	return &TransferPatternDao{database: database}
+}
+
+// ReplaceForSource deletes previously computed patterns from the source to the given destinations
+// and inserts the new ones
+func (dao *TransferPatternDao) ReplaceForSource(source string, destinations []string, patterns []*transfer_patterns.TransferPattern) error {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	if len(destinations) > 0 {
+		escapedDestinations := make([]string, len(destinations))
+		for i, destination := range destinations {
+			escapedDestinations[i] = fmt.Sprintf("'%s'", database.MysqlRealEscapeString(destination))
+		}
+
+		_, err = connection.Exec(fmt.Sprintf("delete from transfer_patterns where from_point = ? and to_point in (%s)",
+			strings.Join(escapedDestinations, ",")), source)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		lines[i] = fmt.Sprintf("('%s', '%s', '%s', %d, %d)",
+			database.MysqlRealEscapeString(pattern.From),
+			database.MysqlRealEscapeString(pattern.To),
+			database.MysqlRealEscapeString(pattern.Key()),
+			pattern.Legs,
+			pattern.Hits)
+	}
+
+	sqlQuery := "insert into transfer_patterns (from_point, to_point, pattern, legs, hits) values " +
+		strings.Join(lines, ",\n") +
+		" on duplicate key update legs = values(legs), hits = values(hits)"
+
+	_, err = connection.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+
+	return nil
+}
+
+// FindByPair returns the patterns from one point to another having the given amount of legs, most used first
+func (dao *TransferPatternDao) FindByPair(from, to string, legs int) ([]*transfer_patterns.TransferPattern, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT from_point, to_point, pattern, legs, hits FROM transfer_patterns
+		WHERE from_point = ? AND to_point = ? AND legs = ?
+		ORDER BY hits DESC`
+	rows, err := connection.Query(sqlQuery, from, to, legs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patterns []*transfer_patterns.TransferPattern
+	for rows.Next() {
+		pattern := &transfer_patterns.TransferPattern{}
+		var joined string
+		err := rows.Scan(&pattern.From, &pattern.To, &joined, &pattern.Legs, &pattern.Hits)
+		if err != nil {
+			return nil, err
+		}
+		pattern.Intermediate = transfer_patterns.SplitPoints(joined)
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, rows.Err()
+}
+
+// CountByPair returns how many patterns of any legs amount are computed from one point to another
+func (dao *TransferPatternDao) CountByPair(from, to string) (int, error) {
+	connection, err := dao.database.GetConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = connection.QueryRow("SELECT COUNT(*) FROM transfer_patterns WHERE from_point = ? AND to_point = ?", from, to).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
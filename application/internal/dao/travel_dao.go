@@ -5,6 +5,7 @@ import (
 	"darbelis.eu/persedimai/internal/data"
 	"darbelis.eu/persedimai/internal/database"
 	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -22,6 +23,8 @@ var MAX_CLUSTERED_CONNECTION_TIME_RANGE = []int{
 type TravelDao struct {
 	database *database.Database
 	Timeout  time.Duration // Query timeout (0 = no timeout)
+	// Diagnostics collects executed queries with their EXPLAIN output when set (nil = disabled)
+	Diagnostics *data.SearchDiagnostics
 }
 
 func NewTravelDao(database *database.Database) *TravelDao {
@@ -31,34 +34,86 @@ func NewTravelDao(database *database.Database) *TravelDao {
 	}
 }
 
+// InsertMany inserts the travels. The month partitions of their departures have to be added before,
+// see migrations.PartitionsManager.EnsureMonthPartitions.
 func (td *TravelDao) InsertMany(travels []*tables.Transfer) error {
+	return td.insertMany(travels, "")
+}
+
+// UpsertMany inserts the travels updating the ones of the same id
+func (td *TravelDao) UpsertMany(travels []*tables.Transfer) error {
+	return td.insertMany(travels, ` on duplicate key update
+		from_point = values(from_point),
+		to_point = values(to_point),
+		arrival = values(arrival),
+		airline = values(airline),
+		operator = values(operator),
+		service_number = values(service_number),
+		mode = values(mode),
+		vehicle_type = values(vehicle_type),
+		terminal = values(terminal)`)
+}
+
+// insertMany keeps the id unique although the primary key is (id, departure) for the partitioning:
+// the travels of the same id and another departure are deleted first
+func (td *TravelDao) insertMany(travels []*tables.Transfer, onDuplicateSql string) error {
+	if len(travels) == 0 {
+		return nil
+	}
+
 	connection, err := td.database.GetConnection()
 	if err != nil {
 		return err
 	}
 
+	tx, err := connection.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	lines := make([]string, len(travels))
+	ids := make([]string, len(travels))
+	keys := make([]string, len(travels))
 	for i, travel := range travels {
-		line := fmt.Sprintf("('%s', '%s', '%s', '%s', '%s')",
+		details := util.ArrayMap([]string{
+			travel.Airline,
+			travel.Operator,
+			travel.ServiceNumber,
+			travel.Mode,
+			travel.VehicleType,
+			travel.Terminal,
+		}, util.QuoteStringOrNull)
+		line := fmt.Sprintf("('%s', '%s', '%s', '%s', '%s', %s)",
 			database.MysqlRealEscapeString(travel.ID),
 			database.MysqlRealEscapeString(travel.From),
 			database.MysqlRealEscapeString(travel.To),
 			travel.Departure.Format("2006-01-02 15:04:05"),
-			travel.Arrival.Format("2006-01-02 15:04:05"))
+			travel.Arrival.Format("2006-01-02 15:04:05"),
+			strings.Join(details, ", "))
 		lines[i] = line
+		ids[i] = "'" + database.MysqlRealEscapeString(travel.ID) + "'"
+		keys[i] = fmt.Sprintf("(%s, '%s')", ids[i], travel.Departure.Format("2006-01-02 15:04:05"))
+	}
+
+	sqlQuery := "delete from travels where id in (" + strings.Join(ids, ", ") + ")" +
+		" and (id, departure) not in (" + strings.Join(keys, ", ") + ")"
+	_, err = tx.Exec(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
 	}
 
 	valuesSubSql := strings.Join(lines, ",\n")
 
-	sqlQuery := "insert into travels (ID, from_point, to_point, departure, arrival) values " + valuesSubSql
+	sqlQuery = "insert into travels (ID, from_point, to_point, departure, arrival, airline, operator, service_number, mode, vehicle_type, terminal) values " + valuesSubSql + onDuplicateSql
 
-	_, err = connection.Exec(sqlQuery)
+	_, err = tx.Exec(sqlQuery)
 
 	if err != nil {
 		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // SelectAll loads all travels from db. Should be avoided to call unless for testing purposes.
@@ -88,6 +143,112 @@ func (td *TravelDao) SelectAll() ([]*tables.Transfer, error) {
 	return travels, nil
 }
 
+// ForEach streams the travels departing within the range (all when the range is zero) ordered by departure
+// to the consumer without loading them all, the consumer error stops the reading
+func (td *TravelDao) ForEach(departureFrom, departureTo time.Time, consume func(travel *tables.Transfer) error) error {
+	connection, err := td.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := "SELECT id, from_point, to_point, departure, arrival, " + travelDetailsColumns + " FROM travels"
+	var args []interface{}
+	if !departureFrom.IsZero() || !departureTo.IsZero() {
+		sqlQuery += " WHERE departure >= ? AND departure < ?"
+		args = append(args, departureFrom, departureTo)
+	}
+	sqlQuery += " ORDER BY departure, id"
+
+	rows, err := connection.Query(sqlQuery, args...)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		travel := &tables.Transfer{}
+		details := &travelDetails{}
+		err = rows.Scan(append([]any{&travel.ID, &travel.From, &travel.To, &travel.Departure, &travel.Arrival}, details.targets()...)...)
+		if err != nil {
+			return err
+		}
+		details.apply(travel)
+		err = consume(travel)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// LoadDetails sets the operating airlines and the service details of the transfers found by the searches,
+// which load the times only. The travels are found by their primary key, the id and the departure.
+func (td *TravelDao) LoadDetails(transfers []*tables.Transfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	connection, err := td.database.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	byKey := make(map[string][]*tables.Transfer)
+	var keys []string
+	for _, transfer := range transfers {
+		key := transfer.ID + "|" + transfer.Departure.Format(time.DateTime)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, "("+util.QuoteString(transfer.ID)+", "+util.QuoteString(transfer.Departure.Format(time.DateTime))+")")
+		}
+		byKey[key] = append(byKey[key], transfer)
+	}
+
+	sqlQuery := `SELECT id, departure, ` + travelDetailsColumns + `
+		FROM travels
+		WHERE (id, departure) IN (` + strings.Join(keys, ",") + ")"
+	rows, err := connection.Query(sqlQuery)
+	if err != nil {
+		return errors.New(err.Error() + " for sqlQuery " + sqlQuery)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var departure time.Time
+		details := &travelDetails{}
+		err = rows.Scan(append([]any{&id, &departure}, details.targets()...)...)
+		if err != nil {
+			return err
+		}
+		for _, transfer := range byKey[id+"|"+departure.Format(time.DateTime)] {
+			details.apply(transfer)
+		}
+	}
+
+	return rows.Err()
+}
+
+// travelDetailsColumns are the nullable columns of the service details, scanned by travelDetails
+const travelDetailsColumns = "airline, operator, service_number, mode, vehicle_type, terminal"
+
+type travelDetails struct {
+	airline, operator, serviceNumber, mode, vehicleType, terminal sql.NullString
+}
+
+func (d *travelDetails) targets() []any {
+	return []any{&d.airline, &d.operator, &d.serviceNumber, &d.mode, &d.vehicleType, &d.terminal}
+}
+
+func (d *travelDetails) apply(transfer *tables.Transfer) {
+	transfer.Airline = d.airline.String
+	transfer.Operator = d.operator.String
+	transfer.ServiceNumber = d.serviceNumber.String
+	transfer.Mode = d.mode.String
+	transfer.VehicleType = d.vehicleType.String
+	transfer.Terminal = d.terminal.String
+}
+
 func (td *TravelDao) Count() (int, error) {
 	connection, err := td.database.GetConnection()
 	if err != nil {
@@ -158,6 +319,54 @@ func (td *TravelDao) FindByIDs(ids []string) ([]*tables.Transfer, error) {
 	return travels, nil
 }
 
+// FindByDepartureRange loads travels departing within the given range ordered by departure
+func (td *TravelDao) FindByDepartureRange(departureFrom, departureTo time.Time) ([]*tables.Transfer, error) {
+	connection, err := td.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT id, from_point, to_point, departure, arrival FROM travels
+		WHERE departure BETWEEN ? AND ?
+		ORDER BY departure`
+
+	return td.queryTransfers(connection, sqlQuery, departureFrom, departureTo)
+}
+
+// FindByPointsAndArrivalRange loads travels from one point to another arriving within the given range
+func (td *TravelDao) FindByPointsAndArrivalRange(fromPointID, toPointID string, arrivalFrom, arrivalTo time.Time) ([]*tables.Transfer, error) {
+	connection, err := td.database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT id, from_point, to_point, departure, arrival FROM travels
+		WHERE from_point = ? AND to_point = ? AND arrival BETWEEN ? AND ?
+		ORDER BY arrival`
+
+	return td.queryTransfers(connection, sqlQuery, fromPointID, toPointID, arrivalFrom, arrivalTo)
+}
+
+func (td *TravelDao) queryTransfers(connection *sql.DB, sqlQuery string, args ...interface{}) ([]*tables.Transfer, error) {
+	rows, err := td.executeQueryWithConfiguration(connection, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var travels []*tables.Transfer
+	for rows.Next() {
+		travel := &tables.Transfer{}
+		err := rows.Scan(&travel.ID, &travel.From, &travel.To, &travel.Departure, &travel.Arrival)
+		if err != nil {
+			return nil, err
+		}
+		travels = append(travels, travel)
+	}
+
+	return travels, rows.Err()
+}
+
 func (td *TravelDao) Insert(t *tables.Transfer) {
 	// TODO
 }
@@ -997,6 +1206,10 @@ func (td *TravelDao) FindPath8Clustered5(fromPointID, toPointID string, arrivalT
 // executeQueryWithConfiguration executes a query with optional timeout (both client and server side)
 // Supports both direct SQL and parameterized queries
 func (td *TravelDao) executeQueryWithConfiguration(connection *sql.DB, sqlQuery string, args ...interface{}) (*sql.Rows, error) {
+	if td.Diagnostics.IsEnabled() {
+		return td.executeQueryWithDiagnostics(connection, sqlQuery, args...)
+	}
+
 	skipTimeout := true
 	if skipTimeout || td.Timeout <= 0 {
 		return connection.Query(sqlQuery, args...)
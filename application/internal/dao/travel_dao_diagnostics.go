@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/database"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// executeQueryWithDiagnostics executes the query and records it together with its EXPLAIN output
+func (td *TravelDao) executeQueryWithDiagnostics(connection *sql.DB, sqlQuery string, args ...interface{}) (*sql.Rows, error) {
+	queryDiagnostics := &data.QueryDiagnostics{SQL: sqlQuery}
+	for _, arg := range args {
+		queryDiagnostics.Args = append(queryDiagnostics.Args, formatQueryArg(arg))
+	}
+
+	startTime := time.Now()
+	rows, err := connection.Query(sqlQuery, args...)
+	queryDiagnostics.Duration = time.Since(startTime)
+	if err != nil {
+		queryDiagnostics.Error = err.Error()
+	}
+
+	td.explainQuery(connection, queryDiagnostics, sqlQuery, args...)
+	td.Diagnostics.AddQuery(queryDiagnostics)
+
+	return rows, err
+}
+
+// explainQuery runs EXPLAIN, which reports estimated rows only. ANALYZE executes the query a second time
+// to report the actual rows read (r_rows), so it is run on MariaDB only when the diagnostics ask to analyze.
+func (td *TravelDao) explainQuery(connection *sql.DB, queryDiagnostics *data.QueryDiagnostics, sqlQuery string, args ...interface{}) {
+	baseQuery := database.RemoveTimeoutFromQuery(sqlQuery)
+
+	rowsColumn := "rows"
+	explainQuery := "EXPLAIN " + baseQuery
+	if td.Diagnostics.Analyze && td.database.IsMariaDB() {
+		rowsColumn = "r_rows"
+		explainQuery = "ANALYZE " + baseQuery
+	}
+	queryDiagnostics.RowsExaminedEstimated = rowsColumn == "rows"
+
+	rows, err := connection.Query(explainQuery, args...)
+	if err != nil {
+		queryDiagnostics.ExplainError = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		queryDiagnostics.ExplainError = err.Error()
+		return
+	}
+	queryDiagnostics.ExplainColumns = columns
+
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		err = rows.Scan(pointers...)
+		if err != nil {
+			queryDiagnostics.ExplainError = err.Error()
+			return
+		}
+
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = value.String
+			if columns[i] == rowsColumn && value.Valid {
+				examined, err := strconv.ParseFloat(value.String, 64)
+				if err == nil {
+					queryDiagnostics.RowsExamined += int64(examined)
+				}
+			}
+		}
+		queryDiagnostics.ExplainRows = append(queryDiagnostics.ExplainRows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		queryDiagnostics.ExplainError = err.Error()
+	}
+}
+
+func formatQueryArg(arg interface{}) string {
+	switch value := arg.(type) {
+	case time.Time:
+		return value.Format(time.DateTime)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
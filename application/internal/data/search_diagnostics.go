@@ -0,0 +1,79 @@
+package data
+
+import "time"
+
+// QueryDiagnostics describes one executed search query
+type QueryDiagnostics struct {
+	// SQL is the query actually executed, including the timeout hint
+	SQL      string
+	Args     []string
+	Duration time.Duration
+	Error    string
+
+	ExplainColumns []string
+	ExplainRows    [][]string
+	ExplainError   string
+	// RowsExamined is the sum of r_rows of ANALYZE on MariaDB when analyzed, otherwise the sum of estimated EXPLAIN rows
+	RowsExamined          int64
+	RowsExaminedEstimated bool
+}
+
+// StepTiming is the duration of one step of a search strategy
+type StepTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// DiscardCount is the amount of found sequences discarded for a reason
+type DiscardCount struct {
+	Reason string
+	Count  int
+}
+
+// SearchDiagnostics collects executed queries, step timings and discarded results of a search.
+// All the methods may be called on a nil receiver, then nothing is collected.
+type SearchDiagnostics struct {
+	Queries  []*QueryDiagnostics
+	Steps    []*StepTiming
+	Discards []*DiscardCount
+	// Analyze runs ANALYZE instead of EXPLAIN on MariaDB, which executes every query a second time
+	// to report the actual rows read
+	Analyze bool
+}
+
+func NewSearchDiagnostics() *SearchDiagnostics {
+	return &SearchDiagnostics{}
+}
+
+func (d *SearchDiagnostics) IsEnabled() bool {
+	return d != nil
+}
+
+func (d *SearchDiagnostics) AddQuery(query *QueryDiagnostics) {
+	if d == nil {
+		return
+	}
+
+	d.Queries = append(d.Queries, query)
+}
+
+// StartStep starts timing of a step, the returned function stops it
+func (d *SearchDiagnostics) StartStep(name string) func() {
+	if d == nil {
+		return func() {}
+	}
+
+	startTime := time.Now()
+
+	return func() {
+		d.Steps = append(d.Steps, &StepTiming{Name: name, Duration: time.Since(startTime)})
+	}
+}
+
+func (d *SearchDiagnostics) AddDiscards(reason string, count int) {
+	if d == nil || count == 0 {
+		return
+	}
+
+	d.Discards = append(d.Discards, &DiscardCount{Reason: reason, Count: count})
+}
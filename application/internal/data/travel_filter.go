@@ -1,6 +1,10 @@
 package data
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 type TravelFilter struct {
 	Source          string
@@ -10,9 +14,17 @@ type TravelFilter struct {
 	TravelCount     int
 	Limit           int // default 10
 	// @deprecated
-	MaxWaitHoursBetweenTransits int // default 24
-	MinConnectionTimeMinutes    int // default 30, minimum time between transfers for comfortable walking
-	MaxConnectionTimeHours      int // default 32, maximum time allowed between connections
+	MaxWaitHoursBetweenTransits int     // default 24
+	MinConnectionTimeMinutes    int     // default 30, minimum time between transfers for comfortable walking
+	MaxConnectionTimeHours      int     // default 32, maximum time allowed between connections
+	MinReliability              float64 // default 0, minimum probability of making all the connections
+	// IncludeAirlines and ExcludeAirlines are IATA codes of the airlines the flights must or must not be operated by
+	IncludeAirlines []string
+	ExcludeAirlines []string
+	// SameAirlineOnly keeps the paths with all the flights operated by one airline
+	SameAirlineOnly bool
+	// Currency of the fares the paths are priced in, default EUR
+	Currency string
 }
 
 // NewTravelFilter creates a new TravelFilter with default values for Limit, MaxWaitHoursBetweenTransits, MinConnectionTimeMinutes, and MaxConnectionTimeHours
@@ -28,6 +40,7 @@ func NewTravelFilter(source, destination string, arrivalTimeFrom, arrivalTimeTo
 		MaxWaitHoursBetweenTransits: 24,
 		MinConnectionTimeMinutes:    30,
 		MaxConnectionTimeHours:      32,
+		Currency:                    "EUR",
 	}
 }
 
@@ -41,3 +54,44 @@ func (tf *TravelFilter) ValidateMaxConnectionTime(allowedValues []int) bool {
 	}
 	return false
 }
+
+// ParseAirlines splits comma or space separated airline IATA codes
+func ParseAirlines(value string) []string {
+	var airlines []string
+	for _, code := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		code = strings.ToUpper(code)
+		if !slices.Contains(airlines, code) {
+			airlines = append(airlines, code)
+		}
+	}
+
+	return airlines
+}
+
+// HasAirlineFilter tells if the found paths are to be filtered by their airlines
+func (tf *TravelFilter) HasAirlineFilter() bool {
+	return len(tf.IncludeAirlines) > 0 || len(tf.ExcludeAirlines) > 0 || tf.SameAirlineOnly
+}
+
+// AllowsAirlines tells if a path of the travels operated by the given airlines passes the airline filter.
+// Travels without an airline (not flights) are not subject to it.
+func (tf *TravelFilter) AllowsAirlines(airlines []string) bool {
+	first := ""
+	for _, airline := range airlines {
+		if airline == "" {
+			continue
+		}
+		if len(tf.IncludeAirlines) > 0 && !slices.Contains(tf.IncludeAirlines, airline) {
+			return false
+		}
+		if slices.Contains(tf.ExcludeAirlines, airline) {
+			return false
+		}
+		if tf.SameAirlineOnly && first != "" && airline != first {
+			return false
+		}
+		first = airline
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package data
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAirlines(t *testing.T) {
+	airlines := ParseAirlines(" bt, BA,,lh bt ")
+	expected := []string{"BT", "BA", "LH"}
+	if !slices.Equal(airlines, expected) {
+		t.Errorf("Expected %v, got %v", expected, airlines)
+	}
+
+	if airlines := ParseAirlines(""); len(airlines) != 0 {
+		t.Errorf("Expected no airlines, got %v", airlines)
+	}
+}
+
+func TestTravelFilter_AllowsAirlines(t *testing.T) {
+	newFilter := func() *TravelFilter {
+		return NewTravelFilter("VNO", "LHR", time.Time{}, time.Time{}, 2)
+	}
+
+	t.Run("no airline filter", func(t *testing.T) {
+		filter := newFilter()
+		if filter.HasAirlineFilter() {
+			t.Error("Expected no airline filter by default")
+		}
+		if !filter.AllowsAirlines([]string{"BT", "BA"}) {
+			t.Error("Expected any airlines allowed")
+		}
+	})
+
+	t.Run("include airlines", func(t *testing.T) {
+		filter := newFilter()
+		filter.IncludeAirlines = []string{"BT", "LH"}
+		if !filter.HasAirlineFilter() {
+			t.Error("Expected airline filter")
+		}
+		if !filter.AllowsAirlines([]string{"BT", "LH"}) {
+			t.Error("Expected BT and LH allowed")
+		}
+		if filter.AllowsAirlines([]string{"BT", "BA"}) {
+			t.Error("Expected BA not allowed")
+		}
+	})
+
+	t.Run("exclude airlines", func(t *testing.T) {
+		filter := newFilter()
+		filter.ExcludeAirlines = []string{"FR"}
+		if filter.AllowsAirlines([]string{"BT", "FR"}) {
+			t.Error("Expected FR not allowed")
+		}
+		if !filter.AllowsAirlines([]string{"BT", "BA"}) {
+			t.Error("Expected BT and BA allowed")
+		}
+	})
+
+	t.Run("same airline only", func(t *testing.T) {
+		filter := newFilter()
+		filter.SameAirlineOnly = true
+		if !filter.AllowsAirlines([]string{"BT", "BT"}) {
+			t.Error("Expected a single airline allowed")
+		}
+		if filter.AllowsAirlines([]string{"BT", "BA"}) {
+			t.Error("Expected two airlines not allowed")
+		}
+	})
+
+	t.Run("travels without airline", func(t *testing.T) {
+		filter := newFilter()
+		filter.IncludeAirlines = []string{"BT"}
+		filter.SameAirlineOnly = true
+		if !filter.AllowsAirlines([]string{"", "BT", "", "BT"}) {
+			t.Error("Expected travels without airline ignored")
+		}
+	})
+}
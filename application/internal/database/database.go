@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -138,3 +139,14 @@ func (db *Database) AddTimeoutToQuery(baseQuery string, timeout time.Duration) s
 			strings.TrimPrefix(baseQuery, "SELECT"))
 	}
 }
+
+var timeoutHintRegexp = regexp.MustCompile(`(?is)^SET STATEMENT max_statement_time=\d+ FOR |/\*\+ MAX_EXECUTION_TIME\(\d+\) \*/ ?`)
+
+// RemoveTimeoutFromQuery reverts AddTimeoutToQuery, so that the query may be prefixed with EXPLAIN
+func RemoveTimeoutFromQuery(query string) string {
+	return timeoutHintRegexp.ReplaceAllString(query, "")
+}
+
+func (db *Database) IsMariaDB() bool {
+	return strings.Contains(strings.ToLower(db.CheckVersion()), "mariadb")
+}
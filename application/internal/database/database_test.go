@@ -0,0 +1,26 @@
+package database
+
+import "testing"
+
+func TestRemoveTimeoutFromQuery(t *testing.T) {
+	t.Run("MariaDB", func(t *testing.T) {
+		result := RemoveTimeoutFromQuery("SET STATEMENT max_statement_time=32 FOR SELECT id FROM travels")
+		if result != "SELECT id FROM travels" {
+			t.Errorf("Expected query without timeout, got %s", result)
+		}
+	})
+
+	t.Run("MySQL", func(t *testing.T) {
+		result := RemoveTimeoutFromQuery("SELECT /*+ MAX_EXECUTION_TIME(32000) */  id FROM travels")
+		if result != "SELECT  id FROM travels" {
+			t.Errorf("Expected query without timeout, got %s", result)
+		}
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		result := RemoveTimeoutFromQuery("SELECT id FROM travels")
+		if result != "SELECT id FROM travels" {
+			t.Errorf("Expected unchanged query, got %s", result)
+		}
+	})
+}
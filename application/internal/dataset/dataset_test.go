@@ -0,0 +1,225 @@
+package dataset
+
+import (
+	"bytes"
+	"darbelis.eu/persedimai/internal/tables"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	points := []*tables.Point{
+		{ID: "VNO", Name: "Vilnius, \"International\"", X: 25.2858, Y: 54.6341},
+		{ID: "17", X: 2000, Y: 0},
+	}
+	travels := []*tables.Transfer{
+		{ID: "BT344_202603012330_VNORIX", From: "VNO", To: "17",
+			Departure: time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC), Arrival: time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC),
+			Airline: "BT", ServiceNumber: "BT344", Mode: tables.TransportModeFlight, VehicleType: "223", Terminal: "A"},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buffer bytes.Buffer
+			pointsEncoder, err := NewPointsEncoder(&buffer, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, point := range points {
+				_ = pointsEncoder.Encode(point)
+			}
+			if err = pointsEncoder.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			var read []*tables.Point
+			err = ReadPoints(&buffer, format, func(line int, point *tables.Point) error {
+				read = append(read, point)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(read) != 2 || *read[0] != *points[0] || *read[1] != *points[1] {
+				t.Errorf("Expected %+v %+v, got %+v", *points[0], *points[1], read)
+			}
+
+			buffer.Reset()
+			travelsEncoder, _ := NewTravelsEncoder(&buffer, format)
+			_ = travelsEncoder.Encode(travels[0])
+			if err = travelsEncoder.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			var readTravels []*tables.Transfer
+			err = ReadTravels(&buffer, format, func(line int, travel *tables.Transfer) error {
+				readTravels = append(readTravels, travel)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(readTravels) != 1 || *readTravels[0] != *travels[0] {
+				t.Errorf("Expected %+v, got %+v", *travels[0], readTravels)
+			}
+		})
+	}
+}
+
+func TestReadTravels(t *testing.T) {
+	t.Run("malformed line", func(t *testing.T) {
+		input := "id,from,to,departure,arrival\nT1,A,B,2026-03-01T10:00:00Z,tomorrow\n"
+		err := ReadTravels(strings.NewReader(input), FormatCSV, func(line int, travel *tables.Transfer) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected error of line 2, got %v", err)
+		}
+	})
+
+	t.Run("malformed first row", func(t *testing.T) {
+		input := "id,from,to,departure,arrival\n\"\n"
+		err := ReadTravels(strings.NewReader(input), FormatCSV, func(line int, travel *tables.Transfer) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected error of line 2, got %v", err)
+		}
+	})
+
+	t.Run("without service details", func(t *testing.T) {
+		input := "id,from,to,departure,arrival\nT1,A,B,2026-03-01T10:00:00Z,2026-03-01T11:00:00Z\n"
+		var travel *tables.Transfer
+		err := ReadTravels(strings.NewReader(input), FormatCSV, func(line int, read *tables.Transfer) error {
+			travel = read
+			return nil
+		})
+		if err != nil || travel == nil || travel.ID != "T1" || travel.Mode != "" {
+			t.Errorf("Expected T1 without mode, got %+v and %v", travel, err)
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		err := ReadTravels(strings.NewReader("id,from,to,departure\n"), FormatCSV, func(line int, travel *tables.Transfer) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "arrival") {
+			t.Errorf("Expected error of the missing arrival column, got %v", err)
+		}
+	})
+
+	t.Run("ndjson line numbers skip empty lines", func(t *testing.T) {
+		input := "\n{\"id\":\"T1\",\"from\":\"A\",\"to\":\"B\",\"departure\":\"2026-03-01T10:00:00Z\",\"arrival\":\"2026-03-01T11:00:00+01:00\"}\n"
+		var lines []int
+		var arrival time.Time
+		err := ReadTravels(strings.NewReader(input), FormatNDJSON, func(line int, travel *tables.Transfer) error {
+			lines = append(lines, line)
+			arrival = travel.Arrival
+			return nil
+		})
+		if err != nil || len(lines) != 1 || lines[0] != 2 {
+			t.Errorf("Expected the travel of line 2, got %v %v", lines, err)
+		}
+		if arrival != time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC) {
+			t.Errorf("Expected the arrival converted to 10:00 UTC, got %s", arrival)
+		}
+	})
+}
+
+func TestValidator(t *testing.T) {
+	validator := NewValidator([]string{"VNO"})
+	departure := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	valid := []bool{
+		validator.ValidatePoint(2, &tables.Point{ID: "RIX"}),
+		validator.ValidatePoint(3, &tables.Point{ID: "RIX"}),
+		validator.ValidatePoint(4, &tables.Point{ID: ""}),
+		validator.ValidateTravel(2, &tables.Transfer{ID: "T1", From: "VNO", To: "RIX", Departure: departure, Arrival: departure.Add(time.Hour)}),
+		validator.ValidateTravel(3, &tables.Transfer{ID: "T1", From: "VNO", To: "RIX", Departure: departure, Arrival: departure.Add(time.Hour)}),
+		validator.ValidateTravel(4, &tables.Transfer{ID: "T2", From: "VNO", To: "WAW", Departure: departure, Arrival: departure.Add(time.Hour)}),
+		validator.ValidateTravel(5, &tables.Transfer{ID: "T3", From: "RIX", To: "VNO", Departure: departure, Arrival: departure.Add(-time.Hour)}),
+	}
+	expected := []bool{true, false, false, true, false, false, false}
+	for i := range expected {
+		if valid[i] != expected[i] {
+			t.Errorf("Expected validation %d to be %v, got %v", i, expected[i], valid[i])
+		}
+	}
+
+	reasons := []string{"duplicate id", "empty id", "duplicate id", "unknown point WAW", "arrival before departure"}
+	if len(validator.Errors) != len(reasons) {
+		t.Fatalf("Expected %d errors, got %v", len(reasons), validator.Errors)
+	}
+	for i, reason := range reasons {
+		if validator.Errors[i].Reason != reason {
+			t.Errorf("Expected %s, got %s", reason, validator.Errors[i].Reason)
+		}
+	}
+}
+
+func TestReadFares(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		input := "id,travel_id,from,to,valid_from,valid_to,days_of_week,amount,currency\n" +
+			"F1,BT344_202603012330_VNORIX,,,,,,45.50,eur\n" +
+			"F2,,VNO,RIX,2026-03-01,2026-03-31,67,90,EUR\n"
+		var fares []*tables.Fare
+		err := ReadFares(strings.NewReader(input), FormatCSV, func(line int, fare *tables.Fare) error {
+			fares = append(fares, fare)
+			return nil
+		})
+		if err != nil || len(fares) != 2 {
+			t.Fatalf("Expected 2 fares, got %d and %v", len(fares), err)
+		}
+		if fares[0].TravelID != "BT344_202603012330_VNORIX" || fares[0].Amount != 45.5 || fares[0].Currency != "EUR" {
+			t.Errorf("Expected travel fare of 45.50 EUR, got %+v", fares[0])
+		}
+		if fares[1].From != "VNO" || fares[1].ValidTo != "2026-03-31" || fares[1].DaysOfWeek != "67" {
+			t.Errorf("Expected weekend route fare of VNO in March, got %+v", fares[1])
+		}
+	})
+
+	t.Run("ndjson route fare", func(t *testing.T) {
+		input := `{"id":"F3","from":"VNO","to":"RIX","amount":80,"currency":"EUR"}` + "\n"
+		var fare *tables.Fare
+		err := ReadFares(strings.NewReader(input), FormatNDJSON, func(line int, read *tables.Fare) error {
+			fare = read
+			return nil
+		})
+		if err != nil || fare == nil || fare.To != "RIX" || fare.Amount != 80 || fare.ValidFrom != "" {
+			t.Errorf("Expected open route fare of 80, got %+v and %v", fare, err)
+		}
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		err := ReadFares(strings.NewReader("id,amount,currency\nF1,free,EUR\n"), FormatCSV, func(line int, fare *tables.Fare) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected error of line 2, got %v", err)
+		}
+	})
+}
+
+func TestValidator_ValidateFare(t *testing.T) {
+	validator := NewValidator([]string{"VNO", "RIX"})
+
+	cases := []struct {
+		fare   *tables.Fare
+		reason string
+	}{
+		{&tables.Fare{ID: "F1", TravelID: "T1", Amount: 10, Currency: "EUR"}, ""},
+		{&tables.Fare{ID: "F1", TravelID: "T2", Amount: 10, Currency: "EUR"}, "duplicate id"},
+		{&tables.Fare{ID: "F2", Amount: 10, Currency: "EUR"}, "neither travel_id nor from and to"},
+		{&tables.Fare{ID: "F3", TravelID: "T1", From: "VNO", To: "RIX", Amount: 10, Currency: "EUR"}, "both travel_id and from and to"},
+		{&tables.Fare{ID: "F4", From: "VNO", To: "WAW", Amount: 10, Currency: "EUR"}, "unknown point WAW"},
+		{&tables.Fare{ID: "F5", From: "VNO", To: "RIX", Amount: -1, Currency: "EUR"}, "negative amount"},
+		{&tables.Fare{ID: "F6", From: "VNO", To: "RIX", Amount: 10, Currency: "EURO"}, "invalid currency EURO"},
+		{&tables.Fare{ID: "F7", From: "VNO", To: "RIX", Amount: 10, Currency: "EUR", ValidFrom: "2026-03-31", ValidTo: "2026-03-01"}, "valid_to before valid_from"},
+		{&tables.Fare{ID: "F8", From: "VNO", To: "RIX", Amount: 10, Currency: "EUR", ValidTo: "31/03/2026"}, "invalid valid_to 31/03/2026"},
+		{&tables.Fare{ID: "F9", From: "VNO", To: "RIX", Amount: 10, Currency: "EUR", DaysOfWeek: "08"}, "invalid days_of_week 08"},
+		{&tables.Fare{ID: "F10", From: "VNO", To: "RIX", Amount: 10, Currency: "EUR", ValidFrom: "2026-03-01", DaysOfWeek: "67"}, ""},
+	}
+	for i, c := range cases {
+		failed := len(validator.Errors)
+		valid := validator.ValidateFare(i+2, c.fare)
+		if valid != (c.reason == "") {
+			t.Errorf("Expected validation of %s to be %v, got %v", c.fare.ID, c.reason == "", valid)
+		}
+		if !valid && validator.Errors[failed].Reason != c.reason {
+			t.Errorf("Expected %s, got %s", c.reason, validator.Errors[failed].Reason)
+		}
+	}
+}
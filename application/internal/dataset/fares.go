@@ -0,0 +1,54 @@
+package dataset
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// faresHeader are the required columns, travel_id or from and to, valid_from, valid_to and days_of_week are optional
+var faresHeader = []string{"id", "amount", "currency"}
+
+type fareRecord struct {
+	ID         string  `json:"id"`
+	TravelID   string  `json:"travel_id,omitempty"`
+	From       string  `json:"from,omitempty"`
+	To         string  `json:"to,omitempty"`
+	ValidFrom  string  `json:"valid_from,omitempty"`
+	ValidTo    string  `json:"valid_to,omitempty"`
+	DaysOfWeek string  `json:"days_of_week,omitempty"`
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+}
+
+// ReadFares passes the fares of the reader with their line numbers to the consumer,
+// a malformed line or the consumer error stops the reading
+func ReadFares(r io.Reader, format Format, consume func(line int, fare *tables.Fare) error) error {
+	if format == FormatNDJSON {
+		return readLines(r, func(line int, data []byte) error {
+			var record fareRecord
+			err := json.Unmarshal(data, &record)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+
+			return consume(line, &tables.Fare{ID: record.ID, TravelID: record.TravelID, From: record.From, To: record.To,
+				ValidFrom: record.ValidFrom, ValidTo: record.ValidTo, DaysOfWeek: record.DaysOfWeek,
+				Amount: record.Amount, Currency: strings.ToUpper(record.Currency)})
+		})
+	}
+
+	return readCSV(r, faresHeader, func(line int, values map[string]string) error {
+		amount, err := strconv.ParseFloat(values["amount"], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid amount %q", line, values["amount"])
+		}
+
+		return consume(line, &tables.Fare{ID: values["id"], TravelID: values["travel_id"], From: values["from"], To: values["to"],
+			ValidFrom: values["valid_from"], ValidTo: values["valid_to"], DaysOfWeek: values["days_of_week"],
+			Amount: amount, Currency: strings.ToUpper(values["currency"])})
+	})
+}
@@ -0,0 +1,31 @@
+package dataset
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format of the dataset files
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// FormatOf returns the given format or the one of the file extension (.csv, .ndjson or .jsonl)
+func FormatOf(path string, format string) (Format, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	switch format {
+	case "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	}
+
+	return "", fmt.Errorf("unknown format of %s, give csv or ndjson", path)
+}
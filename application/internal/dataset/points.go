@@ -0,0 +1,91 @@
+package dataset
+
+import (
+	"bufio"
+	"darbelis.eu/persedimai/internal/tables"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var pointsHeader = []string{"id", "name", "x", "y"}
+
+type pointRecord struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// PointsEncoder writes the points one by one
+type PointsEncoder struct {
+	csv    *csv.Writer
+	json   *json.Encoder
+	buffer *bufio.Writer
+}
+
+func NewPointsEncoder(w io.Writer, format Format) (*PointsEncoder, error) {
+	encoder := &PointsEncoder{buffer: bufio.NewWriter(w)}
+	if format == FormatNDJSON {
+		encoder.json = json.NewEncoder(encoder.buffer)
+		return encoder, nil
+	}
+
+	encoder.csv = csv.NewWriter(encoder.buffer)
+	return encoder, encoder.csv.Write(pointsHeader)
+}
+
+func (e *PointsEncoder) Encode(point *tables.Point) error {
+	if e.json != nil {
+		return e.json.Encode(pointRecord{ID: point.ID, Name: point.Name, X: point.X, Y: point.Y})
+	}
+
+	return e.csv.Write([]string{point.ID, point.Name, formatFloat(point.X), formatFloat(point.Y)})
+}
+
+// Flush writes the buffered points
+func (e *PointsEncoder) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	return e.buffer.Flush()
+}
+
+// ReadPoints passes the points of the reader with their line numbers to the consumer,
+// a malformed line or the consumer error stops the reading
+func ReadPoints(r io.Reader, format Format, consume func(line int, point *tables.Point) error) error {
+	if format == FormatNDJSON {
+		return readLines(r, func(line int, data []byte) error {
+			var record pointRecord
+			err := json.Unmarshal(data, &record)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+
+			return consume(line, &tables.Point{ID: record.ID, Name: record.Name, X: record.X, Y: record.Y})
+		})
+	}
+
+	return readCSV(r, pointsHeader, func(line int, values map[string]string) error {
+		x, err := strconv.ParseFloat(values["x"], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid x %q", line, values["x"])
+		}
+		y, err := strconv.ParseFloat(values["y"], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid y %q", line, values["y"])
+		}
+
+		return consume(line, &tables.Point{ID: values["id"], Name: values["name"], X: x, Y: y})
+	})
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLineLength of the NDJSON lines
+const maxLineLength = 1024 * 1024
+
+// readLines passes the not empty lines to the parser
+func readLines(r io.Reader, parse func(line int, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		err := parse(line, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readCSV passes the rows by the header names to the parser, all the required columns must be in the header
+func readCSV(r io.Reader, required []string, parse func(line int, values map[string]string) error) error {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("header: %w", err)
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+	}
+	for _, column := range required {
+		found := false
+		for _, name := range header {
+			found = found || name == column
+		}
+		if !found {
+			return fmt.Errorf("header: column %s is missing", column)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		// a malformed row has no field positions, its line is taken from the parse error
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			return fmt.Errorf("line %d: %w", parseError.StartLine, err)
+		} else if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+
+		values := make(map[string]string, len(header))
+		for i, column := range header {
+			values[column] = strings.TrimSpace(row[i])
+		}
+		err = parse(line, values)
+		if err != nil {
+			return err
+		}
+	}
+}
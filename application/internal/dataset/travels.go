@@ -0,0 +1,110 @@
+package dataset
+
+import (
+	"bufio"
+	"darbelis.eu/persedimai/internal/tables"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+var travelsHeader = []string{"id", "from", "to", "departure", "arrival"}
+
+// travelDetailsHeader are the optional columns of the service details
+var travelDetailsHeader = []string{"airline", "operator", "service_number", "mode", "vehicle_type", "terminal"}
+
+// travelRecord times are written in RFC 3339 UTC
+type travelRecord struct {
+	ID            string    `json:"id"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Departure     time.Time `json:"departure"`
+	Arrival       time.Time `json:"arrival"`
+	Airline       string    `json:"airline,omitempty"`
+	Operator      string    `json:"operator,omitempty"`
+	ServiceNumber string    `json:"service_number,omitempty"`
+	Mode          string    `json:"mode,omitempty"`
+	VehicleType   string    `json:"vehicle_type,omitempty"`
+	Terminal      string    `json:"terminal,omitempty"`
+}
+
+// TravelsEncoder writes the travels one by one
+type TravelsEncoder struct {
+	csv    *csv.Writer
+	json   *json.Encoder
+	buffer *bufio.Writer
+}
+
+func NewTravelsEncoder(w io.Writer, format Format) (*TravelsEncoder, error) {
+	encoder := &TravelsEncoder{buffer: bufio.NewWriter(w)}
+	if format == FormatNDJSON {
+		encoder.json = json.NewEncoder(encoder.buffer)
+		return encoder, nil
+	}
+
+	encoder.csv = csv.NewWriter(encoder.buffer)
+	return encoder, encoder.csv.Write(slices.Concat(travelsHeader, travelDetailsHeader))
+}
+
+func (e *TravelsEncoder) Encode(travel *tables.Transfer) error {
+	if e.json != nil {
+		return e.json.Encode(travelRecord{ID: travel.ID, From: travel.From, To: travel.To,
+			Departure: travel.Departure.UTC(), Arrival: travel.Arrival.UTC(),
+			Airline: travel.Airline, Operator: travel.Operator, ServiceNumber: travel.ServiceNumber,
+			Mode: travel.Mode, VehicleType: travel.VehicleType, Terminal: travel.Terminal})
+	}
+
+	return e.csv.Write([]string{travel.ID, travel.From, travel.To,
+		travel.Departure.UTC().Format(time.RFC3339), travel.Arrival.UTC().Format(time.RFC3339),
+		travel.Airline, travel.Operator, travel.ServiceNumber, travel.Mode, travel.VehicleType, travel.Terminal})
+}
+
+// Flush writes the buffered travels
+func (e *TravelsEncoder) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	return e.buffer.Flush()
+}
+
+// ReadTravels passes the travels of the reader with their line numbers to the consumer,
+// a malformed line or the consumer error stops the reading. The service details columns are optional.
+func ReadTravels(r io.Reader, format Format, consume func(line int, travel *tables.Transfer) error) error {
+	if format == FormatNDJSON {
+		return readLines(r, func(line int, data []byte) error {
+			var record travelRecord
+			err := json.Unmarshal(data, &record)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+
+			return consume(line, &tables.Transfer{ID: record.ID, From: record.From, To: record.To,
+				Departure: record.Departure.UTC(), Arrival: record.Arrival.UTC(),
+				Airline: record.Airline, Operator: record.Operator, ServiceNumber: record.ServiceNumber,
+				Mode: record.Mode, VehicleType: record.VehicleType, Terminal: record.Terminal})
+		})
+	}
+
+	return readCSV(r, travelsHeader, func(line int, values map[string]string) error {
+		departure, err := time.Parse(time.RFC3339, values["departure"])
+		if err != nil {
+			return fmt.Errorf("line %d: invalid departure %q", line, values["departure"])
+		}
+		arrival, err := time.Parse(time.RFC3339, values["arrival"])
+		if err != nil {
+			return fmt.Errorf("line %d: invalid arrival %q", line, values["arrival"])
+		}
+
+		return consume(line, &tables.Transfer{ID: values["id"], From: values["from"], To: values["to"],
+			Departure: departure.UTC(), Arrival: arrival.UTC(),
+			Airline: values["airline"], Operator: values["operator"], ServiceNumber: values["service_number"],
+			Mode: values["mode"], VehicleType: values["vehicle_type"], Terminal: values["terminal"]})
+	})
+}
@@ -0,0 +1,137 @@
+package dataset
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ValidationError of a row of the dataset
+type ValidationError struct {
+	Line   int
+	ID     string
+	Reason string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("line %d, id %q: %s", e.Line, e.ID, e.Reason)
+}
+
+// Validator checks the imported rows: points, travels and fares without ids or with repeated ids,
+// travels of unknown points and travels arriving before the departure, fares of neither a travel nor a route
+// of known points and fares with invalid amounts, currencies or dates
+type Validator struct {
+	// KnownPoints are the points of the database and the valid imported ones
+	KnownPoints map[string]bool
+	travels     map[string]bool
+	points      map[string]bool
+	fares       map[string]bool
+	Errors      []ValidationError
+}
+
+func NewValidator(knownPointIds []string) *Validator {
+	validator := &Validator{KnownPoints: make(map[string]bool), travels: make(map[string]bool), points: make(map[string]bool),
+		fares: make(map[string]bool)}
+	for _, id := range knownPointIds {
+		validator.KnownPoints[id] = true
+	}
+
+	return validator
+}
+
+// ValidatePoint records the error of the invalid point and returns false, the valid one becomes known
+func (v *Validator) ValidatePoint(line int, point *tables.Point) bool {
+	switch {
+	case point.ID == "":
+		return v.fail(line, point.ID, "empty id")
+	case len(point.ID) > 64:
+		return v.fail(line, point.ID, "id longer than 64")
+	case v.points[point.ID]:
+		return v.fail(line, point.ID, "duplicate id")
+	}
+
+	v.points[point.ID] = true
+	v.KnownPoints[point.ID] = true
+
+	return true
+}
+
+// ValidateTravel records the error of the invalid travel and returns false
+func (v *Validator) ValidateTravel(line int, travel *tables.Transfer) bool {
+	switch {
+	case travel.ID == "":
+		return v.fail(line, travel.ID, "empty id")
+	case len(travel.ID) > 64:
+		return v.fail(line, travel.ID, "id longer than 64")
+	case v.travels[travel.ID]:
+		return v.fail(line, travel.ID, "duplicate id")
+	case !v.KnownPoints[travel.From]:
+		return v.fail(line, travel.ID, "unknown point "+travel.From)
+	case !v.KnownPoints[travel.To]:
+		return v.fail(line, travel.ID, "unknown point "+travel.To)
+	case travel.Arrival.Before(travel.Departure):
+		return v.fail(line, travel.ID, "arrival before departure")
+	}
+
+	v.travels[travel.ID] = true
+
+	return true
+}
+
+// ValidateFare records the error of the invalid fare and returns false
+func (v *Validator) ValidateFare(line int, fare *tables.Fare) bool {
+	route := fare.From != "" || fare.To != ""
+	switch {
+	case fare.ID == "":
+		return v.fail(line, fare.ID, "empty id")
+	case len(fare.ID) > 64:
+		return v.fail(line, fare.ID, "id longer than 64")
+	case v.fares[fare.ID]:
+		return v.fail(line, fare.ID, "duplicate id")
+	case fare.TravelID == "" && !route:
+		return v.fail(line, fare.ID, "neither travel_id nor from and to")
+	case fare.TravelID != "" && route:
+		return v.fail(line, fare.ID, "both travel_id and from and to")
+	case route && !v.KnownPoints[fare.From]:
+		return v.fail(line, fare.ID, "unknown point "+fare.From)
+	case route && !v.KnownPoints[fare.To]:
+		return v.fail(line, fare.ID, "unknown point "+fare.To)
+	case fare.Amount < 0:
+		return v.fail(line, fare.ID, "negative amount")
+	case !currencyRegexp.MatchString(fare.Currency):
+		return v.fail(line, fare.ID, "invalid currency "+fare.Currency)
+	case !isDateOrEmpty(fare.ValidFrom):
+		return v.fail(line, fare.ID, "invalid valid_from "+fare.ValidFrom)
+	case !isDateOrEmpty(fare.ValidTo):
+		return v.fail(line, fare.ID, "invalid valid_to "+fare.ValidTo)
+	case fare.ValidFrom != "" && fare.ValidTo != "" && fare.ValidTo < fare.ValidFrom:
+		return v.fail(line, fare.ID, "valid_to before valid_from")
+	case !daysOfWeekRegexp.MatchString(fare.DaysOfWeek):
+		return v.fail(line, fare.ID, "invalid days_of_week "+fare.DaysOfWeek)
+	}
+
+	v.fares[fare.ID] = true
+
+	return true
+}
+
+var currencyRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// daysOfWeekRegexp matches the ISO weekdays like 135, empty for every day
+var daysOfWeekRegexp = regexp.MustCompile(`^[1-7]{0,7}$`)
+
+func isDateOrEmpty(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse(time.DateOnly, value)
+
+	return err == nil
+}
+
+func (v *Validator) fail(line int, id string, reason string) bool {
+	v.Errors = append(v.Errors, ValidationError{Line: line, ID: id, Reason: reason})
+
+	return false
+}
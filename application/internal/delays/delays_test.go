@@ -0,0 +1,139 @@
+package delays
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+func TestNewObservation(t *testing.T) {
+	t.Run("arrival delay is preferred", func(t *testing.T) {
+		o, ok := NewObservation("bt344", "VNO", "RIX", "2026-03-01", "landed", "20", "12")
+		if !ok || o.Delay != 12 || o.FlightIataNumber != "BT344" {
+			t.Errorf("Expected BT344 delayed by 12, got %+v %v", o, ok)
+		}
+	})
+
+	t.Run("departure delay and early arrivals", func(t *testing.T) {
+		o, ok := NewObservation("BT344", "VNO", "RIX", "2026-03-01", "landed", "-5", "")
+		if !ok || o.Delay != 0 {
+			t.Errorf("Expected the early flight not delayed, got %+v %v", o, ok)
+		}
+	})
+
+	t.Run("cancelled and unknown", func(t *testing.T) {
+		o, ok := NewObservation("BT344", "VNO", "RIX", "2026-03-01", "cancelled", "", "")
+		if !ok || !o.Cancelled {
+			t.Errorf("Expected cancelled observation, got %+v %v", o, ok)
+		}
+		_, ok = NewObservation("BT344", "VNO", "RIX", "2026-03-01", "scheduled", "", "")
+		if ok {
+			t.Error("Expected no observation without delay")
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	schedules := []*Observation{{FlightIataNumber: "BT344", DepIataCode: "VNO", ArrIataCode: "RIX", Date: "2026-03-01", Delay: 30}}
+	snapshots := []*Observation{
+		{FlightIataNumber: "BT344", DepIataCode: "VNO", ArrIataCode: "RIX", Date: "2026-03-01", Delay: 25},
+		{FlightIataNumber: "BT344", DepIataCode: "VNO", ArrIataCode: "RIX", Date: "2026-03-02", Delay: 5},
+	}
+
+	merged := Merge(schedules, snapshots)
+	if len(merged) != 2 || merged[0].Delay != 30 || merged[1].Delay != 5 {
+		t.Errorf("Expected the schedule delay 30 and the snapshot of the other day 5, got %+v %+v", merged[0], merged[len(merged)-1])
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	var observations []*Observation
+	for i, delay := range []int{0, 5, 10, 15, 20, 25, 30, 35, 40, 100} {
+		observations = append(observations, &Observation{FlightIataNumber: "BT344", DepIataCode: "VNO", ArrIataCode: "RIX",
+			Date: time.Date(2026, 3, i+1, 0, 0, 0, 0, time.UTC).Format(time.DateOnly), Delay: delay})
+	}
+	observations = append(observations,
+		&Observation{FlightIataNumber: "BT344", DepIataCode: "VNO", ArrIataCode: "RIX", Date: "2026-03-11", Cancelled: true},
+		&Observation{FlightIataNumber: "LO772", DepIataCode: "VNO", ArrIataCode: "WAW", Date: "2026-03-01", Delay: 60},
+	)
+
+	stats := Aggregate(observations, 2)
+	scopes := make(map[string]*tables.DelayStat)
+	for _, stat := range stats {
+		scopes[stat.Scope+" "+stat.FlightIataNumber+" "+stat.ArrIataCode] = stat
+	}
+	if len(stats) != 3 {
+		t.Fatalf("Expected flight, route and airport stats of 2 or more flights, got %d", len(stats))
+	}
+
+	flight := scopes["flight BT344 RIX"]
+	if flight == nil || flight.Flights != 11 || flight.Cancelled != 1 || flight.MeanDelay != 28 {
+		t.Fatalf("Expected 11 flights of BT344, 1 cancelled, mean 28, got %+v", flight)
+	}
+	if flight.P50Delay != 20 || flight.P75Delay != 35 || flight.P90Delay != 40 || flight.P95Delay != 100 {
+		t.Errorf("Expected percentiles 20, 35, 40, 100, got %d, %d, %d, %d", flight.P50Delay, flight.P75Delay, flight.P90Delay, flight.P95Delay)
+	}
+	if flight.CancellationRate != 1.0/11 || flight.PeriodFrom != "2026-03-01" || flight.PeriodTo != "2026-03-11" {
+		t.Errorf("Expected cancellation rate 1/11 of 2026-03-01 - 2026-03-11, got %f of %s - %s", flight.CancellationRate, flight.PeriodFrom, flight.PeriodTo)
+	}
+
+	airport := scopes["airport  "]
+	if airport == nil || airport.Flights != 12 || airport.DepIataCode != "VNO" {
+		t.Errorf("Expected 12 departures of VNO, got %+v", airport)
+	}
+}
+
+func TestSnapshotsOf(t *testing.T) {
+	flights := []aviation_edge.FlightTrackerResponse{
+		{
+			Departure: aviation_edge.Departure{IataCode: "vno", Delay: aviation_edge.DelayValue{Value: "12"}},
+			Arrival:   aviation_edge.Arrival{IataCode: "RIX"},
+			Flight:    aviation_edge.Flight{IataNumber: "bt344"},
+			Speed:     aviation_edge.Speed{IsGround: 1},
+			System:    aviation_edge.System{Updated: 1772362800},
+			Status:    "en-route",
+		},
+		{Flight: aviation_edge.Flight{IataNumber: "BT345"}},
+	}
+
+	snapshots := SnapshotsOf(flights, time.Date(2026, 3, 1, 11, 0, 5, 0, time.UTC))
+	if len(snapshots) != 1 {
+		t.Fatalf("Expected the flight without airports skipped, got %d snapshots", len(snapshots))
+	}
+	s := snapshots[0]
+	if s.FlightIataNumber != "BT344" || s.DepIataCode != "VNO" || s.DepDelay != "12" || !s.IsGround {
+		t.Errorf("Expected BT344 from VNO delayed by 12 on ground, got %+v", s)
+	}
+	if s.UpdatedAt != "2026-03-01 11:00:00" || s.PolledAt != "2026-03-01 11:00:05" {
+		t.Errorf("Expected updated 2026-03-01 11:00:00 and polled 11:00:05, got %s and %s", s.UpdatedAt, s.PolledAt)
+	}
+}
+
+func TestObservationsOfSnapshots(t *testing.T) {
+	snapshot := func(flight, status, arrDelay, polledAt string) *tables.FlightStatusSnapshot {
+		return &tables.FlightStatusSnapshot{FlightIataNumber: flight, DepIataCode: "VNO", ArrIataCode: "RIX", Status: status, ArrDelay: arrDelay, PolledAt: polledAt}
+	}
+	scheduled := snapshot("BT346", "landed", "5", "2026-03-02 01:10:00")
+	scheduled.DepScheduledTime = "2026-03-01 23:55:00"
+
+	observations := ObservationsOfSnapshots([]*tables.FlightStatusSnapshot{
+		// crossing midnight, landed after a partial delay
+		snapshot("BT344", "en-route", "3", "2026-03-01 23:50:00"),
+		snapshot("BT344", "landed", "25", "2026-03-02 00:40:00"),
+		snapshot("BT344", "landed", "25", "2026-03-02 00:50:00"),
+		// the next day, last seen en-route
+		snapshot("BT344", "en-route", "10", "2026-03-02 23:50:00"),
+		scheduled,
+	})
+
+	if len(observations) != 2 {
+		t.Fatalf("Expected 2 observations, got %d", len(observations))
+	}
+	if observations[0].Date != "2026-03-01" || observations[0].Delay != 25 {
+		t.Errorf("Expected BT344 of 2026-03-01 landed 25 minutes late, got %+v", observations[0])
+	}
+	if observations[1].FlightIataNumber != "BT346" || observations[1].Date != "2026-03-01" {
+		t.Errorf("Expected BT346 of its scheduled date 2026-03-01, got %+v", observations[1])
+	}
+}
@@ -0,0 +1,155 @@
+package delays
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/tables"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Observation is the outcome of a flight on a departure date: its delay in minutes or the cancellation
+type Observation struct {
+	FlightIataNumber string
+	DepIataCode      string
+	ArrIataCode      string
+	// Date of the departure YYYY-MM-DD
+	Date      string
+	Delay     int
+	Cancelled bool
+}
+
+// NewObservation chooses the arrival delay, which decides the connections, or the departure one.
+// Flights neither cancelled nor of a known delay are not observations.
+func NewObservation(flight, dep, arr, date, status, depDelay, arrDelay string) (*Observation, bool) {
+	observation := &Observation{FlightIataNumber: strings.ToUpper(flight), DepIataCode: dep, ArrIataCode: arr, Date: date}
+	if status == "cancelled" {
+		observation.Cancelled = true
+		return observation, true
+	}
+
+	delay, ok := ParseDelay(arrDelay)
+	if !ok {
+		delay, ok = ParseDelay(depDelay)
+	}
+	observation.Delay = delay
+
+	return observation, ok
+}
+
+// ParseDelay parses the delay minutes of the API, the early flights are not delayed
+func ParseDelay(value string) (int, bool) {
+	delay, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+
+	return max(delay, 0), true
+}
+
+// Merge joins the observations of the collected schedules and of the tracker snapshots,
+// the schedules win for the same flight and date
+func Merge(schedules, snapshots []*Observation) []*Observation {
+	key := func(o *Observation) string {
+		return o.FlightIataNumber + "|" + o.DepIataCode + "|" + o.ArrIataCode + "|" + o.Date
+	}
+
+	merged := append([]*Observation{}, schedules...)
+	seen := make(map[string]bool, len(schedules))
+	for _, observation := range schedules {
+		seen[key(observation)] = true
+	}
+	for _, observation := range snapshots {
+		if !seen[key(observation)] {
+			merged = append(merged, observation)
+			seen[key(observation)] = true
+		}
+	}
+
+	return merged
+}
+
+// flightGap separates the snapshots of the flights of the same number and route on different days
+const flightGap = 12 * time.Hour
+
+// ObservationsOfSnapshots takes the outcomes of the flights of the snapshots ordered by the flight, the route
+// and the poll time. The snapshots of a flight and route less than flightGap apart are of one flight, its outcome
+// is its last landed or cancelled snapshot. The flights not seen landed or cancelled are skipped as their delay is
+// not final. The date of a flight is its scheduled departure date, else the date of its first snapshot, so the
+// flights crossing midnight are observed once on the date of their schedule.
+func ObservationsOfSnapshots(snapshots []*tables.FlightStatusSnapshot) []*Observation {
+	var observations []*Observation
+	var first, outcome *tables.FlightStatusSnapshot
+	var previousPoll time.Time
+
+	flush := func() {
+		if outcome == nil {
+			return
+		}
+		date := first.PolledAt
+		if outcome.DepScheduledTime != "" {
+			date = outcome.DepScheduledTime
+		}
+		observation, ok := NewObservation(outcome.FlightIataNumber, outcome.DepIataCode, outcome.ArrIataCode,
+			date[:len(time.DateOnly)], outcome.Status, outcome.DepDelay, outcome.ArrDelay)
+		if ok {
+			observations = append(observations, observation)
+		}
+	}
+
+	for _, snapshot := range snapshots {
+		polledAt, err := time.Parse(time.DateTime, snapshot.PolledAt)
+		if err != nil {
+			continue
+		}
+		sameFlight := first != nil && snapshot.FlightIataNumber == first.FlightIataNumber &&
+			snapshot.DepIataCode == first.DepIataCode && snapshot.ArrIataCode == first.ArrIataCode &&
+			polledAt.Sub(previousPoll) < flightGap
+		if !sameFlight {
+			flush()
+			first, outcome = snapshot, nil
+		}
+		previousPoll = polledAt
+
+		if snapshot.Status == "landed" || snapshot.Status == "cancelled" {
+			outcome = snapshot
+		}
+	}
+	flush()
+
+	return observations
+}
+
+// SnapshotsOf converts the flight tracker responses of a poll, flights without number or airports are skipped
+func SnapshotsOf(flights []aviation_edge.FlightTrackerResponse, polledAt time.Time) []*tables.FlightStatusSnapshot {
+	var snapshots []*tables.FlightStatusSnapshot
+	for _, flight := range flights {
+		if flight.Flight.IataNumber == "" || flight.Departure.IataCode == "" || flight.Arrival.IataCode == "" {
+			continue
+		}
+
+		snapshot := &tables.FlightStatusSnapshot{
+			FlightIataNumber: strings.ToUpper(flight.Flight.IataNumber),
+			AirlineIataCode:  strings.ToUpper(flight.Airline.IataCode),
+			DepIataCode:      strings.ToUpper(flight.Departure.IataCode),
+			ArrIataCode:      strings.ToUpper(flight.Arrival.IataCode),
+			Status:           flight.Status,
+			DepDelay:         flight.Departure.Delay.Value,
+			ArrDelay:         flight.Arrival.Delay.Value,
+			Latitude:         flight.Geography.Latitude,
+			Longitude:        flight.Geography.Longitude,
+			Altitude:         flight.Geography.Altitude,
+			IsGround:         flight.Speed.IsGround != 0,
+			PolledAt:         polledAt.UTC().Format(time.DateTime),
+		}
+		if scheduled, err := time.Parse(time.RFC3339Nano, flight.Departure.ScheduledTime); err == nil {
+			snapshot.DepScheduledTime = scheduled.UTC().Format(time.DateTime)
+		}
+		if flight.System.Updated > 0 {
+			snapshot.UpdatedAt = time.Unix(flight.System.Updated, 0).UTC().Format(time.DateTime)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
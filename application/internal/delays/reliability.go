@@ -0,0 +1,125 @@
+package delays
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"time"
+)
+
+// StatsFinder finds the delay stats, nil when there are none. It is implemented by dao.DelayStatsDao.
+type StatsFinder interface {
+	FindFlight(flightIataNumber, depIataCode, arrIataCode string) (*tables.DelayStat, error)
+	FindRoute(depIataCode, arrIataCode string) (*tables.DelayStat, error)
+	FindAirport(depIataCode string) (*tables.DelayStat, error)
+}
+
+// Reliability is the probability that every connection of a path is made
+type Reliability struct {
+	Probability float64
+	Connections int
+	// RatedConnections have the delay stats of the arriving travel, the others are taken as always made
+	RatedConnections int
+	// ConnectionProbabilities of the connections after each travel but the last
+	ConnectionProbabilities []float64
+}
+
+// ReliabilityScorer rates the connections of the paths by the delay distributions of the arriving flights,
+// of the flight itself, else of its route, else of its departure airport
+type ReliabilityScorer struct {
+	finder StatsFinder
+	// MinConnection is the time needed to change, the arriving travel may be late by the rest of the connection
+	MinConnection time.Duration
+	stats         map[string]*tables.DelayStat
+}
+
+func NewReliabilityScorer(finder StatsFinder, minConnection time.Duration) *ReliabilityScorer {
+	return &ReliabilityScorer{finder: finder, MinConnection: minConnection, stats: make(map[string]*tables.DelayStat)}
+}
+
+// Score computes the probability of making all the connections, taking the delays of the travels as independent.
+// A connection is missed when the arriving travel is cancelled or late by more than the connection time
+// less MinConnection. The departing travel is taken as on time.
+func (s *ReliabilityScorer) Score(sequence *tables.TransferSequence) (*Reliability, error) {
+	reliability := &Reliability{Probability: 1}
+	for i := 0; i < sequence.Count()-1; i++ {
+		reliability.Connections++
+
+		stat, err := s.findStat(sequence.Transfers[i])
+		if err != nil {
+			return nil, err
+		}
+
+		probability := 1.0
+		if stat != nil {
+			reliability.RatedConnections++
+			slack := sequence.ConnectionTime(i) - s.MinConnection
+			probability = (1 - stat.CancellationRate) * OnTimeProbability(stat, slack)
+		}
+		reliability.ConnectionProbabilities = append(reliability.ConnectionProbabilities, probability)
+		reliability.Probability *= probability
+	}
+
+	return reliability, nil
+}
+
+func (s *ReliabilityScorer) findStat(travel *tables.Transfer) (*tables.DelayStat, error) {
+	flight, _, _, _, _ := tables.ParseFlightTravelID(travel.ID)
+
+	key := flight + "|" + travel.From + "|" + travel.To
+	if stat, ok := s.stats[key]; ok {
+		return stat, nil
+	}
+
+	var stat *tables.DelayStat
+	var err error
+	if flight != "" {
+		stat, err = s.finder.FindFlight(flight, travel.From, travel.To)
+	}
+	if err == nil && stat == nil {
+		stat, err = s.finder.FindRoute(travel.From, travel.To)
+	}
+	if err == nil && stat == nil {
+		stat, err = s.finder.FindAirport(travel.From)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.stats[key] = stat
+
+	return stat, nil
+}
+
+// OnTimeProbability is the probability of the delay not exceeding the slack. The distribution is interpolated
+// linearly between no delay and the percentiles of the stat and reaches 1 at twice the 95th percentile
+// (at least an hour after it).
+func OnTimeProbability(stat *tables.DelayStat, slack time.Duration) float64 {
+	minutes := slack.Minutes()
+	if minutes < 0 {
+		return 0
+	}
+
+	p95 := float64(stat.P95Delay)
+	knots := []struct{ delay, probability float64 }{
+		{0, 0},
+		{float64(stat.P50Delay), 0.5},
+		{float64(stat.P75Delay), 0.75},
+		{float64(stat.P90Delay), 0.9},
+		{p95, 0.95},
+		{max(2*p95, p95+60), 1},
+	}
+
+	probability := 0.0
+	for i := 1; i < len(knots); i++ {
+		a, b := knots[i-1], knots[i]
+		if minutes >= b.delay {
+			probability = b.probability
+			continue
+		}
+		if minutes > a.delay {
+			probability = a.probability + (b.probability-a.probability)*(minutes-a.delay)/(b.delay-a.delay)
+		}
+		break
+	}
+
+	return probability
+}
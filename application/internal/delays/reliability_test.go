@@ -0,0 +1,105 @@
+package delays
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeStatsFinder struct {
+	flights  map[string]*tables.DelayStat
+	routes   map[string]*tables.DelayStat
+	airports map[string]*tables.DelayStat
+	calls    int
+}
+
+func (f *fakeStatsFinder) FindFlight(flight, dep, arr string) (*tables.DelayStat, error) {
+	f.calls++
+	return f.flights[flight+dep+arr], nil
+}
+
+func (f *fakeStatsFinder) FindRoute(dep, arr string) (*tables.DelayStat, error) {
+	return f.routes[dep+arr], nil
+}
+
+func (f *fakeStatsFinder) FindAirport(dep string) (*tables.DelayStat, error) {
+	return f.airports[dep], nil
+}
+
+func TestOnTimeProbability(t *testing.T) {
+	stat := &tables.DelayStat{P50Delay: 10, P75Delay: 20, P90Delay: 40, P95Delay: 60}
+
+	cases := []struct {
+		slack    time.Duration
+		expected float64
+	}{
+		{-time.Minute, 0},
+		{0, 0},
+		{5 * time.Minute, 0.25},
+		{10 * time.Minute, 0.5},
+		{30 * time.Minute, 0.825},
+		{60 * time.Minute, 0.95},
+		{90 * time.Minute, 0.975},
+		{3 * time.Hour, 1},
+	}
+	for _, c := range cases {
+		probability := OnTimeProbability(stat, c.slack)
+		if math.Abs(probability-c.expected) > 1e-9 {
+			t.Errorf("Expected %f for slack %s, got %f", c.expected, c.slack, probability)
+		}
+	}
+
+	t.Run("mostly on time", func(t *testing.T) {
+		onTime := &tables.DelayStat{P50Delay: 0, P75Delay: 0, P90Delay: 5, P95Delay: 15}
+		if OnTimeProbability(onTime, 0) != 0.75 {
+			t.Errorf("Expected 0.75 for no slack, got %f", OnTimeProbability(onTime, 0))
+		}
+	})
+}
+
+func TestReliabilityScorer_Score(t *testing.T) {
+	departure := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	travel := func(id, from, to string, departureAfter, arrivalAfter time.Duration) *tables.Transfer {
+		return &tables.Transfer{ID: id, From: from, To: to, Departure: departure.Add(departureAfter), Arrival: departure.Add(arrivalAfter)}
+	}
+	finder := &fakeStatsFinder{
+		flights: map[string]*tables.DelayStat{
+			"BT344VNORIX": {P50Delay: 10, P75Delay: 20, P90Delay: 40, P95Delay: 60, CancellationRate: 0.1},
+		},
+		routes:   map[string]*tables.DelayStat{"RIXAMS": {P50Delay: 0, P75Delay: 0, P90Delay: 0, P95Delay: 0}},
+		airports: map[string]*tables.DelayStat{},
+	}
+	scorer := NewReliabilityScorer(finder, 30*time.Minute)
+
+	sequence := tables.NewTransferSequence([]*tables.Transfer{
+		travel("BT344_202603011000_VNORIX", "VNO", "RIX", 0, time.Hour),
+		travel("KL1234_202603011200_RIXAMS", "RIX", "AMS", 2*time.Hour, 4*time.Hour),
+		travel("LTG_T1_20260301_1", "AMS", "BRU", 5*time.Hour, 7*time.Hour),
+		travel("7", "BRU", "PAR", 8*time.Hour, 9*time.Hour),
+	})
+
+	reliability, err := scorer.Score(sequence)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if reliability.Connections != 3 || reliability.RatedConnections != 2 {
+		t.Errorf("Expected 2 of 3 connections rated, got %d of %d", reliability.RatedConnections, reliability.Connections)
+	}
+	// the punctual route keeps the hour long tail after its 95th percentile
+	expected := []float64{0.9 * 0.825, 0.975, 1}
+	for i, probability := range reliability.ConnectionProbabilities {
+		if math.Abs(probability-expected[i]) > 1e-9 {
+			t.Errorf("Expected connection %d probability %f, got %f", i, expected[i], probability)
+		}
+	}
+	if math.Abs(reliability.Probability-0.9*0.825*0.975) > 1e-9 {
+		t.Errorf("Expected reliability %f, got %f", 0.9*0.825*0.975, reliability.Probability)
+	}
+
+	_, _ = scorer.Score(sequence)
+	if finder.calls != 2 {
+		t.Errorf("Expected the stats cached, got %d flight queries", finder.calls)
+	}
+}
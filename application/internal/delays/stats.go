@@ -0,0 +1,91 @@
+package delays
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"sort"
+)
+
+// Aggregate computes the delay distributions of the flights, routes and departure airports
+// observed at least minFlights times, ordered by the scope keys
+func Aggregate(observations []*Observation, minFlights int) []*tables.DelayStat {
+	groups := make(map[tables.DelayStat][]*Observation)
+	for _, o := range observations {
+		for _, key := range []tables.DelayStat{
+			{Scope: tables.DelayScopeFlight, FlightIataNumber: o.FlightIataNumber, DepIataCode: o.DepIataCode, ArrIataCode: o.ArrIataCode},
+			{Scope: tables.DelayScopeRoute, DepIataCode: o.DepIataCode, ArrIataCode: o.ArrIataCode},
+			{Scope: tables.DelayScopeAirport, DepIataCode: o.DepIataCode},
+		} {
+			groups[key] = append(groups[key], o)
+		}
+	}
+
+	var stats []*tables.DelayStat
+	for key, group := range groups {
+		if len(group) < minFlights {
+			continue
+		}
+		stat := key
+		computeStat(&stat, group)
+		stats = append(stats, &stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.DepIataCode != b.DepIataCode {
+			return a.DepIataCode < b.DepIataCode
+		}
+		if a.ArrIataCode != b.ArrIataCode {
+			return a.ArrIataCode < b.ArrIataCode
+		}
+		return a.FlightIataNumber < b.FlightIataNumber
+	})
+
+	return stats
+}
+
+func computeStat(stat *tables.DelayStat, observations []*Observation) {
+	var delays []int
+	total := 0
+	for _, o := range observations {
+		if stat.PeriodFrom == "" || o.Date < stat.PeriodFrom {
+			stat.PeriodFrom = o.Date
+		}
+		if o.Date > stat.PeriodTo {
+			stat.PeriodTo = o.Date
+		}
+		if o.Cancelled {
+			stat.Cancelled++
+			continue
+		}
+		delays = append(delays, o.Delay)
+		total += o.Delay
+	}
+	sort.Ints(delays)
+
+	stat.Flights = len(observations)
+	stat.CancellationRate = float64(stat.Cancelled) / float64(stat.Flights)
+	if len(delays) > 0 {
+		stat.MeanDelay = float64(total) / float64(len(delays))
+	}
+	stat.P50Delay = Percentile(delays, 50)
+	stat.P75Delay = Percentile(delays, 75)
+	stat.P90Delay = Percentile(delays, 90)
+	stat.P95Delay = Percentile(delays, 95)
+}
+
+// Percentile of the sorted values by the nearest rank, 0 of no values
+func Percentile(sorted []int, percent int) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (percent*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
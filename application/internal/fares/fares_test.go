@@ -0,0 +1,69 @@
+package fares
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+func TestIndex_FareOf(t *testing.T) {
+	index := NewIndex([]*tables.Fare{
+		{ID: "route", From: "VNO", To: "RIX", Amount: 80, Currency: "EUR"},
+		{ID: "march", From: "VNO", To: "RIX", ValidFrom: "2026-03-01", ValidTo: "2026-03-31", Amount: 60, Currency: "EUR"},
+		{ID: "march-weekend", From: "VNO", To: "RIX", ValidFrom: "2026-03-01", ValidTo: "2026-03-31", DaysOfWeek: "67", Amount: 90, Currency: "EUR"},
+		{ID: "travel", TravelID: "BT344_202603022330_VNORIX", Amount: 45.5, Currency: "EUR"},
+	})
+	transfer := func(id string, departure time.Time) *tables.Transfer {
+		return &tables.Transfer{ID: id, From: "VNO", To: "RIX", Departure: departure, Arrival: departure.Add(time.Hour)}
+	}
+
+	cases := []struct {
+		name     string
+		transfer *tables.Transfer
+		expected string
+	}{
+		{"fare of the travel", transfer("BT344_202603022330_VNORIX", time.Date(2026, 3, 2, 21, 30, 0, 0, time.UTC)), "travel"},
+		{"fare of the period", transfer("T1", time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)), "march"},
+		{"fare of the weekdays", transfer("T2", time.Date(2026, 3, 7, 8, 0, 0, 0, time.UTC)), "march-weekend"},
+		{"open fare", transfer("T3", time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)), "route"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fare := index.FareOf(c.transfer)
+			if fare == nil || fare.ID != c.expected {
+				t.Errorf("Expected fare %s, got %+v", c.expected, fare)
+			}
+		})
+	}
+
+	t.Run("other route", func(t *testing.T) {
+		other := &tables.Transfer{ID: "T4", From: "RIX", To: "VNO", Departure: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)}
+		if fare := index.FareOf(other); fare != nil {
+			t.Errorf("Expected no fare, got %+v", fare)
+		}
+	})
+}
+
+func TestIndex_Price(t *testing.T) {
+	index := NewIndex([]*tables.Fare{
+		{ID: "1", From: "VNO", To: "RIX", Amount: 49.99, Currency: "EUR"},
+		{ID: "2", From: "RIX", To: "LHR", Amount: 120.02, Currency: "EUR"},
+	})
+	departure := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+	price := index.Price([]*tables.Transfer{
+		{ID: "A", From: "VNO", To: "RIX", Departure: departure},
+		{ID: "B", From: "RIX", To: "LHR", Departure: departure.Add(3 * time.Hour)},
+	}, "EUR")
+	if !price.Complete() || price.Total != 170.01 || price.Legs[1].ID != "2" {
+		t.Errorf("Expected complete price 170.01 with the fare 2 of the second leg, got %+v", price)
+	}
+
+	price = index.Price([]*tables.Transfer{
+		{ID: "A", From: "VNO", To: "RIX", Departure: departure},
+		{ID: "C", From: "LHR", To: "JFK", Departure: departure.Add(3 * time.Hour)},
+	}, "EUR")
+	if price.Complete() || price.PricedLegs != 1 || price.Legs[1] != nil || price.Total != 49.99 {
+		t.Errorf("Expected 49.99 of 1 priced leg, got %+v", price)
+	}
+}
@@ -0,0 +1,96 @@
+package fares
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Finder finds the fares in the currency of the transfers and of the routes between their points.
+// It is implemented by dao.FaresDao.
+type Finder interface {
+	FindForTransfers(transfers []*tables.Transfer, currency string) ([]*tables.Fare, error)
+}
+
+// Index matches the transfers to their fares. The fare of the travel itself wins, else the most specific
+// route fare applying to the departure date: one of some weekdays before one of every day, then the one
+// of the shortest validity period, then the cheapest.
+type Index struct {
+	travels map[string]*tables.Fare
+	routes  map[string][]*tables.Fare
+}
+
+func NewIndex(fares []*tables.Fare) *Index {
+	index := &Index{travels: make(map[string]*tables.Fare), routes: make(map[string][]*tables.Fare)}
+	for _, fare := range fares {
+		if fare.TravelID != "" {
+			index.travels[fare.TravelID] = fare
+			continue
+		}
+		key := fare.From + "\x00" + fare.To
+		index.routes[key] = append(index.routes[key], fare)
+	}
+
+	return index
+}
+
+// FareOf returns the fare of the transfer, nil when there is none
+func (index *Index) FareOf(transfer *tables.Transfer) *tables.Fare {
+	if fare, ok := index.travels[transfer.ID]; ok {
+		return fare
+	}
+
+	var best *tables.Fare
+	for _, fare := range index.routes[transfer.From+"\x00"+transfer.To] {
+		if Applies(fare, transfer.Departure) && (best == nil || moreSpecific(fare, best)) {
+			best = fare
+		}
+	}
+
+	return best
+}
+
+// Applies tells whether the route fare applies to the departure, the dates are compared in UTC
+func Applies(fare *tables.Fare, departure time.Time) bool {
+	date := departure.UTC().Format(time.DateOnly)
+	if fare.ValidFrom != "" && date < fare.ValidFrom {
+		return false
+	}
+	if fare.ValidTo != "" && date > fare.ValidTo {
+		return false
+	}
+	if fare.DaysOfWeek == "" {
+		return true
+	}
+
+	weekday := int(departure.UTC().Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+
+	return strings.Contains(fare.DaysOfWeek, strconv.Itoa(weekday))
+}
+
+func moreSpecific(fare, other *tables.Fare) bool {
+	if (fare.DaysOfWeek != "") != (other.DaysOfWeek != "") {
+		return fare.DaysOfWeek != ""
+	}
+	if validity(fare) != validity(other) {
+		return validity(fare) < validity(other)
+	}
+
+	return fare.Amount < other.Amount
+}
+
+// validity is the length of the validity period, open periods are the longest
+func validity(fare *tables.Fare) time.Duration {
+	if fare.ValidFrom == "" || fare.ValidTo == "" {
+		return time.Duration(math.MaxInt64)
+	}
+	from, _ := time.Parse(time.DateOnly, fare.ValidFrom)
+	to, _ := time.Parse(time.DateOnly, fare.ValidTo)
+
+	return to.Sub(from)
+}
@@ -0,0 +1,39 @@
+package fares
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"math"
+)
+
+// PathPrice is the price of a path broken down by its legs
+type PathPrice struct {
+	// Legs are the fares of the transfers of the path, nil of the transfers without a fare
+	Legs     []*tables.Fare
+	Total    float64
+	Currency string
+	// PricedLegs have a fare, Total is the sum of their fares only
+	PricedLegs int
+}
+
+// Complete tells whether every leg has a fare
+func (p *PathPrice) Complete() bool {
+	return p.PricedLegs == len(p.Legs)
+}
+
+// Price sums the fares of the transfers
+func (index *Index) Price(transfers []*tables.Transfer, currency string) *PathPrice {
+	price := &PathPrice{Legs: make([]*tables.Fare, len(transfers)), Currency: currency}
+	for i, transfer := range transfers {
+		fare := index.FareOf(transfer)
+		if fare == nil {
+			continue
+		}
+		price.Legs[i] = fare
+		price.Total += fare.Amount
+		price.PricedLegs++
+	}
+	// the fares have cents, the sum of the floats is rounded back to them
+	price.Total = math.Round(price.Total*100) / 100
+
+	return price
+}
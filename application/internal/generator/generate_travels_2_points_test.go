@@ -32,6 +32,12 @@ func TestGenerateTravels2Points(t *testing.T) {
 	if len(expectedTravels) != len(actualTravels) {
 		t.Errorf("expectedTravels size: %d, actualTravels size: %d", len(expectedTravels), len(actualTravels))
 	}
+
+	for _, travel := range actualTravels {
+		if travel.ServiceNumber != "G1" || travel.Mode != tables.TransportModeFlight {
+			t.Errorf("Expected flight G1 both ways, got %s %s", travel.Mode, travel.ServiceNumber)
+		}
+	}
 }
 
 func GetTime(timeStr string) time.Time {
@@ -31,6 +31,8 @@ type Generator struct {
 	squareSize  float64
 	randFactor  float64
 	idGenerator IdGenerator
+	// shuttles counts the pairs of points travels were generated between, it numbers their services
+	shuttles int
 }
 
 // generateRandomName creates a random location name by combining an adjective and a noun
@@ -137,8 +139,13 @@ func (g *Generator) GenerateTravels(
 	return nil
 }
 
-// GenerateTravelsForTwoPoints generates multiple travels between two points
+// GenerateTravelsForTwoPoints generates multiple travels between two points,
+// the travels back and forth are one service of the mode given by the speed
 func (g *Generator) GenerateTravelsForTwoPoints(point1 tables.Point, point2 tables.Point, fromDate time.Time, toDate time.Time, speed float64, restHours int, travelConsumer TravelConsumerInterface) (int, error) {
+	g.shuttles++
+	serviceNumber := fmt.Sprintf("G%d", g.shuttles)
+	mode := modeOfSpeed(speed)
+
 	currentDeparture := fromDate
 	currentFrom := point1
 	currentTo := point2
@@ -156,6 +163,9 @@ func (g *Generator) GenerateTravelsForTwoPoints(point1 tables.Point, point2 tabl
 			break
 		}
 
+		travel.ServiceNumber = serviceNumber
+		travel.Mode = mode
+
 		err := travelConsumer.Consume(&travel)
 		if err != nil {
 			return count, err
@@ -202,6 +212,18 @@ func (g *Generator) GenerateSingleTravel(point1 tables.Point, point2 tables.Poin
 	return travel
 }
 
+// modeOfSpeed takes the generated coordinates as kilometres and the speed as kilometres per hour
+func modeOfSpeed(speed float64) string {
+	switch {
+	case speed >= 300:
+		return tables.TransportModeFlight
+	case speed >= 80:
+		return tables.TransportModeTrain
+	}
+
+	return tables.TransportModeBus
+}
+
 // applyRandomFactor applies random variation to a value based on randFactor
 // If randFactor is 0, returns the original value unchanged
 // Otherwise returns value * (1 + random variation), where variation is between -randFactor and +randFactor
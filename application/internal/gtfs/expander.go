@@ -0,0 +1,221 @@
+package gtfs
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// dateLayout of the dates of calendar.txt and calendar_dates.txt
+const dateLayout = "20060102"
+
+// maxIdLength of the points and travels ids
+const maxIdLength = 64
+
+// PointID is the id of the point of the stop. The ids are prefixed by the feed name,
+// so they do not collide with the airports IATA codes or the stops of other feeds.
+func PointID(prefix, stopID string) string {
+	return shortenID(prefix + ":" + stopID)
+}
+
+// TravelID is the id of the travel of the trip on the service day starting at the stop of the given sequence
+func TravelID(prefix, tripID, day string, sequence int) string {
+	return shortenID(fmt.Sprintf("%s_%s_%s_%d", prefix, tripID, day, sequence))
+}
+
+// shortenID replaces the end of the too long id by its hash
+func shortenID(id string) string {
+	if len(id) <= maxIdLength {
+		return id
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(id))
+	suffix := fmt.Sprintf("~%016x", hash.Sum64())
+
+	return id[:maxIdLength-len(suffix)] + suffix
+}
+
+// Points converts the stops used by the trips into points, longitude is x and latitude is y
+func (f *Feed) Points(prefix string) []*tables.Point {
+	used := make(map[string]bool)
+	for _, stopTimes := range f.StopTimes {
+		for _, stopTime := range stopTimes {
+			used[stopTime.StopID] = true
+		}
+	}
+
+	var points []*tables.Point
+	for _, stop := range f.Stops {
+		if !used[stop.ID] {
+			continue
+		}
+		points = append(points, &tables.Point{ID: PointID(prefix, stop.ID), X: stop.Longitude, Y: stop.Latitude, Name: stop.Name})
+	}
+
+	return points
+}
+
+// IsServiceDay tells whether the service runs on the day: the calendar weekdays within its dates
+// unless removed by calendar_dates.txt, or the days added by calendar_dates.txt
+func (f *Feed) IsServiceDay(serviceID string, day time.Time) bool {
+	date := day.Format(dateLayout)
+	for _, exception := range f.CalendarDates[serviceID] {
+		if exception.Date == date {
+			return exception.ExceptionType == 1
+		}
+	}
+
+	calendar := f.Calendars[serviceID]
+	if calendar == nil {
+		return false
+	}
+
+	return calendar.StartDate <= date && date <= calendar.EndDate && calendar.Weekdays[day.Weekday()]
+}
+
+// ExpandDay creates the transfers between the consecutive timed stops of the trips running on the day.
+// The stops without times are passed through. The times are converted from the feed timezone to UTC.
+func (f *Feed) ExpandDay(prefix string, day time.Time) []*tables.Transfer {
+	date := day.Format(dateLayout)
+	// the stop times are counted from the noon minus 12h, which differs from the midnight on the DST change days
+	base := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, f.Timezone).Add(-12 * time.Hour)
+
+	var transfers []*tables.Transfer
+	for _, trip := range f.Trips {
+		if !f.IsServiceDay(trip.ServiceID, day) {
+			continue
+		}
+
+		details := f.tripDetails(trip)
+		var previous *StopTime
+		for _, stopTime := range f.StopTimes[trip.ID] {
+			if !stopTime.HasTimes {
+				continue
+			}
+			if previous != nil && stopTime.ArrivalTime >= previous.DepartureTime {
+				terminal := ""
+				if stop, ok := f.Stops[previous.StopID]; ok {
+					terminal = stop.PlatformCode
+				}
+				transfers = append(transfers, &tables.Transfer{
+					ID:            TravelID(prefix, trip.ID, date, previous.Sequence),
+					From:          PointID(prefix, previous.StopID),
+					To:            PointID(prefix, stopTime.StopID),
+					Departure:     base.Add(time.Duration(previous.DepartureTime) * time.Second).UTC(),
+					Arrival:       base.Add(time.Duration(stopTime.ArrivalTime) * time.Second).UTC(),
+					Operator:      details.Operator,
+					ServiceNumber: details.ServiceNumber,
+					Mode:          details.Mode,
+					Terminal:      terminal,
+				})
+			}
+			previous = stopTime
+		}
+	}
+
+	return transfers
+}
+
+// ServiceDates returns the first and the last day of the calendars and the added calendar dates
+func (f *Feed) ServiceDates() (time.Time, time.Time, error) {
+	first, last := "", ""
+	extend := func(date string) {
+		if first == "" || date < first {
+			first = date
+		}
+		if last == "" || date > last {
+			last = date
+		}
+	}
+
+	for _, calendar := range f.Calendars {
+		extend(calendar.StartDate)
+		extend(calendar.EndDate)
+	}
+	for _, dates := range f.CalendarDates {
+		for _, date := range dates {
+			if date.ExceptionType == 1 {
+				extend(date.Date)
+			}
+		}
+	}
+
+	if first == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("the feed has no service dates")
+	}
+
+	from, err := time.Parse(dateLayout, first)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := time.Parse(dateLayout, last)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return from, to, nil
+}
+
+// tripDetails returns the operator, the service number and the mode of the trip transfers.
+// The service number is the trip short name (the train number) else the route short name (the bus line).
+func (f *Feed) tripDetails(trip *Trip) tables.Transfer {
+	details := tables.Transfer{Operator: f.AgencyID, ServiceNumber: trip.ShortName}
+	route, ok := f.Routes[trip.RouteID]
+	if !ok {
+		return details
+	}
+
+	if route.AgencyID != "" {
+		details.Operator = route.AgencyID
+	}
+	if details.ServiceNumber == "" {
+		details.ServiceNumber = route.ShortName
+	}
+	details.Mode = ModeOfRouteType(route.Type)
+
+	return details
+}
+
+// ModeOfRouteType converts the basic or the extended GTFS route type to the transport mode, empty for the others
+// (cable cars, funiculars and the like)
+func ModeOfRouteType(routeType int) string {
+	switch {
+	case routeType == 0 || routeType == 5 || routeType >= 900 && routeType < 1000:
+		return tables.TransportModeTram
+	case routeType == 1 || routeType >= 400 && routeType < 500:
+		return tables.TransportModeSubway
+	case routeType == 2 || routeType == 12 || routeType >= 100 && routeType < 200:
+		return tables.TransportModeTrain
+	case routeType == 3 || routeType == 11 || routeType >= 200 && routeType < 300 || routeType >= 700 && routeType < 900:
+		return tables.TransportModeBus
+	case routeType == 4 || routeType >= 1000 && routeType < 1100 || routeType == 1200:
+		return tables.TransportModeFerry
+	case routeType >= 1100 && routeType < 1200:
+		return tables.TransportModeFlight
+	}
+
+	return ""
+}
+
+// RouteTypeOfMode converts the transport mode to the basic GTFS route type (the extended one of flights),
+// defaultType is returned for the unknown modes
+func RouteTypeOfMode(mode string, defaultType int) int {
+	switch mode {
+	case tables.TransportModeTram:
+		return 0
+	case tables.TransportModeSubway:
+		return 1
+	case tables.TransportModeTrain:
+		return 2
+	case tables.TransportModeBus:
+		return 3
+	case tables.TransportModeFerry:
+		return 4
+	case tables.TransportModeFlight:
+		return 1100
+	}
+
+	return defaultType
+}
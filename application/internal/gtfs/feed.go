@@ -0,0 +1,330 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stop is a row of stops.txt
+type Stop struct {
+	ID        string
+	Name      string
+	Latitude  float64
+	Longitude float64
+	// LocationType 0 (or empty) is a stop or a platform, 1 a station of several stops
+	LocationType string
+	PlatformCode string
+}
+
+// Route is a row of routes.txt
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	// Type is the basic (0 - 12) or the extended (100 - 1700) route type
+	Type int
+}
+
+// Trip is a row of trips.txt
+type Trip struct {
+	ID        string
+	RouteID   string
+	ServiceID string
+	ShortName string
+}
+
+// Transfer is a row of transfers.txt, Type 2 needs MinTransferTime seconds to change and 3 is not possible
+type Transfer struct {
+	FromStopID      string
+	ToStopID        string
+	Type            int
+	MinTransferTime int
+}
+
+// StopTime is a row of stop_times.txt, the times are seconds since the noon minus 12h of the service day,
+// they exceed 24 hours for the trips running past midnight. Stop times without times have HasTimes false.
+type StopTime struct {
+	TripID        string
+	StopID        string
+	Sequence      int
+	ArrivalTime   int
+	DepartureTime int
+	HasTimes      bool
+}
+
+// Calendar is a row of calendar.txt, Weekdays are indexed by time.Weekday
+type Calendar struct {
+	ServiceID string
+	Weekdays  [7]bool
+	StartDate string
+	EndDate   string
+}
+
+// CalendarDate is a row of calendar_dates.txt, ExceptionType 1 adds the service day, 2 removes it
+type CalendarDate struct {
+	ServiceID     string
+	Date          string
+	ExceptionType int
+}
+
+// Feed is the static GTFS data needed to expand the trips into dated transfers
+type Feed struct {
+	Stops map[string]*Stop
+	// Routes are read of the optional routes.txt, the trips of unknown routes have no service details
+	Routes map[string]*Route
+	Trips  []*Trip
+	// StopTimes of the trips ordered by the stop sequence
+	StopTimes     map[string][]*StopTime
+	Calendars     map[string]*Calendar
+	CalendarDates map[string][]*CalendarDate
+	// Timezone of the agency the stop times are given in
+	Timezone *time.Location
+	// AgencyID of the first agency, the operator of the routes without agency_id
+	AgencyID string
+	// Transfers are read of the optional transfers.txt
+	Transfers []*Transfer
+}
+
+// ReadFeed reads agency.txt, stops.txt, routes.txt, trips.txt, stop_times.txt, calendar.txt, calendar_dates.txt
+// and transfers.txt of the GTFS zip file. One of the calendar files may be missing. The timezone is taken from agency.txt unless given.
+func ReadFeed(zipPath string, timezone *time.Location) (*Feed, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = archive.Close() }()
+
+	feed := &Feed{
+		Stops:         make(map[string]*Stop),
+		Routes:        make(map[string]*Route),
+		StopTimes:     make(map[string][]*StopTime),
+		Calendars:     make(map[string]*Calendar),
+		CalendarDates: make(map[string][]*CalendarDate),
+		Timezone:      timezone,
+	}
+
+	agencies := 0
+	err = readFile(&archive.Reader, "agency.txt", timezone == nil, func(r record) error {
+		agencies++
+		if agencies > 1 {
+			return nil
+		}
+		feed.AgencyID = r.get("agency_id")
+		if feed.Timezone == nil {
+			feed.Timezone, err = time.LoadLocation(r.get("agency_timezone"))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFile(&archive.Reader, "stops.txt", true, func(r record) error {
+		stop := &Stop{ID: r.get("stop_id"), Name: r.get("stop_name"), LocationType: r.get("location_type"), PlatformCode: r.get("platform_code")}
+		stop.Latitude, err = strconv.ParseFloat(r.get("stop_lat"), 64)
+		if err != nil {
+			return fmt.Errorf("invalid stop_lat of stop %s", stop.ID)
+		}
+		stop.Longitude, err = strconv.ParseFloat(r.get("stop_lon"), 64)
+		if err != nil {
+			return fmt.Errorf("invalid stop_lon of stop %s", stop.ID)
+		}
+		feed.Stops[stop.ID] = stop
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFile(&archive.Reader, "routes.txt", false, func(r record) error {
+		route := &Route{ID: r.get("route_id"), AgencyID: r.get("agency_id"), ShortName: r.get("route_short_name")}
+		route.Type, err = strconv.Atoi(r.get("route_type"))
+		if err != nil {
+			return fmt.Errorf("invalid route_type of route %s", route.ID)
+		}
+		feed.Routes[route.ID] = route
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFile(&archive.Reader, "trips.txt", true, func(r record) error {
+		feed.Trips = append(feed.Trips, &Trip{
+			ID:        r.get("trip_id"),
+			RouteID:   r.get("route_id"),
+			ServiceID: r.get("service_id"),
+			ShortName: r.get("trip_short_name"),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFile(&archive.Reader, "stop_times.txt", true, func(r record) error {
+		stopTime := &StopTime{TripID: r.get("trip_id"), StopID: r.get("stop_id")}
+		stopTime.Sequence, err = strconv.Atoi(r.get("stop_sequence"))
+		if err != nil {
+			return fmt.Errorf("invalid stop_sequence of trip %s", stopTime.TripID)
+		}
+
+		arrival, departure := r.get("arrival_time"), r.get("departure_time")
+		if arrival == "" {
+			arrival = departure
+		}
+		if departure == "" {
+			departure = arrival
+		}
+		if arrival != "" {
+			stopTime.ArrivalTime, err = ParseTime(arrival)
+			if err != nil {
+				return err
+			}
+			stopTime.DepartureTime, err = ParseTime(departure)
+			if err != nil {
+				return err
+			}
+			stopTime.HasTimes = true
+		}
+
+		feed.StopTimes[stopTime.TripID] = append(feed.StopTimes[stopTime.TripID], stopTime)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, stopTimes := range feed.StopTimes {
+		sort.Slice(stopTimes, func(i, j int) bool { return stopTimes[i].Sequence < stopTimes[j].Sequence })
+	}
+
+	err = readFile(&archive.Reader, "calendar.txt", false, func(r record) error {
+		calendar := &Calendar{ServiceID: r.get("service_id"), StartDate: r.get("start_date"), EndDate: r.get("end_date")}
+		for weekday, column := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+			calendar.Weekdays[weekday] = r.get(column) == "1"
+		}
+		feed.Calendars[calendar.ServiceID] = calendar
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFile(&archive.Reader, "calendar_dates.txt", false, func(r record) error {
+		date := &CalendarDate{ServiceID: r.get("service_id"), Date: r.get("date")}
+		date.ExceptionType, _ = strconv.Atoi(r.get("exception_type"))
+		feed.CalendarDates[date.ServiceID] = append(feed.CalendarDates[date.ServiceID], date)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readFile(&archive.Reader, "transfers.txt", false, func(r record) error {
+		transfer := &Transfer{FromStopID: r.get("from_stop_id"), ToStopID: r.get("to_stop_id")}
+		transfer.Type, _ = strconv.Atoi(r.get("transfer_type"))
+		transfer.MinTransferTime, _ = strconv.Atoi(r.get("min_transfer_time"))
+		feed.Transfers = append(feed.Transfers, transfer)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(feed.Calendars) == 0 && len(feed.CalendarDates) == 0 {
+		return nil, errors.New("the feed has neither calendar.txt nor calendar_dates.txt")
+	}
+	if feed.Timezone == nil {
+		return nil, errors.New("the feed has no agency timezone")
+	}
+
+	return feed, nil
+}
+
+// ParseTime converts a GTFS time HH:MM:SS (the hours may exceed 24) to seconds
+func ParseTime(value string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	seconds := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+
+	return seconds, nil
+}
+
+// record is a CSV row accessed by the header names
+type record struct {
+	columns map[string]int
+	values  []string
+}
+
+func (r record) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.values[i])
+}
+
+// readFile passes the rows of the file of the archive to the parser, a missing optional file is skipped
+func readFile(archive *zip.Reader, name string, required bool, parse func(r record) error) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		if !required {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		// the files may start with the byte order mark
+		columns[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = i
+	}
+
+	for line := 2; ; line++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+
+		err = parse(record{columns: columns, values: values})
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+	}
+}
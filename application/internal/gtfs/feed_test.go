@@ -0,0 +1,160 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// zipFeed packs the files of the testdata directory into a GTFS zip
+func zipFeed(t *testing.T, dir string) string {
+	zipPath := filepath.Join(t.TempDir(), "feed.zip")
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	archive := zip.NewWriter(file)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer, err := archive.Create(entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = writer.Write(content)
+	}
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return zipPath
+}
+
+func TestReadFeed(t *testing.T) {
+	feed, err := ReadFeed(zipFeed(t, "testdata/feed"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if feed.Timezone.String() != "Europe/Vilnius" {
+		t.Errorf("Expected agency timezone Europe/Vilnius, got %s", feed.Timezone)
+	}
+	if len(feed.Stops) != 5 || feed.Stops["VLN"] == nil || feed.Stops["VLN"].Longitude != 25.2839 {
+		t.Errorf("Expected 5 stops with VLN at longitude 25.2839, got %d", len(feed.Stops))
+	}
+	if len(feed.StopTimes["T2"]) != 3 || feed.StopTimes["T2"][2].ArrivalTime != 27*3600+5*60 {
+		t.Errorf("Expected 3 stop times of T2 arriving at 27:05:00, got %v", feed.StopTimes["T2"])
+	}
+	if feed.StopTimes["T1"][1].HasTimes {
+		t.Error("Expected the stop without times to have no times")
+	}
+
+	t.Run("points of the used stops", func(t *testing.T) {
+		points := feed.Points("LTG")
+		if len(points) != 4 {
+			t.Fatalf("Expected 4 points, got %d", len(points))
+		}
+		for _, point := range points {
+			if point.ID == "LTG:UNUSED" {
+				t.Error("Expected the unused stop skipped")
+			}
+		}
+	})
+
+	t.Run("timezone given", func(t *testing.T) {
+		riga, _ := time.LoadLocation("Europe/Riga")
+		feed, err := ReadFeed(zipFeed(t, "testdata/feed"), riga)
+		if err != nil || feed.Timezone != riga {
+			t.Errorf("Expected the given timezone, got %v %v", feed, err)
+		}
+	})
+}
+
+func TestFeed_ExpandDay(t *testing.T) {
+	feed, err := ReadFeed(zipFeed(t, "testdata/feed"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("stops without times are passed through", func(t *testing.T) {
+		transfers := feed.ExpandDay("LTG", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC))
+		if len(transfers) != 1 {
+			t.Fatalf("Expected 1 transfer, got %d", len(transfers))
+		}
+		tr := transfers[0]
+		if tr.ID != "LTG_T1_20260303_1" || tr.From != "LTG:VLN" || tr.To != "LTG:KNS" {
+			t.Errorf("Expected LTG_T1_20260303_1 LTG:VLN → LTG:KNS, got %s %s → %s", tr.ID, tr.From, tr.To)
+		}
+		if tr.Departure != time.Date(2026, 3, 3, 6, 0, 0, 0, time.UTC) || tr.Arrival != time.Date(2026, 3, 3, 7, 15, 0, 0, time.UTC) {
+			t.Errorf("Expected 06:00 → 07:15 UTC, got %s → %s", tr.Departure, tr.Arrival)
+		}
+		if tr.Operator != "LTG" || tr.ServiceNumber != "702" || tr.Mode != "train" || tr.Terminal != "3" {
+			t.Errorf("Expected LTG train 702 from platform 3, got %s %s %s from platform %s", tr.Operator, tr.Mode, tr.ServiceNumber, tr.Terminal)
+		}
+	})
+
+	t.Run("calendar exceptions", func(t *testing.T) {
+		days := map[int]int{2: 0, 6: 1, 7: 1, 8: 0}
+		for day, expected := range days {
+			transfers := feed.ExpandDay("LTG", time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC))
+			if len(transfers) != expected {
+				t.Errorf("Expected %d transfers on 2026-03-%02d, got %d", expected, day, len(transfers))
+			}
+		}
+	})
+
+	t.Run("times past midnight on the DST change", func(t *testing.T) {
+		transfers := feed.ExpandDay("LTG", time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC))
+		if len(transfers) != 2 {
+			t.Fatalf("Expected 2 transfers, got %d", len(transfers))
+		}
+		if transfers[0].Departure != time.Date(2026, 3, 28, 21, 10, 0, 0, time.UTC) || transfers[0].Arrival != time.Date(2026, 3, 28, 22, 30, 0, 0, time.UTC) {
+			t.Errorf("Expected 21:10 → 22:30 UTC, got %s → %s", transfers[0].Departure, transfers[0].Arrival)
+		}
+		if transfers[1].Departure != time.Date(2026, 3, 28, 22, 35, 0, 0, time.UTC) || transfers[1].Arrival != time.Date(2026, 3, 29, 1, 5, 0, 0, time.UTC) {
+			t.Errorf("Expected 22:35 → 01:05 UTC, got %s → %s", transfers[1].Departure, transfers[1].Arrival)
+		}
+		if transfers[1].Operator != "LTG" || transfers[1].ServiceNumber != "RB" || transfers[1].Mode != "bus" {
+			t.Errorf("Expected LTG bus RB of the route without agency, got %s %s %s", transfers[1].Operator, transfers[1].Mode, transfers[1].ServiceNumber)
+		}
+	})
+
+	t.Run("service dates", func(t *testing.T) {
+		from, to, err := feed.ServiceDates()
+		if err != nil || from.Format(time.DateOnly) != "2026-03-01" || to.Format(time.DateOnly) != "2026-03-31" {
+			t.Errorf("Expected 2026-03-01 - 2026-03-31, got %s - %s %v", from.Format(time.DateOnly), to.Format(time.DateOnly), err)
+		}
+	})
+}
+
+func TestTravelID(t *testing.T) {
+	id := TravelID("LTG", "a-very-long-trip-id-of-some-feeds-that-use-uuids-0123456789abcdef", "20260303", 12)
+	if len(id) != maxIdLength {
+		t.Errorf("Expected the id shortened to %d, got %d %s", maxIdLength, len(id), id)
+	}
+	if id == TravelID("LTG", "a-very-long-trip-id-of-some-feeds-that-use-uuids-0123456789abcdef", "20260303", 13) {
+		t.Error("Expected different ids of the different stops")
+	}
+}
+
+func TestModeOfRouteType(t *testing.T) {
+	expected := map[int]string{0: "tram", 1: "subway", 2: "train", 3: "bus", 4: "ferry", 6: "", 109: "train", 200: "bus", 1100: "flight"}
+	for routeType, mode := range expected {
+		if got := ModeOfRouteType(routeType); got != mode {
+			t.Errorf("Expected mode %q of route type %d, got %q", mode, routeType, got)
+		}
+		if mode != "" && ModeOfRouteType(RouteTypeOfMode(mode, 3)) != mode {
+			t.Errorf("Expected route type of %s converted back", mode)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package gtfs
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// walkingSpeed in km/h of the walks between the points
+const walkingSpeed = 4.5
+
+// earthRadius in km
+const earthRadius = 6371.0
+
+// Walk links two points, the searches change between the travels of its points by walking it
+type Walk struct {
+	From     string
+	To       string
+	Duration time.Duration
+}
+
+// DistanceKm is the great circle distance between the points of degree coordinates, x is longitude and y latitude
+func DistanceKm(a, b *tables.Point) float64 {
+	lat1, lat2 := a.Y*math.Pi/180, b.Y*math.Pi/180
+	dLat, dLon := lat2-lat1, (b.X-a.X)*math.Pi/180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// WalkingTime of the distance in km, rounded up to whole minutes
+func WalkingTime(distance float64) time.Duration {
+	return time.Duration(max(1, math.Ceil(distance/walkingSpeed*60))) * time.Minute
+}
+
+// Walks links the points of the stops used by the trips: the stops of transfers.txt (taking min_transfer_time
+// when given, transfers not possible are skipped) and, both ways, the stops and the other points (airports,
+// stops of the other feeds) not further than maxDistance km apart. The points of the feed are prefixed by prefix.
+func (f *Feed) Walks(prefix string, others []*tables.Point, maxDistance float64) []*Walk {
+	points := make(map[string]*tables.Point)
+	for _, point := range f.Points(prefix) {
+		points[point.ID] = point
+	}
+
+	var walks []*Walk
+	linked := make(map[string]bool)
+	link := func(from, to string, duration time.Duration) {
+		if from == to || linked[from+"\x00"+to] {
+			return
+		}
+		linked[from+"\x00"+to] = true
+		walks = append(walks, &Walk{From: from, To: to, Duration: duration})
+	}
+
+	for _, transfer := range f.Transfers {
+		from, to := points[PointID(prefix, transfer.FromStopID)], points[PointID(prefix, transfer.ToStopID)]
+		if from == nil || to == nil || transfer.Type >= 3 {
+			continue
+		}
+		duration := WalkingTime(DistanceKm(from, to))
+		if transfer.Type == 2 && transfer.MinTransferTime > 0 {
+			duration = time.Duration(transfer.MinTransferTime) * time.Second
+		}
+		link(from.ID, to.ID, duration)
+	}
+
+	if maxDistance <= 0 {
+		return walks
+	}
+	// a degree of latitude is 111 km, the points further in latitude are skipped without computing the distance
+	maxLatitude := maxDistance / 111
+	for _, stop := range f.Points(prefix) {
+		for _, other := range others {
+			if strings.HasPrefix(other.ID, prefix+":") || math.Abs(other.Y-stop.Y) > maxLatitude {
+				continue
+			}
+			distance := DistanceKm(stop, other)
+			if distance > maxDistance {
+				continue
+			}
+			link(stop.ID, other.ID, WalkingTime(distance))
+			link(other.ID, stop.ID, WalkingTime(distance))
+		}
+	}
+
+	return walks
+}
+
+// ExpandWalks creates the walking travels departing every interval of the UTC day, so the walks are timed
+// travels the searches join like the others. Waiting for the next departure makes the walks up to interval longer.
+func ExpandWalks(prefix string, walks []*Walk, day time.Time, interval time.Duration) []*tables.Transfer {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	var transfers []*tables.Transfer
+	for _, walk := range walks {
+		for departure := start; departure.Before(start.AddDate(0, 0, 1)); departure = departure.Add(interval) {
+			transfers = append(transfers, &tables.Transfer{
+				ID:        shortenID(fmt.Sprintf("%s_walk_%s_%s_%s", prefix, walk.From, walk.To, departure.Format("200601021504"))),
+				From:      walk.From,
+				To:        walk.To,
+				Departure: departure,
+				Arrival:   departure.Add(walk.Duration),
+				Mode:      tables.TransportModeWalk,
+			})
+		}
+	}
+
+	return transfers
+}
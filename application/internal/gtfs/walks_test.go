@@ -0,0 +1,81 @@
+package gtfs
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/transfer_patterns"
+	"testing"
+	"time"
+)
+
+// airport near the Kaunas stop of the testdata feed
+var airport = &tables.Point{ID: "AIR", Name: "Airport", X: 23.9500, Y: 54.8880}
+
+func TestDistanceKm(t *testing.T) {
+	vilnius := &tables.Point{X: 25.2839, Y: 54.6707}
+	riga := &tables.Point{X: 24.1209, Y: 56.9466}
+	distance := DistanceKm(vilnius, riga)
+	if distance < 262 || distance > 265 {
+		t.Errorf("Expected about 263 km, got %f", distance)
+	}
+}
+
+func TestFeed_Walks(t *testing.T) {
+	feed, err := ReadFeed(zipFeed(t, "testdata/feed"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	walks := feed.Walks("LTG", []*tables.Point{airport, {ID: "LTG:KNS", X: 23.9386, Y: 54.8847}}, 1)
+	expected := []Walk{
+		{From: "LTG:KNS", To: "LTG:KAI", Duration: 10 * time.Minute},
+		{From: "LTG:KNS", To: "AIR", Duration: 11 * time.Minute},
+		{From: "AIR", To: "LTG:KNS", Duration: 11 * time.Minute},
+	}
+	if len(walks) != len(expected) {
+		t.Fatalf("Expected %d walks, got %d", len(expected), len(walks))
+	}
+	for i, walk := range walks {
+		if *walk != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], *walk)
+		}
+	}
+}
+
+func TestMixedPath(t *testing.T) {
+	feed, err := ReadFeed(zipFeed(t, "testdata/feed"), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	day := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	trains := feed.ExpandDay("LTG", day)
+	walks := ExpandWalks("LTG", feed.Walks("LTG", []*tables.Point{airport}, 1), day, 15*time.Minute)
+	var toAirport []*tables.Transfer
+	for _, walk := range walks {
+		if walk.To == "AIR" {
+			toAirport = append(toAirport, walk)
+		}
+	}
+	if len(toAirport) != 96 {
+		t.Fatalf("Expected 96 walks to the airport, got %d", len(toAirport))
+	}
+	flights := []*tables.Transfer{{
+		ID: "FR1_202603030830_AIR_RIX", From: "AIR", To: "RIX",
+		Departure: day.Add(8*time.Hour + 30*time.Minute), Arrival: day.Add(9*time.Hour + 30*time.Minute),
+		Mode: tables.TransportModeFlight,
+	}}
+
+	sequences := transfer_patterns.JoinLegs([][]*tables.Transfer{trains, toAirport, flights}, 0, 2*time.Hour, 10)
+	if len(sequences) == 0 {
+		t.Fatal("Expected a train, walk and flight path, got none")
+	}
+	path := sequences[0].Transfers
+	if path[0].ID != "LTG_T1_20260303_1" || path[2].ID != "FR1_202603030830_AIR_RIX" {
+		t.Errorf("Expected the train and the flight, got %s and %s", path[0].ID, path[2].ID)
+	}
+	walk := path[1]
+	if walk.From != "LTG:KNS" || walk.Mode != tables.TransportModeWalk ||
+		walk.Departure != day.Add(7*time.Hour+15*time.Minute) || walk.Arrival != day.Add(7*time.Hour+26*time.Minute) {
+		t.Errorf("Expected the walk from LTG:KNS at 07:15 → 07:26, got %s %s at %s → %s", walk.From, walk.Mode, walk.Departure, walk.Arrival)
+	}
+}
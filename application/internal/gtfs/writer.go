@@ -0,0 +1,176 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"darbelis.eu/persedimai/internal/tables"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// kilometresPerDegree of the latitude (and of the longitude at the equator)
+const kilometresPerDegree = 111.32
+
+// ExportOptions of the exported feed
+type ExportOptions struct {
+	AgencyName string
+	AgencyURL  string
+	// RouteType of the routes of the travels without a transport mode, 3 (bus) is accepted by all the tools,
+	// 1100 is the extended air service type
+	RouteType int
+	// Kilometres converts the synthetic coordinates given in kilometres into degrees from the (0, 0) point
+	Kilometres bool
+}
+
+// ExportSummary counts the exported and skipped rows
+type ExportSummary struct {
+	Stops           int
+	Routes          int
+	Trips           int
+	ServiceDays     int
+	SkippedTravels  int
+	UnknownPointIds int
+}
+
+// NeedsKilometres tells whether the points coordinates do not fit the longitude and latitude ranges,
+// which is the case of the generated points
+func NeedsKilometres(points []*tables.Point) bool {
+	for _, point := range points {
+		if point.X < -180 || point.X > 180 || point.Y < -90 || point.Y > 90 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteFeed writes the points and the travels as a GTFS zip. Every travel becomes a trip of two stop times
+// of the route of its points and transport mode, named by its service number, run on the service day of its UTC departure date. The agency timezone is UTC,
+// so the arrivals on the following days get times past 24:00:00. Travels of unknown points or arriving
+// before the departure are skipped.
+func WriteFeed(w io.Writer, points []*tables.Point, travels []*tables.Transfer, options ExportOptions) (*ExportSummary, error) {
+	summary := &ExportSummary{}
+	archive := zip.NewWriter(w)
+
+	known := make(map[string]bool, len(points))
+	stops := [][]string{{"stop_id", "stop_name", "stop_lat", "stop_lon"}}
+	for _, point := range points {
+		lat, lon := point.Y, point.X
+		if options.Kilometres {
+			lat, lon = point.Y/kilometresPerDegree, point.X/kilometresPerDegree
+		}
+		name := point.Name
+		if name == "" {
+			name = point.ID
+		}
+		stops = append(stops, []string{point.ID, name, formatCoordinate(lat), formatCoordinate(lon)})
+		known[point.ID] = true
+	}
+	summary.Stops = len(points)
+
+	routeIds := make(map[string]string)
+	routes := [][]string{{"route_id", "agency_id", "route_long_name", "route_type"}}
+	trips := [][]string{{"route_id", "service_id", "trip_id", "trip_short_name"}}
+	stopTimes := [][]string{{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence"}}
+	serviceDays := make(map[string]bool)
+
+	for _, travel := range travels {
+		if !known[travel.From] || !known[travel.To] {
+			summary.UnknownPointIds++
+			summary.SkippedTravels++
+			continue
+		}
+		if travel.Arrival.Before(travel.Departure) {
+			summary.SkippedTravels++
+			continue
+		}
+
+		routeKey := travel.From + "\x00" + travel.To + "\x00" + travel.Mode
+		routeID, ok := routeIds[routeKey]
+		if !ok {
+			routeID = strconv.Itoa(len(routeIds) + 1)
+			routeIds[routeKey] = routeID
+			routeType := RouteTypeOfMode(travel.Mode, options.RouteType)
+			routes = append(routes, []string{routeID, "1", travel.From + " - " + travel.To, strconv.Itoa(routeType)})
+		}
+
+		departure := travel.Departure.UTC()
+		serviceDay := time.Date(departure.Year(), departure.Month(), departure.Day(), 0, 0, 0, 0, time.UTC)
+		serviceID := serviceDay.Format(dateLayout)
+		serviceDays[serviceID] = true
+
+		departureTime := formatTime(departure.Sub(serviceDay))
+		arrivalTime := formatTime(travel.Arrival.UTC().Sub(serviceDay))
+		trips = append(trips, []string{routeID, serviceID, travel.ID, travel.ServiceNumber})
+		stopTimes = append(stopTimes,
+			[]string{travel.ID, departureTime, departureTime, travel.From, "1"},
+			[]string{travel.ID, arrivalTime, arrivalTime, travel.To, "2"},
+		)
+	}
+	summary.Routes = len(routeIds)
+	summary.Trips = len(trips) - 1
+	summary.ServiceDays = len(serviceDays)
+
+	// services are single days, so calendar_dates.txt alone defines them
+	dates := make([]string, 0, len(serviceDays))
+	for date := range serviceDays {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	calendarDates := [][]string{{"service_id", "date", "exception_type"}}
+	for _, date := range dates {
+		calendarDates = append(calendarDates, []string{date, date, "1"})
+	}
+
+	files := []struct {
+		name string
+		rows [][]string
+	}{
+		{"agency.txt", [][]string{
+			{"agency_id", "agency_name", "agency_url", "agency_timezone"},
+			{"1", options.AgencyName, options.AgencyURL, "UTC"},
+		}},
+		{"stops.txt", stops},
+		{"routes.txt", routes},
+		{"trips.txt", trips},
+		{"stop_times.txt", stopTimes},
+		{"calendar_dates.txt", calendarDates},
+	}
+	for _, file := range files {
+		err := writeFile(archive, file.name, file.rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, archive.Close()
+}
+
+func writeFile(archive *zip.Writer, name string, rows [][]string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	writer := csv.NewWriter(file)
+	err = writer.WriteAll(rows)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// formatTime formats the duration since the service day start as GTFS time, the hours may exceed 24
+func formatTime(sinceDayStart time.Duration) string {
+	seconds := int(sinceDayStart / time.Second)
+
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', 6, 64)
+}
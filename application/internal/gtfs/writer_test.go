@@ -0,0 +1,80 @@
+package gtfs
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFeed(t *testing.T) {
+	points := []*tables.Point{
+		{ID: "VNO", X: 25.2858, Y: 54.6341, Name: "Vilnius"},
+		{ID: "RIX", X: 23.9711, Y: 56.9236, Name: "Riga"},
+		{ID: "LTG:KNS", X: 23.9386, Y: 54.8847},
+	}
+	travels := []*tables.Transfer{
+		{ID: "BT344_202603012330_VNORIX", From: "VNO", To: "RIX",
+			Departure: time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC), Arrival: time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC),
+			ServiceNumber: "BT344", Mode: tables.TransportModeFlight},
+		{ID: "late", From: "RIX", To: "VNO",
+			Departure: time.Date(2026, 3, 1, 23, 10, 0, 0, time.UTC), Arrival: time.Date(2026, 3, 2, 0, 20, 0, 0, time.UTC)},
+		{ID: "unknown", From: "VNO", To: "WAW",
+			Departure: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), Arrival: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)},
+		{ID: "backwards", From: "VNO", To: "LTG:KNS",
+			Departure: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), Arrival: time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)},
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "export.zip")
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := WriteFeed(file, points, travels, ExportOptions{AgencyName: "persedimai", AgencyURL: "https://darbelis.eu", RouteType: 3})
+	_ = file.Close()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if summary.Trips != 2 || summary.Routes != 2 || summary.SkippedTravels != 2 || summary.UnknownPointIds != 1 || summary.ServiceDays != 1 {
+		t.Errorf("Expected 2 trips of 2 routes on 1 day, 2 skipped of which 1 of unknown points, got %+v", summary)
+	}
+
+	t.Run("the exported feed is read back", func(t *testing.T) {
+		feed, err := ReadFeed(zipPath, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if feed.Stops["LTG:KNS"] == nil || feed.Stops["LTG:KNS"].Name != "LTG:KNS" {
+			t.Errorf("Expected the stop without name named by its id, got %+v", feed.Stops["LTG:KNS"])
+		}
+
+		transfers := feed.ExpandDay("X", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		if len(transfers) != 2 {
+			t.Fatalf("Expected 2 transfers, got %d", len(transfers))
+		}
+		for i, transfer := range transfers {
+			if transfer.From != "X:"+travels[i].From || !transfer.Departure.Equal(travels[i].Departure) || !transfer.Arrival.Equal(travels[i].Arrival) {
+				t.Errorf("Expected %s %s → %s, got %s %s → %s", travels[i].From, travels[i].Departure, travels[i].Arrival,
+					transfer.From, transfer.Departure, transfer.Arrival)
+			}
+		}
+		if transfers[0].ServiceNumber != "BT344" || transfers[0].Mode != "flight" || transfers[1].Mode != "bus" {
+			t.Errorf("Expected flight BT344 and a bus of the default route type, got %s %s and %s",
+				transfers[0].Mode, transfers[0].ServiceNumber, transfers[1].Mode)
+		}
+		if feed.StopTimes["late"][1].ArrivalTime != 24*3600+20*60 {
+			t.Errorf("Expected the arrival on the next day at 24:20:00, got %d", feed.StopTimes["late"][1].ArrivalTime)
+		}
+	})
+}
+
+func TestNeedsKilometres(t *testing.T) {
+	if NeedsKilometres([]*tables.Point{{X: 25.28, Y: 54.63}}) {
+		t.Error("Expected the degrees kept")
+	}
+	if !NeedsKilometres([]*tables.Point{{X: 25.28, Y: 54.63}, {X: 2000, Y: 0}}) {
+		t.Error("Expected the generated coordinates converted")
+	}
+}
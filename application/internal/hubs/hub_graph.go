@@ -0,0 +1,107 @@
+package hubs
+
+import "darbelis.eu/persedimai/internal/tables"
+
+// HubGraph holds the direct connections between hubs
+type HubGraph struct {
+	neighbours map[string][]string
+}
+
+func NewHubGraph(connections []*tables.HubConnection) *HubGraph {
+	neighbours := make(map[string][]string)
+	for _, connection := range connections {
+		neighbours[connection.FromHub] = append(neighbours[connection.FromHub], connection.ToHub)
+	}
+
+	return &HubGraph{neighbours: neighbours}
+}
+
+// Paths returns hub sequences from one hub to another having exactly the given amount of legs.
+// A hub is not visited twice within a path.
+func (g *HubGraph) Paths(from, to string, legs int) [][]string {
+	var paths [][]string
+	visited := map[string]bool{from: true}
+	current := []string{from}
+
+	var walk func(point string, legsLeft int)
+	walk = func(point string, legsLeft int) {
+		if legsLeft == 0 {
+			if point == to {
+				path := make([]string, len(current))
+				copy(path, current)
+				paths = append(paths, path)
+			}
+			return
+		}
+
+		for _, next := range g.neighbours[point] {
+			if visited[next] {
+				continue
+			}
+
+			visited[next] = true
+			current = append(current, next)
+			walk(next, legsLeft-1)
+			current = current[:len(current)-1]
+			visited[next] = false
+		}
+	}
+	walk(from, legs)
+
+	return paths
+}
+
+// PointSequences combines access legs to the source hubs, hub to hub paths and egress legs
+// from the destination hubs into sequences of points having exactly travelCount legs.
+// At most maxSequences sequences are returned, the ones through the nearest hubs first.
+func (g *HubGraph) PointSequences(source, destination string, sourceHubs, destinationHubs []string, travelCount int, maxSequences int) [][]string {
+	var sequences [][]string
+
+	for _, sourceHub := range sourceHubs {
+		for _, destinationHub := range destinationHubs {
+			hubLegs := travelCount
+			if sourceHub != source {
+				hubLegs--
+			}
+			if destinationHub != destination {
+				hubLegs--
+			}
+			if hubLegs < 0 {
+				continue
+			}
+
+			for _, hubPath := range g.Paths(sourceHub, destinationHub, hubLegs) {
+				sequence := hubPath
+				if sourceHub != source {
+					sequence = append([]string{source}, sequence...)
+				}
+				if destinationHub != destination {
+					sequence = append(sequence, destination)
+				}
+
+				if hasRepeatedPoints(sequence) {
+					continue
+				}
+
+				sequences = append(sequences, sequence)
+				if len(sequences) >= maxSequences {
+					return sequences
+				}
+			}
+		}
+	}
+
+	return sequences
+}
+
+func hasRepeatedPoints(sequence []string) bool {
+	seen := make(map[string]bool, len(sequence))
+	for _, point := range sequence {
+		if seen[point] {
+			return true
+		}
+		seen[point] = true
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+package hubs
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"reflect"
+	"testing"
+)
+
+func makeGraph() *HubGraph {
+	return NewHubGraph([]*tables.HubConnection{
+		{FromHub: "H1", ToHub: "H2"},
+		{FromHub: "H2", ToHub: "H1"},
+		{FromHub: "H2", ToHub: "H3"},
+		{FromHub: "H1", ToHub: "H3"},
+	})
+}
+
+func TestHubGraph_Paths(t *testing.T) {
+	graph := makeGraph()
+
+	t.Run("zero legs", func(t *testing.T) {
+		paths := graph.Paths("H1", "H1", 0)
+		if !reflect.DeepEqual(paths, [][]string{{"H1"}}) {
+			t.Errorf("Expected [[H1]], got %v", paths)
+		}
+	})
+
+	t.Run("one leg", func(t *testing.T) {
+		paths := graph.Paths("H1", "H3", 1)
+		if !reflect.DeepEqual(paths, [][]string{{"H1", "H3"}}) {
+			t.Errorf("Expected [[H1 H3]], got %v", paths)
+		}
+	})
+
+	t.Run("two legs", func(t *testing.T) {
+		paths := graph.Paths("H1", "H3", 2)
+		if !reflect.DeepEqual(paths, [][]string{{"H1", "H2", "H3"}}) {
+			t.Errorf("Expected [[H1 H2 H3]], got %v", paths)
+		}
+	})
+
+	t.Run("hub is not visited twice", func(t *testing.T) {
+		paths := graph.Paths("H1", "H3", 3)
+		if len(paths) != 0 {
+			t.Errorf("Expected no paths, got %v", paths)
+		}
+	})
+}
+
+func TestHubGraph_PointSequences(t *testing.T) {
+	graph := makeGraph()
+
+	t.Run("access and egress legs", func(t *testing.T) {
+		sequences := graph.PointSequences("A", "B", []string{"H1"}, []string{"H3"}, 3, 10)
+		expected := [][]string{{"A", "H1", "H3", "B"}}
+		if !reflect.DeepEqual(sequences, expected) {
+			t.Errorf("Expected %v, got %v", expected, sequences)
+		}
+	})
+
+	t.Run("source is a hub", func(t *testing.T) {
+		sequences := graph.PointSequences("H1", "B", []string{"H1"}, []string{"H3"}, 3, 10)
+		expected := [][]string{{"H1", "H2", "H3", "B"}}
+		if !reflect.DeepEqual(sequences, expected) {
+			t.Errorf("Expected %v, got %v", expected, sequences)
+		}
+	})
+
+	t.Run("max sequences", func(t *testing.T) {
+		sequences := graph.PointSequences("A", "B", []string{"H1", "H2"}, []string{"H3"}, 3, 1)
+		if len(sequences) != 1 {
+			t.Errorf("Expected 1 sequence, got %d", len(sequences))
+		}
+	})
+}
+
+func TestNearestHubs(t *testing.T) {
+	hubPoints := []*tables.Point{
+		{ID: "H1", X: 0, Y: 0},
+		{ID: "H2", X: 0, Y: 10},
+		{ID: "H3", X: 0, Y: 3},
+	}
+
+	t.Run("nearest first", func(t *testing.T) {
+		pointHubs := NearestHubs(&tables.Point{ID: "A", X: 1, Y: 1}, hubPoints, 2)
+		if len(pointHubs) != 2 {
+			t.Fatalf("Expected 2 hubs, got %d", len(pointHubs))
+		}
+		if pointHubs[0].HubID != "H1" || pointHubs[1].HubID != "H3" {
+			t.Errorf("Expected H1, H3, got %s, %s", pointHubs[0].HubID, pointHubs[1].HubID)
+		}
+	})
+
+	t.Run("hub is its own hub", func(t *testing.T) {
+		pointHubs := NearestHubs(hubPoints[1], hubPoints, 2)
+		if len(pointHubs) != 1 || pointHubs[0].HubID != "H2" {
+			t.Errorf("Expected only H2, got %v", pointHubs)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package hubs
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"sort"
+)
+
+// NearestHubs returns up to count hubs closest to the point ordered by distance.
+// A hub point is assigned only to itself.
+func NearestHubs(point *tables.Point, hubPoints []*tables.Point, count int) []*tables.PointHub {
+	for _, hubPoint := range hubPoints {
+		if hubPoint.ID == point.ID {
+			return []*tables.PointHub{{PointID: point.ID, HubID: point.ID, Distance: 0}}
+		}
+	}
+
+	pointHubs := make([]*tables.PointHub, len(hubPoints))
+	for i, hubPoint := range hubPoints {
+		pointHubs[i] = &tables.PointHub{PointID: point.ID, HubID: hubPoint.ID, Distance: point.CalculateDistance(*hubPoint)}
+	}
+
+	sort.SliceStable(pointHubs, func(i, j int) bool { return pointHubs[i].Distance < pointHubs[j].Distance })
+
+	if len(pointHubs) > count {
+		pointHubs = pointHubs[:count]
+	}
+
+	return pointHubs
+}
@@ -0,0 +1,111 @@
+package _import
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAlreadyImported is returned by the airport collection when the requested range is imported
+var ErrAlreadyImported = errors.New("already imported")
+
+// AirportsCollectionSummary is the outcome of the airports collected by CollectAirports
+type AirportsCollectionSummary struct {
+	Succeeded []string
+	Skipped   []string
+	Failed    map[string]error
+	// Interrupted airports were stopped or not started because of the shutdown, the next run resumes them
+	Interrupted []string
+	Duration    time.Duration
+}
+
+func (s *AirportsCollectionSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Collected %d airports in %s: %d succeeded, %d skipped, %d failed, %d interrupted\n",
+		len(s.Succeeded)+len(s.Skipped)+len(s.Failed)+len(s.Interrupted), s.Duration.Round(time.Second),
+		len(s.Succeeded), len(s.Skipped), len(s.Failed), len(s.Interrupted))
+
+	failed := make([]string, 0, len(s.Failed))
+	for airportCode := range s.Failed {
+		failed = append(failed, airportCode)
+	}
+	sort.Strings(failed)
+	for _, airportCode := range failed {
+		fmt.Fprintf(&b, "  %s failed: %v\n", airportCode, s.Failed[airportCode])
+	}
+
+	if len(s.Interrupted) > 0 {
+		fmt.Fprintf(&b, "  interrupted: %s\n", strings.Join(s.Interrupted, ", "))
+	}
+
+	return b.String()
+}
+
+// CollectAirports runs collect for the airports by the given number of workers. When the context is cancelled
+// no more airports are started and the running ones are expected to stop after their current day.
+func CollectAirports(ctx context.Context, airportCodes []string, workers int, collect func(ctx context.Context, airportCode string) error) *AirportsCollectionSummary {
+	started := time.Now()
+	summary := &AirportsCollectionSummary{Failed: map[string]error{}}
+	var mu sync.Mutex
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for airportCode := range queue {
+				err := collect(ctx, airportCode)
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					summary.Succeeded = append(summary.Succeeded, airportCode)
+				case errors.Is(err, ErrAlreadyImported):
+					summary.Skipped = append(summary.Skipped, airportCode)
+				case errors.Is(err, context.Canceled):
+					summary.Interrupted = append(summary.Interrupted, airportCode)
+				default:
+					log.Printf("Airport %s failed: %v", airportCode, err)
+					summary.Failed[airportCode] = err
+				}
+				done := len(summary.Succeeded) + len(summary.Skipped) + len(summary.Failed) + len(summary.Interrupted)
+				mu.Unlock()
+
+				log.Printf("Progress: %d/%d airports", done, len(airportCodes))
+			}
+		}()
+	}
+
+	for i, airportCode := range airportCodes {
+		select {
+		case queue <- airportCode:
+			continue
+		case <-ctx.Done():
+		}
+
+		// the shutdown leaves the rest of the airports for the next run
+		mu.Lock()
+		summary.Interrupted = append(summary.Interrupted, airportCodes[i:]...)
+		mu.Unlock()
+		break
+	}
+	close(queue)
+	wg.Wait()
+
+	sort.Strings(summary.Succeeded)
+	sort.Strings(summary.Skipped)
+	sort.Strings(summary.Interrupted)
+	summary.Duration = time.Since(started)
+
+	return summary
+}
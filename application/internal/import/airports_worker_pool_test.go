@@ -0,0 +1,66 @@
+package _import
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCollectAirports(t *testing.T) {
+	t.Run("summary of the outcomes", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		collect := func(ctx context.Context, airportCode string) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			switch airportCode {
+			case "RIX":
+				return ErrAlreadyImported
+			case "TLL":
+				return errors.New("API is down")
+			}
+			return nil
+		}
+
+		summary := CollectAirports(context.Background(), []string{"VNO", "RIX", "TLL", "KUN", "WAW"}, 2, collect)
+
+		if len(summary.Succeeded) != 3 || summary.Succeeded[0] != "KUN" {
+			t.Errorf("Expected KUN, VNO and WAW succeeded, got %v", summary.Succeeded)
+		}
+		if len(summary.Skipped) != 1 || summary.Skipped[0] != "RIX" {
+			t.Errorf("Expected RIX skipped, got %v", summary.Skipped)
+		}
+		if len(summary.Failed) != 1 || summary.Failed["TLL"] == nil {
+			t.Errorf("Expected TLL failed, got %v", summary.Failed)
+		}
+		if maxRunning.Load() > 2 {
+			t.Errorf("Expected at most 2 airports at once, got %d", maxRunning.Load())
+		}
+	})
+
+	t.Run("shutdown leaves the rest for the next run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		collect := func(ctx context.Context, airportCode string) error {
+			if airportCode == "RIX" {
+				cancel()
+			}
+			return ctx.Err()
+		}
+
+		summary := CollectAirports(ctx, []string{"VNO", "RIX", "TLL", "KUN"}, 1, collect)
+
+		if len(summary.Succeeded) != 1 || summary.Succeeded[0] != "VNO" {
+			t.Errorf("Expected VNO succeeded, got %v", summary.Succeeded)
+		}
+		if len(summary.Interrupted) != 3 {
+			t.Errorf("Expected RIX, TLL and KUN interrupted, got %v", summary.Interrupted)
+		}
+	})
+}
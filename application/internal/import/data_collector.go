@@ -1,13 +1,16 @@
 package _import
 
 import (
+	"context"
 	"darbelis.eu/persedimai/internal/aviation_edge"
 	"darbelis.eu/persedimai/internal/dao"
 	"darbelis.eu/persedimai/internal/tables"
 	"darbelis.eu/persedimai/internal/util"
+	"errors"
 	"fmt"
 	"log"
-	"time"
+	"strings"
+	"sync"
 )
 
 // DataCollector handles data collection operations using the Aviation Edge API
@@ -16,6 +19,13 @@ type DataCollector struct {
 	consumer        aviation_edge.ScheduleConsumer
 	airportsDao     *dao.AirportsDao
 	airportsMetaDao *dao.AirportsMetaDao
+
+	// airportsHistoryMetaDao tracks the historical schedules imports
+	airportsHistoryMetaDao *dao.AirportsMetaDao
+
+	// scheduleTimeNormalizer is created of all the airports on the first use
+	scheduleTimeNormalizer *ScheduleTimeNormalizer
+	normalizerMu           sync.Mutex
 }
 
 // NewDataCollector creates a new DataCollector with dependency injection
@@ -24,12 +34,14 @@ func NewDataCollector(
 	consumer aviation_edge.ScheduleConsumer,
 	airportsDao *dao.AirportsDao,
 	airportsMetaDao *dao.AirportsMetaDao,
+	airportsHistoryMetaDao *dao.AirportsMetaDao,
 ) *DataCollector {
 	return &DataCollector{
-		apiClient:       apiClient,
-		consumer:        consumer,
-		airportsDao:     airportsDao,
-		airportsMetaDao: airportsMetaDao,
+		apiClient:              apiClient,
+		consumer:               consumer,
+		airportsDao:            airportsDao,
+		airportsMetaDao:        airportsMetaDao,
+		airportsHistoryMetaDao: airportsHistoryMetaDao,
 	}
 }
 
@@ -37,14 +49,42 @@ func (dc *DataCollector) GetConsumer() aviation_edge.ScheduleConsumer {
 	return dc.consumer
 }
 
+// getScheduleTimeNormalizer loads the airports timezones once
+func (dc *DataCollector) getScheduleTimeNormalizer() (*ScheduleTimeNormalizer, error) {
+	dc.normalizerMu.Lock()
+	defer dc.normalizerMu.Unlock()
+
+	if dc.scheduleTimeNormalizer != nil {
+		return dc.scheduleTimeNormalizer, nil
+	}
+
+	airports, err := dc.airportsDao.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load airports timezones: %w", err)
+	}
+
+	dc.scheduleTimeNormalizer = NewScheduleTimeNormalizer(NewAirportTimezones(airports))
+
+	return dc.scheduleTimeNormalizer, nil
+}
+
 func (dc *DataCollector) CollectDepartureSchedules(airportCode string, dateFrom, dateTo string) error {
+	return dc.collectSchedules(airportCode, dateFrom, dateTo, dc.CollectDepartureSchedulesForOneDay)
+}
+
+// CollectArrivalSchedules collects the schedules of the flights arriving to the airport in the local days of the range
+func (dc *DataCollector) CollectArrivalSchedules(airportCode string, dateFrom, dateTo string) error {
+	return dc.collectSchedules(airportCode, dateFrom, dateTo, dc.CollectArrivalSchedulesForOneDay)
+}
+
+func (dc *DataCollector) collectSchedules(airportCode string, dateFrom, dateTo string, collectForOneDay func(airportCode string, day string) error) error {
 	dateRange, err := util.GenerateDateRange(dateFrom, dateTo)
 
 	if err != nil {
 		return err
 	}
 	for _, date := range dateRange {
-		err = dc.CollectDepartureSchedulesForOneDay(airportCode, date)
+		err = collectForOneDay(airportCode, date)
 		if err != nil {
 			return err
 		}
@@ -53,39 +93,200 @@ func (dc *DataCollector) CollectDepartureSchedules(airportCode string, dateFrom,
 	return nil
 }
 
+// CollectAirportSchedules collects the departures (and arrivals if asked) of the airport in the days of the range
+// not imported yet, marking every collected day in airports_meta_days, so an interrupted or failed import is resumed
+// by the next run. ErrAlreadyImported is returned when all the days are imported, the context error when cancelled
+// between the days.
+func (dc *DataCollector) CollectAirportSchedules(ctx context.Context, airportCode string, startDate, endDate string, arrivals bool) error {
+	collectors := map[string]func(airportCode string, day string) error{
+		"departure": dc.CollectDepartureSchedulesForOneDay,
+	}
+	if arrivals {
+		collectors["arrival"] = dc.CollectArrivalSchedulesForOneDay
+	}
+
+	return dc.collectAirport(ctx, dc.airportsMetaDao, airportCode, startDate, endDate, collectors)
+}
+
+// CollectAirportHistory collects the historical departures of the airport in the days of the range not imported yet,
+// tracked in airports_history_meta_days
+func (dc *DataCollector) CollectAirportHistory(ctx context.Context, airportCode string, startDate, endDate string) error {
+	collectors := map[string]func(airportCode string, day string) error{
+		"departure": dc.CollectHistoricalSchedulesForOneDay,
+	}
+
+	return dc.collectAirport(ctx, dc.airportsHistoryMetaDao, airportCode, startDate, endDate, collectors)
+}
+
+// collectAirport plans and collects the missing days of every schedule type by its collector
+func (dc *DataCollector) collectAirport(ctx context.Context, airportsMetaDao *dao.AirportsMetaDao, airportCode string, startDate, endDate string, collectors map[string]func(airportCode string, day string) error) error {
+	plans := make(map[string]*ImportPlan)
+	for _, scheduleType := range []string{"departure", "arrival"} {
+		if collectors[scheduleType] == nil {
+			continue
+		}
+
+		importedDays, err := airportsMetaDao.GetImportedDays(airportCode, scheduleType, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to get imported days: %w", err)
+		}
+
+		plan, err := CalculateImportPlan(startDate, endDate, importedDays)
+		if err != nil {
+			return err
+		}
+
+		if plan.SkipImport {
+			log.Printf("Airport %s %s schedules are already imported from %s to %s", airportCode, scheduleType, startDate, endDate)
+			continue
+		}
+		log.Printf("Airport %s %s schedules: %d days imported, missing %s", airportCode, scheduleType, plan.CoveredDays, plan.GapsString())
+		plans[scheduleType] = plan
+	}
+
+	if len(plans) == 0 {
+		return ErrAlreadyImported
+	}
+
+	err := airportsMetaDao.StartImport(airportCode)
+	if err != nil {
+		return fmt.Errorf("failed to start the import of airport %s: %w", airportCode, err)
+	}
+
+	for _, scheduleType := range []string{"departure", "arrival"} {
+		plan, ok := plans[scheduleType]
+		if !ok {
+			continue
+		}
+
+		for _, day := range plan.MissingDays {
+			if ctx.Err() != nil {
+				log.Printf("Airport %s interrupted before %s %s", airportCode, scheduleType, day)
+				return ctx.Err()
+			}
+
+			err = collectors[scheduleType](airportCode, day)
+			if err != nil {
+				failErr := airportsMetaDao.FailImport(airportCode, err)
+				if failErr != nil {
+					log.Printf("Failed to mark airport %s as failed: %v", airportCode, failErr)
+				}
+				return err
+			}
+
+			err = airportsMetaDao.MarkDayImported(airportCode, scheduleType, day)
+			if err != nil {
+				return fmt.Errorf("failed to mark day %s of airport %s imported: %w", day, airportCode, err)
+			}
+		}
+	}
+
+	err = airportsMetaDao.FinishImport(airportCode)
+	if err != nil {
+		return fmt.Errorf("failed to update airport metadata: %w", err)
+	}
+
+	return nil
+}
+
 func (dc *DataCollector) CollectDepartureSchedulesForOneDay(airportCode string, day string) error {
-	log.Printf("Collecting current schedules for airport: %s, day %s", airportCode, day)
+	return dc.collectSchedulesForOneDay(airportCode, day, "departure")
+}
 
-	var allSchedules []aviation_edge.ScheduleResponse
+func (dc *DataCollector) CollectArrivalSchedulesForOneDay(airportCode string, day string) error {
+	return dc.collectSchedulesForOneDay(airportCode, day, "arrival")
+}
+
+// collectSchedulesForOneDay fetches the schedules of the given type ("departure" or "arrival"),
+// for arrivals the day is the local arrival day of the airport
+func (dc *DataCollector) collectSchedulesForOneDay(airportCode string, day string, scheduleType string) error {
+	log.Printf("Collecting current %s schedules for airport: %s, day %s", scheduleType, airportCode, day)
 
-	// Collect departure schedules
-	log.Printf("Fetching departure schedules...")
+	log.Printf("Fetching %s schedules...", scheduleType)
 	schedules, err := dc.apiClient.GetFutureSchedules(aviation_edge.FutureSchedulesParams{
 		IataCode: airportCode,
-		Type:     "departure",
+		Type:     scheduleType,
 		Date:     day,
 	})
 
-	nexDay := day
+	if errors.Is(err, aviation_edge.ErrNoData) {
+		log.Printf("No %s schedules for airport %s, day %s", scheduleType, airportCode, day)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s schedules: %w", scheduleType, err)
+	}
+
+	return dc.consumeSchedules(schedules, day, scheduleType, "future")
+}
+
+// CollectHistoricalSchedules collects the past departures of the airport with their actual times and delays
+func (dc *DataCollector) CollectHistoricalSchedules(airportCode string, dateFrom, dateTo string) error {
+	return dc.collectSchedules(airportCode, dateFrom, dateTo, dc.CollectHistoricalSchedulesForOneDay)
+}
+
+func (dc *DataCollector) CollectHistoricalSchedulesForOneDay(airportCode string, day string) error {
+	log.Printf("Collecting historical departure schedules for airport: %s, day %s", airportCode, day)
+
+	schedules, err := dc.apiClient.GetHistoricalSchedules(aviation_edge.HistoricalSchedulesParams{
+		Code:     airportCode,
+		Type:     "departure",
+		DateFrom: day,
+		DateTo:   day,
+	})
+
+	if errors.Is(err, aviation_edge.ErrNoData) {
+		log.Printf("No historical schedules for airport %s, day %s", airportCode, day)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get historical schedules: %w", err)
+	}
+
+	// the statuses of the flights (landed, cancelled, ...) are kept
+	return dc.consumeSchedules(schedules, day, "departure", "")
+}
+
+// consumeSchedules normalizes the times of the fetched schedules and passes them to the consumer.
+// An empty status keeps the statuses returned by the API.
+func (dc *DataCollector) consumeSchedules(schedules []aviation_edge.ScheduleResponse, day string, scheduleType string, status string) error {
+	var allSchedules []aviation_edge.ScheduleResponse
 
-	timeDay, err := time.Parse(time.DateOnly, day)
-	if err == nil {
-		nextTimeDay := timeDay.AddDate(0, 0, 1)
-		nexDay = nextTimeDay.Format(time.DateOnly)
+	normalizer, err := dc.getScheduleTimeNormalizer()
+	if err != nil {
+		return err
 	}
 
-	schedules = util.ArrayMap(schedules, func(s aviation_edge.ScheduleResponse) aviation_edge.ScheduleResponse {
-		s.Type = "departure"
-		s.Status = "future"
-		s.Departure.ScheduledTime = day + " " + s.Departure.ScheduledTime
+	normalizedSchedules := make([]aviation_edge.ScheduleResponse, 0, len(schedules))
+	for _, s := range schedules {
+		s.Type = scheduleType
+		if status != "" {
+			s.Status = status
+		}
 
-		arrivalDay := day
+		if scheduleType == "arrival" {
+			err = normalizer.NormalizeArrival(&s, day)
+		} else {
+			err = normalizer.Normalize(&s, day)
+		}
+		if err != nil {
+			log.Printf("Skipping flight %s %s → %s: %v", s.Flight.IataNumber, s.Departure.IataCode, s.Arrival.IataCode, err)
+			continue
+		}
 
-		if s.Arrival.ScheduledTime < s.Departure.ScheduledTime {
-			arrivalDay = nexDay
+		// the consumer takes the schedules of one airport day
+		localTime := s.Departure.ScheduledTimeLocal
+		if scheduleType == "arrival" {
+			localTime = s.Arrival.ScheduledTimeLocal
+		}
+		if !strings.HasPrefix(localTime, day) {
+			log.Printf("Skipping flight %s %s → %s of another day %s", s.Flight.IataNumber, s.Departure.IataCode, s.Arrival.IataCode, localTime)
+			continue
 		}
 
-		s.Arrival.ScheduledTime = arrivalDay + " " + s.Arrival.ScheduledTime
+		if s.Status == "" {
+			s.Status = "-"
+		}
 
 		if s.Airline.Name == "" {
 			s.Airline.Name = "-"
@@ -98,13 +299,12 @@ func (dc *DataCollector) CollectDepartureSchedulesForOneDay(airportCode string,
 		if s.Flight.IataNumber == "" {
 			s.Flight.IataNumber = "-"
 		}
-		return s
-	})
 
-	if err != nil {
-		return fmt.Errorf("failed to get departure schedules: %w", err)
+		normalizedSchedules = append(normalizedSchedules, s)
 	}
-	log.Printf("Found %d departure schedules", len(schedules))
+	schedules = normalizedSchedules
+
+	log.Printf("Found %d %s schedules", len(schedules), scheduleType)
 	allSchedules = append(allSchedules, schedules...)
 
 	// Consume all collected schedules
@@ -123,6 +323,15 @@ func (dc *DataCollector) CollectDepartureSchedulesForOneDay(airportCode string,
 // InitializeEuropeanAirportsMeta creates metadata records for all European airports
 // It uses the European countries constant to find airports and initializes their metadata
 func (dc *DataCollector) InitializeEuropeanAirportsMeta() error {
+	return dc.initializeEuropeanAirportsMeta(dc.airportsMetaDao)
+}
+
+// InitializeEuropeanAirportsHistoryMeta creates the historical schedules metadata records for all European airports
+func (dc *DataCollector) InitializeEuropeanAirportsHistoryMeta() error {
+	return dc.initializeEuropeanAirportsMeta(dc.airportsHistoryMetaDao)
+}
+
+func (dc *DataCollector) initializeEuropeanAirportsMeta(airportsMetaDao *dao.AirportsMetaDao) error {
 	log.Println("Initializing metadata for European airports...")
 
 	// Get airports from European countries
@@ -141,7 +350,7 @@ func (dc *DataCollector) InitializeEuropeanAirportsMeta() error {
 			ImportedTo:   nil,
 		}
 
-		err := dc.airportsMetaDao.Upsert(meta, false)
+		err := airportsMetaDao.Upsert(meta, false)
 		if err != nil {
 			return fmt.Errorf("failed to upsert metadata for airport %s: %w", airport.CodeIataAirport, err)
 		}
@@ -0,0 +1,106 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/aviation_edge/fake"
+	"testing"
+)
+
+// newFakeCollector creates a collector of the fake server, which does not need the database
+// as the airports timezones are given
+func newFakeCollector(data fake.Data, consumer aviation_edge.ScheduleConsumer) (*DataCollector, *fake.Server) {
+	server := fake.NewServer("test-key", data)
+
+	var airports []*aviation_edge.AirportResponse
+	for i := range data.Airports {
+		airports = append(airports, &data.Airports[i])
+	}
+
+	collector := NewDataCollector(server.NewClient(), consumer, nil, nil, nil)
+	collector.scheduleTimeNormalizer = NewScheduleTimeNormalizer(NewAirportTimezones(airports))
+
+	return collector, server
+}
+
+func TestDataCollector_Collect(t *testing.T) {
+	vnoRix := aviation_edge.ScheduleResponse{
+		Departure: aviation_edge.Departure{IataCode: "VNO", ScheduledTime: "23:30"},
+		Arrival:   aviation_edge.Arrival{IataCode: "RIX", ScheduledTime: "00:30"},
+		Flight:    aviation_edge.Flight{Number: "344", IataNumber: "BT344"},
+	}
+	landed := vnoRix
+	landed.Status = "landed"
+	landed.Departure.ScheduledTime = "2025-12-01t23:30:00.000"
+	landed.Departure.ActualTime = "2025-12-01t23:45:00.000"
+	landed.Departure.Delay = aviation_edge.DelayValue{Value: "15"}
+	landed.Arrival.ScheduledTime = "2025-12-02t00:30:00.000"
+
+	data := fake.Data{
+		Airports: []aviation_edge.AirportResponse{
+			{CodeIataAirport: "VNO", Timezone: "Europe/Vilnius"},
+			{CodeIataAirport: "RIX", Timezone: "Europe/Riga"},
+		},
+		Future:  map[string][]aviation_edge.ScheduleResponse{"2026-01-15": {vnoRix}},
+		History: []aviation_edge.ScheduleResponse{landed},
+	}
+
+	t.Run("departures, days without flights are skipped", func(t *testing.T) {
+		consumer := &aviation_edge.ArrayScheduleConsumer{}
+		collector, server := newFakeCollector(data, consumer)
+		defer server.Close()
+
+		err := collector.CollectDepartureSchedules("VNO", "2026-01-15", "2026-01-16")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(consumer.Schedules) != 1 {
+			t.Fatalf("Expected 1 schedule, got %d", len(consumer.Schedules))
+		}
+		s := consumer.Schedules[0]
+		if s.Status != "future" || s.Departure.ScheduledTime != "2026-01-15 21:30:00" || s.Arrival.ScheduledTime != "2026-01-15 22:30:00" {
+			t.Errorf("Expected future 2026-01-15 21:30:00 → 22:30:00, got %s %s → %s", s.Status, s.Departure.ScheduledTime, s.Arrival.ScheduledTime)
+		}
+		if s.Airline.Name != "-" {
+			t.Errorf("Expected missing airline name replaced by -, got %q", s.Airline.Name)
+		}
+	})
+
+	t.Run("arrivals are dated by the arrival day", func(t *testing.T) {
+		consumer := &aviation_edge.ArrayScheduleConsumer{}
+		collector, server := newFakeCollector(data, consumer)
+		defer server.Close()
+
+		err := collector.CollectArrivalSchedules("RIX", "2026-01-15", "2026-01-15")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(consumer.Schedules) != 1 {
+			t.Fatalf("Expected 1 schedule, got %d", len(consumer.Schedules))
+		}
+		if consumer.Schedules[0].Departure.ScheduledTimeLocal != "2026-01-14 23:30:00" {
+			t.Errorf("Expected local departure 2026-01-14 23:30:00, got %s", consumer.Schedules[0].Departure.ScheduledTimeLocal)
+		}
+	})
+
+	t.Run("history keeps statuses and delays", func(t *testing.T) {
+		consumer := &aviation_edge.ArrayScheduleConsumer{}
+		collector, server := newFakeCollector(data, consumer)
+		defer server.Close()
+
+		err := collector.CollectHistoricalSchedules("VNO", "2025-12-01", "2025-12-01")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(consumer.Schedules) != 1 {
+			t.Fatalf("Expected 1 schedule, got %d", len(consumer.Schedules))
+		}
+		s := consumer.Schedules[0]
+		if s.Status != "landed" || s.Departure.ActualTime != "2025-12-01 21:45:00" || s.Departure.Delay.Value != "15" {
+			t.Errorf("Expected landed at 2025-12-01 21:45:00 delayed by 15, got %s at %s delayed by %s",
+				s.Status, s.Departure.ActualTime, s.Departure.Delay.Value)
+		}
+	})
+}
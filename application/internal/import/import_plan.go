@@ -0,0 +1,69 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/util"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateRange is an inclusive range of days in YYYY-MM-DD format
+type DateRange struct {
+	From string
+	To   string
+}
+
+func (r DateRange) String() string {
+	if r.From == r.To {
+		return r.From
+	}
+
+	return r.From + ".." + r.To
+}
+
+// ImportPlan contains the plan for importing airport data
+type ImportPlan struct {
+	SkipImport bool
+	// MissingDays are the days of the requested range not imported yet, Gaps are the same days as ranges
+	MissingDays []string
+	Gaps        []DateRange
+	CoveredDays int
+}
+
+// GapsString lists the gaps separated by commas
+func (plan *ImportPlan) GapsString() string {
+	gaps := make([]string, len(plan.Gaps))
+	for i, gap := range plan.Gaps {
+		gaps[i] = gap.String()
+	}
+
+	return strings.Join(gaps, ", ")
+}
+
+// CalculateImportPlan determines the days of the requested range to import by the days already imported
+func CalculateImportPlan(startDate, endDate string, importedDays map[string]bool) (*ImportPlan, error) {
+	days, err := util.GenerateDateRange(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import range: %w", err)
+	}
+
+	plan := &ImportPlan{}
+
+	for _, day := range days {
+		if importedDays[day] {
+			plan.CoveredDays++
+			continue
+		}
+
+		plan.MissingDays = append(plan.MissingDays, day)
+		last := len(plan.Gaps) - 1
+		if last >= 0 && plan.Gaps[last].To == util.ParseDate(day).AddDate(0, 0, -1).Format(time.DateOnly) {
+			plan.Gaps[last].To = day
+		} else {
+			plan.Gaps = append(plan.Gaps, DateRange{From: day, To: day})
+		}
+	}
+	plan.SkipImport = len(plan.MissingDays) == 0
+
+	return plan, nil
+}
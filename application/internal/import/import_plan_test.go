@@ -0,0 +1,47 @@
+package _import
+
+import "testing"
+
+func TestCalculateImportPlan(t *testing.T) {
+	t.Run("nothing imported imports the requested range", func(t *testing.T) {
+		plan, err := CalculateImportPlan("2025-12-01", "2025-12-10", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if plan.SkipImport {
+			t.Error("Expected import not to be skipped")
+		}
+		if len(plan.MissingDays) != 10 || plan.GapsString() != "2025-12-01..2025-12-10" {
+			t.Errorf("Expected 10 days 2025-12-01..2025-12-10, got %d days %s", len(plan.MissingDays), plan.GapsString())
+		}
+	})
+
+	t.Run("covered range is skipped", func(t *testing.T) {
+		imported := map[string]bool{"2025-12-01": true, "2025-12-02": true, "2025-12-03": true}
+		plan, _ := CalculateImportPlan("2025-12-01", "2025-12-03", imported)
+
+		if !plan.SkipImport || plan.CoveredDays != 3 {
+			t.Errorf("Expected import of 3 covered days to be skipped, got %d covered", plan.CoveredDays)
+		}
+	})
+
+	t.Run("only the missing days are imported", func(t *testing.T) {
+		imported := map[string]bool{"2025-12-01": true, "2025-12-04": true, "2025-12-05": true, "2025-12-07": true}
+		plan, _ := CalculateImportPlan("2025-12-01", "2025-12-08", imported)
+
+		if plan.GapsString() != "2025-12-02..2025-12-03, 2025-12-06, 2025-12-08" {
+			t.Errorf("Expected gaps 2025-12-02..2025-12-03, 2025-12-06, 2025-12-08, got %s", plan.GapsString())
+		}
+		if len(plan.MissingDays) != 4 || plan.CoveredDays != 4 {
+			t.Errorf("Expected 4 missing and 4 covered days, got %d and %d", len(plan.MissingDays), plan.CoveredDays)
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := CalculateImportPlan("2025-12-10", "2025-12-01", nil)
+		if err == nil {
+			t.Error("Expected error for the end before the start")
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/dao"
+	"fmt"
+)
+
+// BackfillLocalTimes converts the flight schedules stored before the times were normalised to UTC: their local times
+// are copied to the local fields and the times are converted to UTC using the airport timezones, one departure airport
+// at a time. The records of airports of unknown timezones are left, the bridge skips them. It returns the amounts
+// of converted and left records.
+func BackfillLocalTimes(schedulesDao *dao.AviationEdgeFlightSchedulesDao, airportsDao *dao.AirportsDao) (int, int, error) {
+	codes, err := schedulesDao.FindDepartureAirportsWithoutLocalTimes()
+	if err != nil || len(codes) == 0 {
+		return 0, 0, err
+	}
+
+	airports, err := airportsDao.GetAll()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load airports timezones: %w", err)
+	}
+	normalizer := NewScheduleTimeNormalizer(NewAirportTimezones(airports))
+
+	converted, left := 0, 0
+	for _, code := range codes {
+		originals, err := schedulesDao.FindWithoutLocalTimes(code)
+		if err != nil {
+			return converted, left, err
+		}
+
+		var convertible, normalized []*aviation_edge.ScheduleResponse
+		for _, original := range originals {
+			schedule := *original
+			err = normalizer.NormalizeStored(&schedule)
+			if err != nil {
+				left++
+				continue
+			}
+			convertible = append(convertible, original)
+			normalized = append(normalized, &schedule)
+		}
+
+		err = schedulesDao.ReplaceWithNormalized(convertible, normalized)
+		if err != nil {
+			return converted, left, err
+		}
+		converted += len(convertible)
+	}
+
+	return converted, left, nil
+}
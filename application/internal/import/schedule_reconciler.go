@@ -0,0 +1,175 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"sort"
+)
+
+// ScheduleInconsistency is a field having different values in the departure and the arrival record of a flight
+type ScheduleInconsistency struct {
+	FlightIataNumber string
+	DepIataCode      string
+	ArrIataCode      string
+	Date             string
+	Field            string
+	DepartureValue   string
+	ArrivalValue     string
+}
+
+// UnmatchedFlights are the flights of an airport which were collected at the other end of the flight only
+type UnmatchedFlights struct {
+	// MissingDepartures are arrival records not found in the departures of the airport
+	MissingDepartures []*aviation_edge.ScheduleResponse
+	// MissingArrivals are departure records not found in the arrivals of the airport
+	MissingArrivals []*aviation_edge.ScheduleResponse
+}
+
+type ReconciliationReport struct {
+	Matched         int
+	Updated         []*aviation_edge.ScheduleResponse
+	Inconsistencies []*ScheduleInconsistency
+	// Unmatched is keyed by the airport IATA code
+	Unmatched map[string]*UnmatchedFlights
+}
+
+// Airports returns the codes of the airports having unmatched flights in alphabetical order
+func (r *ReconciliationReport) Airports() []string {
+	airports := make([]string, 0, len(r.Unmatched))
+	for airport := range r.Unmatched {
+		airports = append(airports, airport)
+	}
+	sort.Strings(airports)
+
+	return airports
+}
+
+func (r *ReconciliationReport) unmatchedOf(airport string) *UnmatchedFlights {
+	unmatched, ok := r.Unmatched[airport]
+	if !ok {
+		unmatched = &UnmatchedFlights{}
+		r.Unmatched[airport] = unmatched
+	}
+
+	return unmatched
+}
+
+// reconciledField is a field present in both records of a flight.
+// Compared fields are reported when the values differ, the others only fill the gaps.
+type reconciledField struct {
+	name      string
+	departure *string
+	arrival   *string
+	compared  bool
+}
+
+func reconciledFields(d, a *aviation_edge.ScheduleResponse) []reconciledField {
+	return []reconciledField{
+		{"dep_scheduled_time", &d.Departure.ScheduledTime, &a.Departure.ScheduledTime, true},
+		{"dep_terminal", &d.Departure.Terminal, &a.Departure.Terminal, true},
+		{"dep_gate", &d.Departure.Gate, &a.Departure.Gate, true},
+		{"arr_scheduled_time", &d.Arrival.ScheduledTime, &a.Arrival.ScheduledTime, true},
+		{"arr_terminal", &d.Arrival.Terminal, &a.Arrival.Terminal, true},
+		{"arr_gate", &d.Arrival.Gate, &a.Arrival.Gate, true},
+		{"arr_baggage", &d.Arrival.Baggage, &a.Arrival.Baggage, true},
+		{"aircraft_icao_code", &d.Aircraft.IcaoCode, &a.Aircraft.IcaoCode, true},
+		{"dep_icao_code", &d.Departure.IcaoCode, &a.Departure.IcaoCode, false},
+		{"dep_scheduled_time_local", &d.Departure.ScheduledTimeLocal, &a.Departure.ScheduledTimeLocal, false},
+		{"dep_estimated_time", &d.Departure.EstimatedTime, &a.Departure.EstimatedTime, false},
+		{"dep_estimated_time_local", &d.Departure.EstimatedTimeLocal, &a.Departure.EstimatedTimeLocal, false},
+		{"dep_actual_time", &d.Departure.ActualTime, &a.Departure.ActualTime, false},
+		{"dep_actual_time_local", &d.Departure.ActualTimeLocal, &a.Departure.ActualTimeLocal, false},
+		{"arr_icao_code", &d.Arrival.IcaoCode, &a.Arrival.IcaoCode, false},
+		{"arr_scheduled_time_local", &d.Arrival.ScheduledTimeLocal, &a.Arrival.ScheduledTimeLocal, false},
+		{"arr_estimated_time", &d.Arrival.EstimatedTime, &a.Arrival.EstimatedTime, false},
+		{"arr_estimated_time_local", &d.Arrival.EstimatedTimeLocal, &a.Arrival.EstimatedTimeLocal, false},
+		{"arr_actual_time", &d.Arrival.ActualTime, &a.Arrival.ActualTime, false},
+		{"arr_actual_time_local", &d.Arrival.ActualTimeLocal, &a.Arrival.ActualTimeLocal, false},
+		{"aircraft_reg_number", &d.Aircraft.RegNumber, &a.Aircraft.RegNumber, false},
+	}
+}
+
+// reconciliationKey matches the records of a flight by its operating number, airports and local departure date.
+// The date is used instead of the time, so that the records differing in times are matched and reported.
+func reconciliationKey(s *aviation_edge.ScheduleResponse) string {
+	return s.OperatingFlightIataNumber() + "|" + s.Departure.IataCode + "|" + s.Arrival.IataCode + "|" + departureDate(s)
+}
+
+func departureDate(s *aviation_edge.ScheduleResponse) string {
+	date := s.Departure.ScheduledTimeLocal
+	if date == "" {
+		date = s.Departure.ScheduledTime
+	}
+	if len(date) > 10 {
+		date = date[:10]
+	}
+
+	return date
+}
+
+// ReconcileSchedules matches the departure type records with the arrival type records of the same flights.
+// The gaps of the matched records are filled from each other and the changed records are returned as Updated,
+// the differing values are reported as inconsistencies. Records without a pair are reported for the airport
+// which schedules miss them: the departure airport for arrival records and the arrival airport for departure records.
+func ReconcileSchedules(departures, arrivals []*aviation_edge.ScheduleResponse) *ReconciliationReport {
+	report := &ReconciliationReport{Unmatched: make(map[string]*UnmatchedFlights)}
+
+	arrivalsByKey := make(map[string]*aviation_edge.ScheduleResponse, len(arrivals))
+	for _, arrival := range arrivals {
+		key := reconciliationKey(arrival)
+		if _, exists := arrivalsByKey[key]; !exists {
+			arrivalsByKey[key] = arrival
+		}
+	}
+
+	matchedKeys := make(map[string]bool)
+	for _, departure := range departures {
+		key := reconciliationKey(departure)
+		arrival, ok := arrivalsByKey[key]
+		if !ok || matchedKeys[key] {
+			unmatched := report.unmatchedOf(departure.Arrival.IataCode)
+			unmatched.MissingArrivals = append(unmatched.MissingArrivals, departure)
+			continue
+		}
+		matchedKeys[key] = true
+		report.Matched++
+
+		departureChanged, arrivalChanged := false, false
+		for _, field := range reconciledFields(departure, arrival) {
+			switch {
+			case *field.departure == *field.arrival:
+			case *field.departure == "":
+				*field.departure = *field.arrival
+				departureChanged = true
+			case *field.arrival == "":
+				*field.arrival = *field.departure
+				arrivalChanged = true
+			case field.compared:
+				report.Inconsistencies = append(report.Inconsistencies, &ScheduleInconsistency{
+					FlightIataNumber: departure.OperatingFlightIataNumber(),
+					DepIataCode:      departure.Departure.IataCode,
+					ArrIataCode:      departure.Arrival.IataCode,
+					Date:             departureDate(departure),
+					Field:            field.name,
+					DepartureValue:   *field.departure,
+					ArrivalValue:     *field.arrival,
+				})
+			}
+		}
+
+		if departureChanged {
+			report.Updated = append(report.Updated, departure)
+		}
+		if arrivalChanged {
+			report.Updated = append(report.Updated, arrival)
+		}
+	}
+
+	for _, arrival := range arrivals {
+		if !matchedKeys[reconciliationKey(arrival)] {
+			unmatched := report.unmatchedOf(arrival.Departure.IataCode)
+			unmatched.MissingDepartures = append(unmatched.MissingDepartures, arrival)
+		}
+	}
+
+	return report
+}
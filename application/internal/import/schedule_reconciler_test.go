@@ -0,0 +1,85 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"testing"
+)
+
+func makeStoredSchedule(scheduleType, flight, from, departure, to, arrival string) *aviation_edge.ScheduleResponse {
+	return &aviation_edge.ScheduleResponse{
+		Type:      scheduleType,
+		Departure: aviation_edge.Departure{IataCode: from, ScheduledTime: departure, ScheduledTimeLocal: departure},
+		Arrival:   aviation_edge.Arrival{IataCode: to, ScheduledTime: arrival, ScheduledTimeLocal: arrival},
+		Flight:    aviation_edge.Flight{IataNumber: flight},
+	}
+}
+
+func TestReconcileSchedules(t *testing.T) {
+	t.Run("fills gaps of both records", func(t *testing.T) {
+		departure := makeStoredSchedule("departure", "BT341", "VNO", "2026-01-15 08:00:00", "RIX", "2026-01-15 09:00:00")
+		departure.Departure.Terminal = "A"
+		arrival := makeStoredSchedule("arrival", "bt341", "VNO", "2026-01-15 08:00:00", "RIX", "2026-01-15 09:00:00")
+		arrival.Arrival.Terminal = "1"
+		arrival.Arrival.Baggage = "3"
+
+		report := ReconcileSchedules([]*aviation_edge.ScheduleResponse{departure}, []*aviation_edge.ScheduleResponse{arrival})
+
+		if report.Matched != 1 {
+			t.Errorf("Expected 1 matched flight, got %d", report.Matched)
+		}
+		if departure.Arrival.Terminal != "1" || departure.Arrival.Baggage != "3" {
+			t.Errorf("Expected arrival terminal 1 and baggage 3, got %s and %s", departure.Arrival.Terminal, departure.Arrival.Baggage)
+		}
+		if arrival.Departure.Terminal != "A" {
+			t.Errorf("Expected departure terminal A, got %s", arrival.Departure.Terminal)
+		}
+		if len(report.Updated) != 2 {
+			t.Errorf("Expected 2 updated records, got %d", len(report.Updated))
+		}
+		if len(report.Inconsistencies) != 0 {
+			t.Errorf("Expected no inconsistencies, got %d", len(report.Inconsistencies))
+		}
+	})
+
+	t.Run("reports differing values", func(t *testing.T) {
+		departure := makeStoredSchedule("departure", "BT341", "VNO", "2026-01-15 08:00:00", "RIX", "2026-01-15 09:00:00")
+		arrival := makeStoredSchedule("arrival", "BT341", "VNO", "2026-01-15 08:00:00", "RIX", "2026-01-15 09:10:00")
+
+		report := ReconcileSchedules([]*aviation_edge.ScheduleResponse{departure}, []*aviation_edge.ScheduleResponse{arrival})
+
+		if len(report.Updated) != 0 {
+			t.Errorf("Expected no updated records, got %d", len(report.Updated))
+		}
+		if len(report.Inconsistencies) != 1 {
+			t.Fatalf("Expected 1 inconsistency, got %d", len(report.Inconsistencies))
+		}
+		inconsistency := report.Inconsistencies[0]
+		if inconsistency.Field != "arr_scheduled_time" || inconsistency.ArrivalValue != "2026-01-15 09:10:00" {
+			t.Errorf("Expected arr_scheduled_time 2026-01-15 09:10:00, got %s %s", inconsistency.Field, inconsistency.ArrivalValue)
+		}
+	})
+
+	t.Run("reports unmatched flights per airport", func(t *testing.T) {
+		departures := []*aviation_edge.ScheduleResponse{
+			makeStoredSchedule("departure", "BT341", "VNO", "2026-01-15 08:00:00", "RIX", "2026-01-15 09:00:00"),
+			makeStoredSchedule("departure", "BT341", "VNO", "2026-01-16 08:00:00", "RIX", "2026-01-16 09:00:00"),
+		}
+		arrivals := []*aviation_edge.ScheduleResponse{
+			makeStoredSchedule("arrival", "BT341", "VNO", "2026-01-15 08:00:00", "RIX", "2026-01-15 09:00:00"),
+			makeStoredSchedule("arrival", "LO771", "WAW", "2026-01-15 10:00:00", "RIX", "2026-01-15 12:00:00"),
+		}
+
+		report := ReconcileSchedules(departures, arrivals)
+
+		airports := report.Airports()
+		if len(airports) != 2 || airports[0] != "RIX" || airports[1] != "WAW" {
+			t.Fatalf("Expected airports [RIX WAW], got %v", airports)
+		}
+		if len(report.Unmatched["RIX"].MissingArrivals) != 1 {
+			t.Errorf("Expected 1 missing arrival at RIX, got %d", len(report.Unmatched["RIX"].MissingArrivals))
+		}
+		if len(report.Unmatched["WAW"].MissingDepartures) != 1 {
+			t.Errorf("Expected 1 missing departure at WAW, got %d", len(report.Unmatched["WAW"].MissingDepartures))
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // airport timezones must be resolvable on hosts without zoneinfo
+)
+
+// scheduleTimeFormats are the formats of the times returned by the schedule endpoints
+var scheduleTimeFormats = []string{"2006-01-02T15:04:05.000", "2006-01-02T15:04:05", time.DateTime}
+
+// AirportTimezones resolves the location of an airport by its IATA code,
+// using the IANA timezone of the airport or its GMT offset when the timezone is unknown
+type AirportTimezones struct {
+	locations map[string]*time.Location
+}
+
+func NewAirportTimezones(airports []*aviation_edge.AirportResponse) *AirportTimezones {
+	locations := make(map[string]*time.Location)
+	for _, airport := range airports {
+		location, err := time.LoadLocation(airport.Timezone)
+		if airport.Timezone == "" || err != nil {
+			var ok bool
+			location, ok = ParseGMTOffset(airport.GMT)
+			if !ok {
+				continue
+			}
+		}
+		locations[airport.CodeIataAirport] = location
+	}
+
+	return &AirportTimezones{locations: locations}
+}
+
+func (t *AirportTimezones) Location(iataCode string) (*time.Location, bool) {
+	location, ok := t.locations[iataCode]
+	return location, ok
+}
+
+// ParseGMTOffset converts offsets like "+2", "-3.5" or "5.75" in hours to a fixed zone
+func ParseGMTOffset(gmt string) (*time.Location, bool) {
+	if gmt == "" {
+		return nil, false
+	}
+
+	hours, err := strconv.ParseFloat(strings.TrimPrefix(gmt, "+"), 64)
+	if err != nil {
+		return nil, false
+	}
+
+	return time.FixedZone("GMT"+gmt, int(hours*3600)), true
+}
+
+// ScheduleTimeNormalizer converts the local times of a schedule to UTC keeping the local ones too.
+// The future schedules endpoint returns times as "HH:MM" without a date, then the departure date
+// is the requested day and the arrival date is the one giving the earliest arrival after the departure,
+// which handles overnight, multi-day and date line crossing flights. Arrival schedules are dated the other
+// way round: the arrival date is the requested day and the departure is the latest one before the arrival.
+type ScheduleTimeNormalizer struct {
+	timezones *AirportTimezones
+}
+
+func NewScheduleTimeNormalizer(timezones *AirportTimezones) *ScheduleTimeNormalizer {
+	return &ScheduleTimeNormalizer{timezones: timezones}
+}
+
+// Normalize sets scheduled, estimated and actual times of the schedule to UTC and their *Local
+// counterparts to the local time of the airport. Day is the local departure day (YYYY-MM-DD).
+func (n *ScheduleTimeNormalizer) Normalize(s *aviation_edge.ScheduleResponse, day string) error {
+	return n.normalize(s, day, false)
+}
+
+// NormalizeArrival normalizes an arrival type schedule, for which day is the local arrival day (YYYY-MM-DD)
+// and the departure date is the one giving the latest departure before the arrival.
+func (n *ScheduleTimeNormalizer) NormalizeArrival(s *aviation_edge.ScheduleResponse, day string) error {
+	return n.normalize(s, day, true)
+}
+
+// NormalizeStored normalizes a schedule stored before the times were normalised to UTC, which holds the full local
+// times of the airports in the UTC fields
+func (n *ScheduleTimeNormalizer) NormalizeStored(s *aviation_edge.ScheduleResponse) error {
+	day, _, _ := strings.Cut(s.Departure.ScheduledTime, " ")
+
+	return n.normalize(s, day, false)
+}
+
+func (n *ScheduleTimeNormalizer) normalize(s *aviation_edge.ScheduleResponse, day string, byArrivalDay bool) error {
+	departureLocation, ok := n.timezones.Location(s.Departure.IataCode)
+	if !ok {
+		return fmt.Errorf("unknown timezone of departure airport %s", s.Departure.IataCode)
+	}
+
+	arrivalLocation, ok := n.timezones.Location(s.Arrival.IataCode)
+	if !ok {
+		return fmt.Errorf("unknown timezone of arrival airport %s", s.Arrival.IataCode)
+	}
+
+	dayLocation := departureLocation
+	if byArrivalDay {
+		dayLocation = arrivalLocation
+	}
+
+	requestedDay, err := time.ParseInLocation(time.DateOnly, day, dayLocation)
+	if err != nil {
+		return err
+	}
+
+	var scheduledDeparture, scheduledArrival time.Time
+	if byArrivalDay {
+		scheduledArrival, err = parseLocalTime(s.Arrival.ScheduledTime, arrivalLocation, requestedCandidate, requestedDay)
+		if err != nil {
+			return fmt.Errorf("arrival scheduled time: %w", err)
+		}
+
+		scheduledDeparture, err = parseLocalTime(s.Departure.ScheduledTime, departureLocation, func(candidates []time.Time) time.Time {
+			return lastBefore(candidates, scheduledArrival)
+		}, requestedDay)
+		if err != nil {
+			return fmt.Errorf("departure scheduled time: %w", err)
+		}
+	} else {
+		scheduledDeparture, err = parseLocalTime(s.Departure.ScheduledTime, departureLocation, requestedCandidate, requestedDay)
+		if err != nil {
+			return fmt.Errorf("departure scheduled time: %w", err)
+		}
+
+		scheduledArrival, err = parseLocalTime(s.Arrival.ScheduledTime, arrivalLocation, func(candidates []time.Time) time.Time {
+			return firstAfter(candidates, scheduledDeparture)
+		}, requestedDay)
+		if err != nil {
+			return fmt.Errorf("arrival scheduled time: %w", err)
+		}
+	}
+	if !scheduledArrival.After(scheduledDeparture) {
+		return fmt.Errorf("arrival %s is not after departure %s", scheduledArrival.UTC(), scheduledDeparture.UTC())
+	}
+
+	s.Departure.ScheduledTime, s.Departure.ScheduledTimeLocal = formatUTCAndLocal(scheduledDeparture)
+	s.Arrival.ScheduledTime, s.Arrival.ScheduledTimeLocal = formatUTCAndLocal(scheduledArrival)
+
+	// estimated and actual times are the ones closest to the scheduled time
+	normalizeNearest := func(value *string, local *string, location *time.Location, scheduled time.Time) error {
+		if *value == "" {
+			return nil
+		}
+
+		parsed, err := parseLocalTime(*value, location, func(candidates []time.Time) time.Time {
+			return nearest(candidates, scheduled)
+		}, requestedDay)
+		if err != nil {
+			return err
+		}
+
+		if local == nil {
+			*value, _ = formatUTCAndLocal(parsed)
+		} else {
+			*value, *local = formatUTCAndLocal(parsed)
+		}
+
+		return nil
+	}
+
+	for _, field := range []struct {
+		value     *string
+		local     *string
+		location  *time.Location
+		scheduled time.Time
+	}{
+		{&s.Departure.EstimatedTime, &s.Departure.EstimatedTimeLocal, departureLocation, scheduledDeparture},
+		{&s.Departure.ActualTime, &s.Departure.ActualTimeLocal, departureLocation, scheduledDeparture},
+		{&s.Departure.EstimatedRunway, nil, departureLocation, scheduledDeparture},
+		{&s.Departure.ActualRunway, nil, departureLocation, scheduledDeparture},
+		{&s.Arrival.EstimatedTime, &s.Arrival.EstimatedTimeLocal, arrivalLocation, scheduledArrival},
+		{&s.Arrival.ActualTime, &s.Arrival.ActualTimeLocal, arrivalLocation, scheduledArrival},
+		{&s.Arrival.EstimatedRunway, nil, arrivalLocation, scheduledArrival},
+		{&s.Arrival.ActualRunway, nil, arrivalLocation, scheduledArrival},
+	} {
+		err = normalizeNearest(field.value, field.local, field.location, field.scheduled)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseLocalTime parses a full local date time, or a "HH:MM" time for which the date is chosen
+// of the candidates from two days before to two days after the requested day
+func parseLocalTime(value string, location *time.Location, choose func(candidates []time.Time) time.Time, requestedDay time.Time) (time.Time, error) {
+	// the history endpoint returns times like "2025-12-01t10:00:00.000"
+	for _, format := range scheduleTimeFormats {
+		parsed, err := time.ParseInLocation(format, strings.ToUpper(value), location)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		// the routes endpoint returns times with seconds
+		clock, err = time.Parse("15:04:05", value)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q", value)
+	}
+
+	var candidates []time.Time
+	for offset := -2; offset <= 2; offset++ {
+		date := requestedDay.AddDate(0, 0, offset)
+		candidates = append(candidates, time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, location))
+	}
+
+	return choose(candidates), nil
+}
+
+// requestedCandidate is the candidate of the requested day itself
+func requestedCandidate(candidates []time.Time) time.Time {
+	return candidates[2]
+}
+
+func firstAfter(candidates []time.Time, after time.Time) time.Time {
+	for _, candidate := range candidates {
+		if candidate.After(after) {
+			return candidate
+		}
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+func lastBefore(candidates []time.Time, before time.Time) time.Time {
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].Before(before) {
+			return candidates[i]
+		}
+	}
+
+	return candidates[0]
+}
+
+func nearest(candidates []time.Time, to time.Time) time.Time {
+	result := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.Sub(to).Abs() < result.Sub(to).Abs() {
+			result = candidate
+		}
+	}
+
+	return result
+}
+
+func formatUTCAndLocal(t time.Time) (string, string) {
+	return t.UTC().Format(time.DateTime), t.Format(time.DateTime)
+}
@@ -0,0 +1,195 @@
+package _import
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"testing"
+)
+
+func makeTimezones() *AirportTimezones {
+	return NewAirportTimezones([]*aviation_edge.AirportResponse{
+		{CodeIataAirport: "VNO", Timezone: "Europe/Vilnius"},
+		{CodeIataAirport: "JFK", Timezone: "America/New_York"},
+		{CodeIataAirport: "AKL", Timezone: "Pacific/Auckland"},
+		{CodeIataAirport: "HNL", Timezone: "Pacific/Honolulu"},
+		{CodeIataAirport: "XXX", GMT: "+5.5"},
+	})
+}
+
+func makeSchedule(from, departure, to, arrival string) *aviation_edge.ScheduleResponse {
+	return &aviation_edge.ScheduleResponse{
+		Departure: aviation_edge.Departure{IataCode: from, ScheduledTime: departure},
+		Arrival:   aviation_edge.Arrival{IataCode: to, ScheduledTime: arrival},
+	}
+}
+
+func TestScheduleTimeNormalizer_Normalize(t *testing.T) {
+	normalizer := NewScheduleTimeNormalizer(makeTimezones())
+
+	t.Run("westbound arrival earlier by local clock", func(t *testing.T) {
+		// 10:00 in Vilnius (UTC+2) is 08:00 UTC, 11:30 in New York (UTC-5) is 16:30 UTC
+		s := makeSchedule("VNO", "10:00", "JFK", "11:30")
+		err := normalizer.Normalize(s, "2026-01-15")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if s.Departure.ScheduledTime != "2026-01-15 08:00:00" {
+			t.Errorf("Expected departure 2026-01-15 08:00:00, got %s", s.Departure.ScheduledTime)
+		}
+		if s.Departure.ScheduledTimeLocal != "2026-01-15 10:00:00" {
+			t.Errorf("Expected local departure 2026-01-15 10:00:00, got %s", s.Departure.ScheduledTimeLocal)
+		}
+		if s.Arrival.ScheduledTime != "2026-01-15 16:30:00" {
+			t.Errorf("Expected arrival 2026-01-15 16:30:00, got %s", s.Arrival.ScheduledTime)
+		}
+	})
+
+	t.Run("overnight arrival", func(t *testing.T) {
+		s := makeSchedule("JFK", "18:00", "VNO", "09:30")
+		err := normalizer.Normalize(s, "2026-01-15")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if s.Arrival.ScheduledTimeLocal != "2026-01-16 09:30:00" {
+			t.Errorf("Expected local arrival 2026-01-16 09:30:00, got %s", s.Arrival.ScheduledTimeLocal)
+		}
+		if s.Arrival.ScheduledTime != "2026-01-16 07:30:00" {
+			t.Errorf("Expected arrival 2026-01-16 07:30:00, got %s", s.Arrival.ScheduledTime)
+		}
+	})
+
+	t.Run("date line crossing arrival earlier by local clock", func(t *testing.T) {
+		// 2026-01-15 20:00 in Auckland (UTC+13) is 07:00 UTC, 06:30 of the same date in Honolulu (UTC-10) is 16:30 UTC
+		s := makeSchedule("AKL", "20:00", "HNL", "06:30")
+		err := normalizer.Normalize(s, "2026-01-15")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if s.Arrival.ScheduledTimeLocal != "2026-01-15 06:30:00" {
+			t.Errorf("Expected local arrival 2026-01-15 06:30:00, got %s", s.Arrival.ScheduledTimeLocal)
+		}
+		if s.Departure.ScheduledTime != "2026-01-15 07:00:00" {
+			t.Errorf("Expected departure 2026-01-15 07:00:00, got %s", s.Departure.ScheduledTime)
+		}
+		if s.Arrival.ScheduledTime != "2026-01-15 16:30:00" {
+			t.Errorf("Expected arrival 2026-01-15 16:30:00, got %s", s.Arrival.ScheduledTime)
+		}
+	})
+
+	t.Run("full date times and estimated time", func(t *testing.T) {
+		s := makeSchedule("VNO", "2026-07-01T23:50:00.000", "XXX", "2026-07-02T08:00:00.000")
+		s.Departure.EstimatedTime = "00:20"
+		err := normalizer.Normalize(s, "2026-07-01")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if s.Departure.ScheduledTime != "2026-07-01 20:50:00" {
+			t.Errorf("Expected departure 2026-07-01 20:50:00, got %s", s.Departure.ScheduledTime)
+		}
+		if s.Arrival.ScheduledTime != "2026-07-02 02:30:00" {
+			t.Errorf("Expected arrival 2026-07-02 02:30:00, got %s", s.Arrival.ScheduledTime)
+		}
+		if s.Departure.EstimatedTimeLocal != "2026-07-02 00:20:00" {
+			t.Errorf("Expected estimated local departure 2026-07-02 00:20:00, got %s", s.Departure.EstimatedTimeLocal)
+		}
+	})
+
+	t.Run("historical lower case date times with actual time", func(t *testing.T) {
+		s := makeSchedule("VNO", "2025-12-01t10:00:00.000", "JFK", "2025-12-01t11:30:00.000")
+		s.Departure.ActualTime = "2025-12-01t10:25:00.000"
+		err := normalizer.Normalize(s, "2025-12-01")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if s.Departure.ActualTime != "2025-12-01 08:25:00" {
+			t.Errorf("Expected actual departure 2025-12-01 08:25:00, got %s", s.Departure.ActualTime)
+		}
+		if s.Arrival.ScheduledTime != "2025-12-01 16:30:00" {
+			t.Errorf("Expected arrival 2025-12-01 16:30:00, got %s", s.Arrival.ScheduledTime)
+		}
+	})
+
+	t.Run("unknown airport", func(t *testing.T) {
+		s := makeSchedule("VNO", "10:00", "ZZZ", "11:00")
+		err := normalizer.Normalize(s, "2026-01-15")
+		if err == nil {
+			t.Error("Expected error for unknown airport timezone")
+		}
+	})
+}
+
+func TestScheduleTimeNormalizer_NormalizeArrival(t *testing.T) {
+	normalizer := NewScheduleTimeNormalizer(makeTimezones())
+
+	t.Run("overnight departure the day before", func(t *testing.T) {
+		s := makeSchedule("JFK", "18:00", "VNO", "09:30")
+		err := normalizer.NormalizeArrival(s, "2026-01-16")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if s.Departure.ScheduledTimeLocal != "2026-01-15 18:00:00" {
+			t.Errorf("Expected local departure 2026-01-15 18:00:00, got %s", s.Departure.ScheduledTimeLocal)
+		}
+		if s.Arrival.ScheduledTime != "2026-01-16 07:30:00" {
+			t.Errorf("Expected arrival 2026-01-16 07:30:00, got %s", s.Arrival.ScheduledTime)
+		}
+	})
+
+	t.Run("same times as the departure schedule", func(t *testing.T) {
+		departure := makeSchedule("AKL", "20:00", "HNL", "06:30")
+		arrival := makeSchedule("AKL", "20:00", "HNL", "06:30")
+
+		err := normalizer.Normalize(departure, "2026-01-15")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err = normalizer.NormalizeArrival(arrival, "2026-01-15")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if arrival.Departure.ScheduledTime != departure.Departure.ScheduledTime {
+			t.Errorf("Expected departure %s, got %s", departure.Departure.ScheduledTime, arrival.Departure.ScheduledTime)
+		}
+		if arrival.Arrival.ScheduledTime != departure.Arrival.ScheduledTime {
+			t.Errorf("Expected arrival %s, got %s", departure.Arrival.ScheduledTime, arrival.Arrival.ScheduledTime)
+		}
+	})
+}
+
+func TestScheduleTimeNormalizer_NormalizeStored(t *testing.T) {
+	normalizer := NewScheduleTimeNormalizer(makeTimezones())
+
+	// a record stored with the local times of Vilnius (UTC+2) and New York (UTC-5)
+	s := makeSchedule("VNO", "2026-01-15 10:00:00", "JFK", "2026-01-15 11:30:00")
+	s.Departure.ActualTime = "2026-01-15 10:12:00"
+	err := normalizer.NormalizeStored(s)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if s.Departure.ScheduledTime != "2026-01-15 08:00:00" {
+		t.Errorf("Expected departure 2026-01-15 08:00:00, got %s", s.Departure.ScheduledTime)
+	}
+	if s.Departure.ScheduledTimeLocal != "2026-01-15 10:00:00" {
+		t.Errorf("Expected local departure 2026-01-15 10:00:00, got %s", s.Departure.ScheduledTimeLocal)
+	}
+	if s.Departure.ActualTime != "2026-01-15 08:12:00" || s.Departure.ActualTimeLocal != "2026-01-15 10:12:00" {
+		t.Errorf("Expected actual departure 2026-01-15 08:12:00 (10:12:00 local), got %s (%s)", s.Departure.ActualTime, s.Departure.ActualTimeLocal)
+	}
+	if s.Arrival.ScheduledTime != "2026-01-15 16:30:00" {
+		t.Errorf("Expected arrival 2026-01-15 16:30:00, got %s", s.Arrival.ScheduledTime)
+	}
+	if s.Arrival.ScheduledTimeLocal != "2026-01-15 11:30:00" {
+		t.Errorf("Expected local arrival 2026-01-15 11:30:00, got %s", s.Arrival.ScheduledTimeLocal)
+	}
+
+	if err = normalizer.NormalizeStored(makeSchedule("VNO", "2026-01-15 10:00:00", "ZZZ", "2026-01-15 11:30:00")); err == nil {
+		t.Errorf("Expected an error of the unknown timezone")
+	}
+}
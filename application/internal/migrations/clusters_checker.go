@@ -0,0 +1,271 @@
+package migrations
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const CLUSTER_SECONDS = 3600
+const CLUSTER8_SECONDS = 28800
+
+// ClustersTableSpec describes a clustered arrival travels table and the invariants
+// the ClustersCreator is expected to produce in it
+type ClustersTableSpec struct {
+	TableName      string
+	DepartureField string
+	ArrivalField   string
+	ClusterSeconds int
+	// ExpectedCopies is how many rows every travel must have in the table.
+	// Each copy has its arrival cluster shifted by 0..ExpectedCopies-1.
+	ExpectedCopies int
+}
+
+// GetClustersTableSpecs returns specs of all the tables created by ClustersCreator.CreateClustersTables
+func GetClustersTableSpecs() []ClustersTableSpec {
+	var specs []ClustersTableSpec
+
+	for i := 2; i <= 8; i *= 2 {
+		specs = append(specs, ClustersTableSpec{
+			TableName:      fmt.Sprintf("clustered_arrival_travels%d", i),
+			DepartureField: "departure_cl",
+			ArrivalField:   "arrival_cl",
+			ClusterSeconds: CLUSTER_SECONDS,
+			ExpectedCopies: i,
+		})
+	}
+
+	for i := 16; i <= 64; i *= 2 {
+		specs = append(specs, ClustersTableSpec{
+			TableName:      fmt.Sprintf("clustered8_arrival_travels%d", i),
+			DepartureField: "departure8_cl",
+			ArrivalField:   "arrival8_cl",
+			ClusterSeconds: CLUSTER8_SECONDS,
+			ExpectedCopies: i * CLUSTER_SECONDS / CLUSTER8_SECONDS,
+		})
+	}
+
+	return specs
+}
+
+// ClustersTableCheckResult holds the mismatches found in one clustered table
+type ClustersTableCheckResult struct {
+	Spec ClustersTableSpec
+
+	// travels which appear in the table not exactly ExpectedCopies times (or with duplicated arrival clusters)
+	WrongCountTravels    int
+	WrongCountTravelsIds []string
+	// rows which point to a travel not existing in the travels table
+	OrphanTravels    int
+	OrphanTravelsIds []string
+	// rows which points or cluster numbers do not match the travel
+	ClusterMismatches    int
+	ClusterMismatchesIds []string
+}
+
+func (r *ClustersTableCheckResult) IsConsistent() bool {
+	return r.WrongCountTravels == 0 && r.OrphanTravels == 0 && r.ClusterMismatches == 0
+}
+
+// ClustersCheckReport is the result of checking travels and all clustered tables
+type ClustersCheckReport struct {
+	TravelsCount int
+	// travels which departure_cl, arrival_cl, departure8_cl or arrival8_cl do not match their dates
+	TravelsClusterMismatches    int
+	TravelsClusterMismatchesIds []string
+	Tables                      []*ClustersTableCheckResult
+}
+
+func (r *ClustersCheckReport) IsConsistent() bool {
+	if r.TravelsClusterMismatches > 0 {
+		return false
+	}
+
+	for _, table := range r.Tables {
+		if !table.IsConsistent() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ToString returns a human-readable report
+func (r *ClustersCheckReport) ToString() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Travels: %d\n", r.TravelsCount))
+	sb.WriteString(fmt.Sprintf("Travels with wrong cluster columns: %d %s\n",
+		r.TravelsClusterMismatches, formatIds(r.TravelsClusterMismatchesIds)))
+
+	for _, table := range r.Tables {
+		status := "OK"
+		if !table.IsConsistent() {
+			status = "MISMATCH"
+		}
+		sb.WriteString(fmt.Sprintf("%s (expected %d copies per travel): %s\n",
+			table.Spec.TableName, table.Spec.ExpectedCopies, status))
+		sb.WriteString(fmt.Sprintf("  wrong count travels: %d %s\n",
+			table.WrongCountTravels, formatIds(table.WrongCountTravelsIds)))
+		sb.WriteString(fmt.Sprintf("  orphan travel ids:   %d %s\n",
+			table.OrphanTravels, formatIds(table.OrphanTravelsIds)))
+		sb.WriteString(fmt.Sprintf("  cluster mismatches:  %d %s\n",
+			table.ClusterMismatches, formatIds(table.ClusterMismatchesIds)))
+	}
+
+	return sb.String()
+}
+
+func formatIds(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+
+	return "[" + strings.Join(ids, ", ") + "]"
+}
+
+// ClustersChecker verifies clustered arrival travels tables against the travels table
+type ClustersChecker struct {
+	db          *database.Database
+	sampleLimit int
+}
+
+func NewClustersChecker(db *database.Database, sampleLimit int) *ClustersChecker {
+	return &ClustersChecker{db: db, sampleLimit: sampleLimit}
+}
+
+// Check runs all the checks and returns the report.
+// An error is returned only when the checks could not be executed.
+func (checker *ClustersChecker) Check() (*ClustersCheckReport, error) {
+	dbConn, err := checker.db.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ClustersCheckReport{}
+
+	report.TravelsCount, err = queryCount(dbConn, "SELECT COUNT(*) FROM travels")
+	if err != nil {
+		return nil, err
+	}
+
+	report.TravelsClusterMismatches, report.TravelsClusterMismatchesIds, err =
+		checker.countAndSample(dbConn, checker.TravelsClusterMismatchesSQL())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spec := range GetClustersTableSpecs() {
+		log.Printf("Checking %s", spec.TableName)
+		result := &ClustersTableCheckResult{Spec: spec}
+
+		result.WrongCountTravels, result.WrongCountTravelsIds, err =
+			checker.countAndSample(dbConn, checker.WrongCountTravelsSQL(spec))
+		if err != nil {
+			return nil, err
+		}
+
+		result.OrphanTravels, result.OrphanTravelsIds, err =
+			checker.countAndSample(dbConn, checker.OrphanTravelsSQL(spec))
+		if err != nil {
+			return nil, err
+		}
+
+		result.ClusterMismatches, result.ClusterMismatchesIds, err =
+			checker.countAndSample(dbConn, checker.ClusterMismatchesSQL(spec))
+		if err != nil {
+			return nil, err
+		}
+
+		report.Tables = append(report.Tables, result)
+	}
+
+	return report, nil
+}
+
+// TravelsClusterMismatchesSQL selects ids of travels which cluster columns were not updated by UpdateClustersOnTravels
+func (checker *ClustersChecker) TravelsClusterMismatchesSQL() string {
+	return fmt.Sprintf(`select t.id from travels t
+		where t.departure_cl is null or t.arrival_cl is null
+			or t.departure8_cl is null or t.arrival8_cl is null
+			or t.departure_cl <> floor(unix_timestamp(t.departure) / %d)
+			or t.arrival_cl <> floor(unix_timestamp(t.arrival) / %d)
+			or t.departure8_cl <> floor(unix_timestamp(t.departure) / %d)
+			or t.arrival8_cl <> floor(unix_timestamp(t.arrival) / %d)`,
+		CLUSTER_SECONDS, CLUSTER_SECONDS, CLUSTER8_SECONDS, CLUSTER8_SECONDS)
+}
+
+// WrongCountTravelsSQL selects ids of travels which do not appear exactly ExpectedCopies times
+// with distinct arrival clusters
+func (checker *ClustersChecker) WrongCountTravelsSQL(spec ClustersTableSpec) string {
+	return fmt.Sprintf(`select t.id from travels t
+		left join %s c on c.travel_id = t.id
+		group by t.id
+		having count(c.travel_id) <> %d or count(distinct c.%s) <> %d`,
+		spec.TableName, spec.ExpectedCopies, spec.ArrivalField, spec.ExpectedCopies)
+}
+
+// OrphanTravelsSQL selects travel ids of the clustered table which do not exist in travels
+func (checker *ClustersChecker) OrphanTravelsSQL(spec ClustersTableSpec) string {
+	return fmt.Sprintf(`select distinct c.travel_id from %s c
+		left join travels t on t.id = c.travel_id
+		where t.id is null`, spec.TableName)
+}
+
+// ClusterMismatchesSQL selects travel ids which clustered rows have points or cluster numbers
+// different from the ones calculated of the travel
+func (checker *ClustersChecker) ClusterMismatchesSQL(spec ClustersTableSpec) string {
+	return fmt.Sprintf(`select distinct c.travel_id from %s c
+		join travels t on t.id = c.travel_id
+		where c.from_point <> t.from_point
+			or c.to_point <> t.to_point
+			or c.%s <> floor(unix_timestamp(t.departure) / %d)
+			or c.%s < floor(unix_timestamp(t.arrival) / %d)
+			or c.%s > floor(unix_timestamp(t.arrival) / %d) + %d`,
+		spec.TableName,
+		spec.DepartureField, spec.ClusterSeconds,
+		spec.ArrivalField, spec.ClusterSeconds,
+		spec.ArrivalField, spec.ClusterSeconds, spec.ExpectedCopies-1)
+}
+
+// countAndSample counts rows of the given ids query and loads up to sampleLimit of the ids
+func (checker *ClustersChecker) countAndSample(dbConn *sql.DB, idsSql string) (int, []string, error) {
+	count, err := queryCount(dbConn, fmt.Sprintf("select count(*) from (%s) x", idsSql))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if count == 0 || checker.sampleLimit <= 0 {
+		return count, nil, nil
+	}
+
+	rows, err := dbConn.Query(fmt.Sprintf("%s limit %d", idsSql, checker.sampleLimit))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to sample ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return 0, nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return count, ids, rows.Err()
+}
+
+func queryCount(dbConn *sql.DB, sqlQuery string) (int, error) {
+	var count int
+	err := dbConn.QueryRow(sqlQuery).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count : %w for sql %s", err, sqlQuery)
+	}
+
+	return count, nil
+}
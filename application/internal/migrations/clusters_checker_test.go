@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetClustersTableSpecs(t *testing.T) {
+	expected := map[string]int{
+		"clustered_arrival_travels2":   2,
+		"clustered_arrival_travels4":   4,
+		"clustered_arrival_travels8":   8,
+		"clustered8_arrival_travels16": 2,
+		"clustered8_arrival_travels32": 4,
+		"clustered8_arrival_travels64": 8,
+	}
+
+	specs := GetClustersTableSpecs()
+	if len(specs) != len(expected) {
+		t.Fatalf("Expected %d specs, got %d", len(expected), len(specs))
+	}
+
+	for _, spec := range specs {
+		copies, ok := expected[spec.TableName]
+		if !ok {
+			t.Errorf("Unexpected table %s", spec.TableName)
+			continue
+		}
+		if spec.ExpectedCopies != copies {
+			t.Errorf("Table %s: expected %d copies, got %d", spec.TableName, copies, spec.ExpectedCopies)
+		}
+		if strings.HasPrefix(spec.TableName, "clustered8_") && spec.ClusterSeconds != CLUSTER8_SECONDS {
+			t.Errorf("Table %s: expected 8 hour clusters, got %d seconds", spec.TableName, spec.ClusterSeconds)
+		}
+	}
+}
+
+func TestClustersCheckReport_IsConsistent(t *testing.T) {
+	report := &ClustersCheckReport{
+		Tables: []*ClustersTableCheckResult{
+			{Spec: GetClustersTableSpecs()[0]},
+		},
+	}
+	if !report.IsConsistent() {
+		t.Error("Expected empty report to be consistent")
+	}
+
+	report.Tables[0].OrphanTravels = 1
+	report.Tables[0].OrphanTravelsIds = []string{"orphan"}
+	if report.IsConsistent() {
+		t.Error("Expected report with orphans to be inconsistent")
+	}
+	if !strings.Contains(report.ToString(), "[orphan]") {
+		t.Errorf("Expected report to list orphan id, got:\n%s", report.ToString())
+	}
+}
@@ -25,7 +25,7 @@ func (creator *ClustersCreator) CreateClustersTableSQL(clustersTableNumber int)
 		departure_cl int,
 		arrival_cl int,
 		index idx_from_departure_cl (from_point, departure_cl),
-		index idx_to_arrival_cl (to_point, arrival_cl) )`, clustersTableNumber)
+		index idx_to_arrival_cl (to_point, arrival_cl) ) %s`, clustersTableNumber, MaxPartitionClauseSQL("departure_cl", false))
 
 	return sql
 }
@@ -39,7 +39,7 @@ func (creator *ClustersCreator) CreateClusters8TableSQL(clustersTableNumber int)
 		departure8_cl int,
 		arrival8_cl int,
 		index idx_from_departure8_cl (from_point, departure8_cl),
-		index idx_to_arrival8_cl (to_point, arrival8_cl) )`, clustersTableNumber)
+		index idx_to_arrival8_cl (to_point, arrival8_cl) ) %s`, clustersTableNumber, MaxPartitionClauseSQL("departure8_cl", false))
 
 	return sql
 }
@@ -98,6 +98,8 @@ func (creator *ClustersCreator) InsertClusters8DataSQLs(clustersTableNumber int)
 	return []string{sqlDisableKeys, sqlInsert1, sqlInsert2, sqlEnableKeys}
 }
 
+// CreateClustersTables (re)creates all the clustered tables, partitioned by month of departure
+// for the months the travels table currently covers
 func (creator *ClustersCreator) CreateClustersTables() error {
 	dbConn, err := creator.db.GetConnection()
 	if err != nil {
@@ -124,6 +126,31 @@ func (creator *ClustersCreator) CreateClustersTables() error {
 
 		i = i * 2
 	}
+
+	return creator.createMonthPartitions()
+}
+
+// createMonthPartitions adds month partitions to the clustered tables for the travels departure range
+func (creator *ClustersCreator) createMonthPartitions() error {
+	partitionsManager := NewPartitionsManager(creator.db)
+	from, to, ok, err := partitionsManager.GetTravelsDepartureRange()
+	if err != nil {
+		return errors.New("failed to get travels departure range : " + err.Error())
+	}
+	if !ok {
+		return nil
+	}
+
+	for _, table := range GetMonthPartitionedTables() {
+		if table.TableName == TravelsPartitionedTable.TableName {
+			continue
+		}
+		err = partitionsManager.EnsureMonthPartitions(table, from, to)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
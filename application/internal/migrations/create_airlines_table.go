@@ -0,0 +1,35 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateAirlinesTable creates a table to store airlines from Aviation Edge API or OpenFlights
+func CreateAirlinesTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS airlines (
+		airline_id INT PRIMARY KEY COMMENT 'Aviation Edge id, negative OpenFlights id of the imported ones',
+		name_airline VARCHAR(128) NOT NULL COMMENT 'airline name',
+		code_iata_airline VARCHAR(3) COMMENT 'airline IATA code',
+		code_icao_airline VARCHAR(4) COMMENT 'airline ICAO code',
+		callsign VARCHAR(64) COMMENT 'radio call sign',
+		status_airline VARCHAR(32) COMMENT 'active, historical, ...',
+		type VARCHAR(64) COMMENT 'scheduled, charter, cargo, ...',
+		size_airline INT COMMENT 'fleet size',
+		age_fleet DOUBLE COMMENT 'average fleet age in years',
+		founding INT COMMENT 'founding year',
+		code_hub VARCHAR(3) COMMENT 'hub airport IATA code',
+		name_country VARCHAR(128) COMMENT 'country name',
+		code_iso2_country VARCHAR(2) COMMENT 'country ISO2 code',
+
+		KEY airlines_iata (code_iata_airline)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Airlines'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
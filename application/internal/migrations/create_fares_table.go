@@ -0,0 +1,32 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateFaresTable creates the table of the fares of the travels and of the routes between points
+func CreateFaresTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS fares (
+		id VARCHAR(64) NOT NULL PRIMARY KEY,
+		travel_id VARCHAR(64) COMMENT 'the travel of the fare, empty of the route fares',
+		from_point VARCHAR(64) COMMENT 'the departure point of the route fare',
+		to_point VARCHAR(64) COMMENT 'the arrival point of the route fare',
+		valid_from DATE COMMENT 'the first departure date of the route fare, open when empty',
+		valid_to DATE COMMENT 'the last departure date of the route fare, open when empty',
+		days_of_week VARCHAR(7) COMMENT 'ISO weekdays of the departures like 135, every day when empty',
+		amount DECIMAL(12,2) NOT NULL,
+		currency CHAR(3) NOT NULL COMMENT 'ISO 4217 code',
+
+		KEY fares_travel (travel_id),
+		KEY fares_route (from_point, to_point)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Fares of travels and routes'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateFlightCodesharesTable creates a table of marketing flight numbers of the operating flights
+// stored in flight_schedules
+func CreateFlightCodesharesTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS flight_codeshares (
+		operating_flight_iata VARCHAR(16) NOT NULL COMMENT 'flight IATA number of the operating carrier',
+		dep_iata_code VARCHAR(3) NOT NULL COMMENT 'departure airport IATA code',
+		dep_scheduled_time DATETIME NOT NULL COMMENT 'scheduled departure time in UTC',
+		marketing_flight_iata VARCHAR(16) NOT NULL COMMENT 'flight IATA number of the marketing carrier',
+		marketing_airline_iata VARCHAR(2) COMMENT 'marketing airline IATA code',
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'record creation timestamp',
+
+		PRIMARY KEY (operating_flight_iata, dep_scheduled_time, dep_iata_code, marketing_flight_iata)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Codeshare flight numbers of operating flights'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateFlightScheduleChangesTable creates a log of the changes of flight_schedules found by the repeated imports
+func CreateFlightScheduleChangesTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS flight_schedule_changes (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+
+		change_type VARCHAR(16) NOT NULL COMMENT 'added, removed, time, cancelled or aircraft',
+		type VARCHAR(32) NOT NULL COMMENT 'departure or arrival schedule',
+		flight_iata_number VARCHAR(16) NOT NULL COMMENT 'operating flight IATA number',
+		dep_iata_code VARCHAR(3) NOT NULL COMMENT 'departure airport IATA code',
+		arr_iata_code VARCHAR(3) NOT NULL COMMENT 'arrival airport IATA code',
+		dep_date DATE NOT NULL COMMENT 'local departure date of the flight',
+		field VARCHAR(32) COMMENT 'changed flight_schedules column, empty for added and removed flights',
+		before_value VARCHAR(128) COMMENT 'value before the import',
+		after_value VARCHAR(128) COMMENT 'value of the import',
+
+		imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'import time',
+
+		KEY changes_dep (dep_iata_code, imported_at),
+		KEY changes_arr (arr_iata_code, imported_at)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Changes of flight schedules between imports'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
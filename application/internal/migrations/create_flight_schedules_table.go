@@ -26,9 +26,9 @@ func CreateFlightSchedulesTable(db *database.Database) error {
 		dep_terminal VARCHAR(16) COMMENT 'departure terminal',
 		dep_gate VARCHAR(16) COMMENT 'departure gate',
 		dep_delay VARCHAR(16) COMMENT 'departure delay in minutes',
-		dep_scheduled_time DATETIME NOT NULL COMMENT 'scheduled departure time',
-		dep_estimated_time DATETIME COMMENT 'estimated departure time',
-		dep_actual_time DATETIME COMMENT 'actual departure time',
+		dep_scheduled_time DATETIME NOT NULL COMMENT 'scheduled departure time in UTC',
+		dep_estimated_time DATETIME COMMENT 'estimated departure time in UTC',
+		dep_actual_time DATETIME COMMENT 'actual departure time in UTC',
 		dep_estimated_runway DATETIME COMMENT 'estimated runway departure time',
 		dep_actual_runway DATETIME COMMENT 'actual runway departure time',
 
@@ -39,9 +39,9 @@ func CreateFlightSchedulesTable(db *database.Database) error {
 		arr_gate VARCHAR(16) COMMENT 'arrival gate',
 		arr_baggage VARCHAR(16) COMMENT 'baggage claim area',
 		arr_delay VARCHAR(16) COMMENT 'arrival delay in minutes',
-		arr_scheduled_time DATETIME NOT NULL COMMENT 'scheduled arrival time',
-		arr_estimated_time DATETIME COMMENT 'estimated arrival time',
-		arr_actual_time DATETIME COMMENT 'actual arrival time',
+		arr_scheduled_time DATETIME NOT NULL COMMENT 'scheduled arrival time in UTC',
+		arr_estimated_time DATETIME COMMENT 'estimated arrival time in UTC',
+		arr_actual_time DATETIME COMMENT 'actual arrival time in UTC',
 		arr_estimated_runway DATETIME COMMENT 'estimated runway arrival time',
 		arr_actual_runway DATETIME COMMENT 'actual runway arrival time',
 
@@ -71,15 +71,73 @@ func CreateFlightSchedulesTable(db *database.Database) error {
 		codeshared_flight_iata VARCHAR(16) COMMENT 'codeshared flight IATA number',
 		codeshared_flight_icao VARCHAR(16) COMMENT 'codeshared flight ICAO number',
 
+		-- Local times of the airports
+		dep_scheduled_time_local DATETIME COMMENT 'scheduled departure time in the departure airport timezone',
+		dep_estimated_time_local DATETIME COMMENT 'estimated departure time in the departure airport timezone',
+		dep_actual_time_local DATETIME COMMENT 'actual departure time in the departure airport timezone',
+		arr_scheduled_time_local DATETIME COMMENT 'scheduled arrival time in the arrival airport timezone',
+		arr_estimated_time_local DATETIME COMMENT 'estimated arrival time in the arrival airport timezone',
+		arr_actual_time_local DATETIME COMMENT 'actual arrival time in the arrival airport timezone',
+
 		-- Metadata
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'record creation timestamp',
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'record update timestamp',
 
-		-- Unique constraint to prevent duplicate schedules
-		UNIQUE KEY unique_schedule (flight_iata_number, dep_scheduled_time, dep_iata_code, arr_iata_code)
+		-- Unique constraint to prevent duplicate schedules, departure and arrival records of a flight are kept apart
+		UNIQUE KEY unique_schedule (flight_iata_number, dep_scheduled_time, dep_iata_code, arr_iata_code, type)
 	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Flight schedules from Aviation Edge API'`
 
 	_, err = conn.Exec(sql)
 
 	return err
 }
+
+// AddFlightSchedulesLocalTimesColumns adds the local time columns to flight_schedules created before
+// the times were normalised to UTC
+func AddFlightSchedulesLocalTimesColumns(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `ALTER TABLE flight_schedules
+		ADD COLUMN IF NOT EXISTS dep_scheduled_time_local DATETIME COMMENT 'scheduled departure time in the departure airport timezone',
+		ADD COLUMN IF NOT EXISTS dep_estimated_time_local DATETIME COMMENT 'estimated departure time in the departure airport timezone',
+		ADD COLUMN IF NOT EXISTS dep_actual_time_local DATETIME COMMENT 'actual departure time in the departure airport timezone',
+		ADD COLUMN IF NOT EXISTS arr_scheduled_time_local DATETIME COMMENT 'scheduled arrival time in the arrival airport timezone',
+		ADD COLUMN IF NOT EXISTS arr_estimated_time_local DATETIME COMMENT 'estimated arrival time in the arrival airport timezone',
+		ADD COLUMN IF NOT EXISTS arr_actual_time_local DATETIME COMMENT 'actual arrival time in the arrival airport timezone'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
+
+// AddTypeToFlightSchedulesUniqueKey lets flight_schedules created before the arrival schedules were collected
+// keep both the departure and the arrival record of a flight. The key is rebuilt only when it lacks the type.
+func AddTypeToFlightSchedulesUniqueKey(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	var hasType bool
+	err = conn.QueryRow(`SELECT COUNT(*) > 0 FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = 'flight_schedules'
+			AND index_name = 'unique_schedule' AND column_name = 'type'`).Scan(&hasType)
+	if err != nil || hasType {
+		return err
+	}
+
+	sql := `ALTER TABLE flight_schedules
+		DROP INDEX IF EXISTS unique_schedule,
+		ADD UNIQUE KEY unique_schedule (flight_iata_number, dep_scheduled_time, dep_iata_code, arr_iata_code, type)`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
@@ -0,0 +1,92 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateFlightStatusSnapshotsTable creates a table of the flight statuses polled from the flight tracker
+func CreateFlightStatusSnapshotsTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS flight_status_snapshots (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+
+		flight_iata_number VARCHAR(16) NOT NULL COMMENT 'flight IATA number',
+		airline_iata_code VARCHAR(2) COMMENT 'airline IATA code',
+		dep_iata_code VARCHAR(3) NOT NULL COMMENT 'departure airport IATA code',
+		arr_iata_code VARCHAR(3) NOT NULL COMMENT 'arrival airport IATA code',
+		status VARCHAR(32) NOT NULL COMMENT 'tracker status (e.g., started, en-route, landed)',
+		dep_delay VARCHAR(16) COMMENT 'departure delay in minutes',
+		arr_delay VARCHAR(16) COMMENT 'arrival delay in minutes',
+		dep_scheduled_time DATETIME COMMENT 'scheduled departure time in UTC, when known',
+		latitude DOUBLE COMMENT 'position latitude',
+		longitude DOUBLE COMMENT 'position longitude',
+		altitude DOUBLE COMMENT 'position altitude',
+		is_ground BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'aircraft on the ground',
+		updated_at DATETIME COMMENT 'tracker data update time in UTC',
+		polled_at DATETIME NOT NULL COMMENT 'poll time in UTC',
+
+		KEY snapshots_flight (flight_iata_number, dep_iata_code, arr_iata_code, polled_at),
+		KEY snapshots_polled (polled_at)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Flight statuses polled from the flight tracker'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
+
+// AddFlightStatusSnapshotsScheduledColumn adds the scheduled departure time to flight_status_snapshots created without it
+func AddFlightStatusSnapshotsScheduledColumn(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `alter table flight_status_snapshots
+		add column if not exists dep_scheduled_time datetime comment 'scheduled departure time in UTC, when known' after arr_delay`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
+
+// CreateDelayStatsTable creates a table of the delay distributions per flight, route and departure airport
+func CreateDelayStatsTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS delay_stats (
+		scope VARCHAR(16) NOT NULL COMMENT 'flight, route or airport',
+		flight_iata_number VARCHAR(16) NOT NULL DEFAULT '' COMMENT 'flight IATA number of the flight scope',
+		dep_iata_code VARCHAR(3) NOT NULL COMMENT 'departure airport IATA code',
+		arr_iata_code VARCHAR(3) NOT NULL DEFAULT '' COMMENT 'arrival airport IATA code, empty for the airport scope',
+
+		flights INT NOT NULL COMMENT 'observed flights',
+		cancelled INT NOT NULL COMMENT 'cancelled flights',
+		mean_delay DOUBLE NOT NULL COMMENT 'mean delay in minutes of the flights not cancelled',
+		p50_delay INT NOT NULL COMMENT 'median delay in minutes',
+		p75_delay INT NOT NULL COMMENT '75th percentile of the delay in minutes',
+		p90_delay INT NOT NULL COMMENT '90th percentile of the delay in minutes',
+		p95_delay INT NOT NULL COMMENT '95th percentile of the delay in minutes',
+		cancellation_rate DOUBLE NOT NULL COMMENT 'cancelled share of the observed flights',
+		period_from DATE NOT NULL COMMENT 'first departure date of the observations',
+		period_to DATE NOT NULL COMMENT 'last departure date of the observations',
+
+		computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'computation time',
+
+		PRIMARY KEY (scope, dep_iata_code, arr_iata_code, flight_iata_number)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Delay distributions of the flights'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
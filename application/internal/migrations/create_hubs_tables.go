@@ -0,0 +1,44 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateHubsTables creates hubs, the nearby hubs of every point, the direct connections between hubs and the log of
+// their builds
+func CreateHubsTables(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sqls := []string{
+		`create table if not exists hubs (
+			point_id varchar(64) not null primary key
+		)`,
+		`create table if not exists point_hubs (
+			point_id varchar(64) not null,
+			hub_id varchar(64) not null,
+			distance decimal(12,5) not null,
+			primary key (point_id, hub_id)
+		)`,
+		`create table if not exists hub_connections (
+			from_hub varchar(64) not null,
+			to_hub varchar(64) not null,
+			primary key (from_hub, to_hub)
+		)`,
+		`create table if not exists hub_builds (
+			id int auto_increment primary key,
+			built_at timestamp not null default current_timestamp
+		) comment 'runs of createhubs, the searches reload the hub graph after a new one'`,
+	}
+
+	for _, sql := range sqls {
+		_, err = conn.Exec(sql)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
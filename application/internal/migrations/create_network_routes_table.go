@@ -0,0 +1,39 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateNetworkRoutesTable creates a table of the airport pairs served by the airlines, without flight numbers
+// and times, imported from the OpenFlights routes
+func CreateNetworkRoutesTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS network_routes (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+
+		airline_code VARCHAR(3) NOT NULL COMMENT 'airline IATA code, ICAO code when it has none',
+		airline_iata VARCHAR(2) COMMENT 'airline IATA code',
+		airline_icao VARCHAR(3) COMMENT 'airline ICAO code',
+		dep_iata_code VARCHAR(3) NOT NULL COMMENT 'departure airport IATA code',
+		arr_iata_code VARCHAR(3) NOT NULL COMMENT 'arrival airport IATA code',
+		codeshare BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'operated by another airline',
+		stops INT NOT NULL DEFAULT 0 COMMENT 'number of stops, 0 for direct',
+		equipment VARCHAR(64) COMMENT 'aircraft IATA codes separated by spaces',
+		source VARCHAR(16) NOT NULL COMMENT 'data set of the route, like openflights',
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'record creation timestamp',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'record update timestamp',
+
+		UNIQUE KEY unique_network_route (airline_code, dep_iata_code, arr_iata_code),
+		INDEX idx_dep (dep_iata_code),
+		INDEX idx_arr (arr_iata_code)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Airport pairs served by airlines'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateRoutesTable creates a table of airline routes from Aviation Edge API, the regular flights without dates
+func CreateRoutesTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `CREATE TABLE IF NOT EXISTS routes (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+
+		airline_iata VARCHAR(2) NOT NULL COMMENT 'airline IATA code',
+		airline_icao VARCHAR(3) COMMENT 'airline ICAO code',
+		flight_number VARCHAR(16) NOT NULL COMMENT 'flight number without the airline code',
+
+		dep_iata_code VARCHAR(3) NOT NULL COMMENT 'departure airport IATA code',
+		dep_icao_code VARCHAR(4) COMMENT 'departure airport ICAO code',
+		dep_terminal VARCHAR(16) COMMENT 'departure terminal',
+		dep_time VARCHAR(8) NOT NULL COMMENT 'departure time in the departure airport timezone',
+
+		arr_iata_code VARCHAR(3) NOT NULL COMMENT 'arrival airport IATA code',
+		arr_icao_code VARCHAR(4) COMMENT 'arrival airport ICAO code',
+		arr_terminal VARCHAR(16) COMMENT 'arrival terminal',
+		arr_time VARCHAR(8) NOT NULL COMMENT 'arrival time in the arrival airport timezone',
+
+		codeshares VARCHAR(1024) COMMENT 'comma separated codeshare flight numbers',
+		days_of_week VARCHAR(7) COMMENT 'ISO weekdays of operation like 135, NULL for unknown (every day)',
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT 'record creation timestamp',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'record update timestamp',
+
+		UNIQUE KEY unique_route (airline_iata, flight_number, dep_iata_code, arr_iata_code)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Airline routes from Aviation Edge API'`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
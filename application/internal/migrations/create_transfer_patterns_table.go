@@ -0,0 +1,29 @@
+package migrations
+
+import "darbelis.eu/persedimai/internal/database"
+
+// CreateTransferPatternsTable creates a table of precomputed transfer patterns:
+// sequences of intermediate points which produced optimal paths between two points
+func CreateTransferPatternsTable(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `create table if not exists transfer_patterns (
+		from_point varchar(64) not null,
+		to_point varchar(64) not null,
+		pattern varchar(1024) not null comment 'intermediate point ids separated by comma, empty for direct travels',
+		legs int not null,
+		hits int not null default 0 comment 'amount of optimal journeys found with this pattern',
+		computed_at timestamp default current_timestamp on update current_timestamp,
+		primary key (from_point, to_point, pattern),
+		index idx_from_to_legs (from_point, to_point, legs)
+	)`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
@@ -1,7 +1,16 @@
 package migrations
 
-import "darbelis.eu/persedimai/internal/database"
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"fmt"
+	"log"
+	"strings"
+)
 
+// CreateTravelsTable creates the travels table partitioned by month of departure.
+// The table is created with the pmax partition only, month partitions are added with
+// PartitionsManager.EnsureMonthPartitions. Departure is a part of the primary key,
+// because the partitioning column must be included in every unique key.
 func CreateTravelsTable(db *database.Database) error {
 	conn, err := db.GetConnection()
 	if err != nil {
@@ -11,22 +20,115 @@ func CreateTravelsTable(db *database.Database) error {
 	defer func() { _ = db.CloseConnection() }()
 
 	sql := `create or replace table travels (
-		id varchar(64) not null primary key,
+		id varchar(64) not null,
 		from_point varchar(64) not null,
 		to_point varchar(64) not null,
 		departure datetime not null,
 		arrival datetime not null,
+		airline varchar(3) comment 'IATA code of the operating airline, empty for not flights',
+		operator varchar(64) comment 'code of the carrier of the travels other than flights',
+		service_number varchar(32) comment 'flight, train or bus number',
+		mode varchar(16) comment 'flight, train, bus, tram, subway or ferry',
+		vehicle_type varchar(32) comment 'aircraft type IATA code or vehicle description',
+		terminal varchar(16) comment 'departure terminal or platform',
 		departure_cl int,
 		departure8_cl int,
 		arrival_cl int,
 		arrival8_cl int,
+		primary key (id, departure),
 		index idx_from_departure (from_point, departure),
 		index idx_to_arrival (to_point, arrival)
 		-- index idx_from_departure_cl (from_point, departure_cl),
 		-- index idx_to_arrival_cl (to_point, arrival_cl)
-	)`
+	) ` + MaxPartitionClauseSQL("departure", true)
 
 	_, err = conn.Exec(sql)
 
 	return err
 }
+
+// AddTravelsDetailsColumns adds the operating airline and the service details to travels created without them
+func AddTravelsDetailsColumns(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	sql := `alter table travels
+		add column if not exists airline varchar(3) comment 'IATA code of the operating airline, empty for not flights' after arrival,
+		add column if not exists operator varchar(64) comment 'code of the carrier of the travels other than flights' after airline,
+		add column if not exists service_number varchar(32) comment 'flight, train or bus number' after operator,
+		add column if not exists mode varchar(16) comment 'flight, train, bus, tram, subway or ferry' after service_number,
+		add column if not exists vehicle_type varchar(32) comment 'aircraft type IATA code or vehicle description' after mode,
+		add column if not exists terminal varchar(16) comment 'departure terminal or platform' after vehicle_type`
+
+	_, err = conn.Exec(sql)
+
+	return err
+}
+
+// travelsDetailsColumns are the columns added by AddTravelsDetailsColumns
+var travelsDetailsColumns = []string{"airline", "operator", "service_number", "mode", "vehicle_type", "terminal"}
+
+// CheckTravelsDetailsColumns returns an error when travels lack the columns of AddTravelsDetailsColumns,
+// for the commands reading them without altering the table
+func CheckTravelsDetailsColumns(db *database.Database) error {
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	var count int
+	err = conn.QueryRow(`SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = 'travels'
+			AND column_name IN ('` + strings.Join(travelsDetailsColumns, "', '") + `')`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count < len(travelsDetailsColumns) {
+		return fmt.Errorf("travels lack the details columns %s, run bridgeschedules, import, importgtfs or importroutes to add them",
+			strings.Join(travelsDetailsColumns, ", "))
+	}
+
+	return nil
+}
+
+// PartitionTravelsTable converts travels created before the partitioning, keyed by id only, to the month partitioned
+// table of CreateTravelsTable with its pmax partition only. Partitioned and missing tables are left as they are.
+func PartitionTravelsTable(db *database.Database) error {
+	names, err := NewPartitionsManager(db).GetPartitionNames(TravelsPartitionedTable.TableName)
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return nil
+	}
+
+	conn, err := db.GetConnection()
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() { _ = db.CloseConnection() }()
+
+	var exists bool
+	err = conn.QueryRow(`SELECT COUNT(*) > 0 FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = 'travels'`).Scan(&exists)
+	if err != nil || !exists {
+		return err
+	}
+
+	sql := `alter table travels
+		drop primary key,
+		add primary key (id, departure)
+		` + MaxPartitionClauseSQL("departure", true)
+
+	log.Println("Running sql : " + sql)
+	_, err = conn.Exec(sql)
+
+	return err
+}
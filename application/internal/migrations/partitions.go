@@ -0,0 +1,251 @@
+package migrations
+
+import (
+	"darbelis.eu/persedimai/internal/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const MAX_PARTITION_NAME = "pmax"
+
+// MonthPartitionedTable describes a table partitioned by the month of departure.
+// Partitions are named pYYYYMM, the last partition pmax holds everything not covered yet.
+type MonthPartitionedTable struct {
+	TableName string
+	// ClusterSeconds is 0 for tables partitioned by RANGE COLUMNS(departure),
+	// otherwise the table is partitioned by RANGE(departure cluster) of that many seconds.
+	ClusterSeconds int
+}
+
+var TravelsPartitionedTable = MonthPartitionedTable{TableName: "travels"}
+
+// GetMonthPartitionedTables returns travels and all the clustered tables, travels being the first
+func GetMonthPartitionedTables() []MonthPartitionedTable {
+	result := []MonthPartitionedTable{TravelsPartitionedTable}
+	for _, spec := range GetClustersTableSpecs() {
+		result = append(result, MonthPartitionedTable{TableName: spec.TableName, ClusterSeconds: spec.ClusterSeconds})
+	}
+
+	return result
+}
+
+// BoundaryValue returns the "values less than" value for the partition holding the month before the given one.
+// Cluster numbers are calculated in UTC, the same as unix_timestamp() on a UTC server.
+func (t MonthPartitionedTable) BoundaryValue(monthStart time.Time) string {
+	if t.ClusterSeconds == 0 {
+		return fmt.Sprintf("'%s'", monthStart.Format(time.DateTime))
+	}
+
+	return fmt.Sprintf("%d", monthStart.Unix()/int64(t.ClusterSeconds))
+}
+
+// PartitionDefinitionSQL builds a definition of the partition for the given month
+func (t MonthPartitionedTable) PartitionDefinitionSQL(month time.Time) string {
+	return fmt.Sprintf("partition %s values less than (%s)", MonthPartitionName(month), t.BoundaryValue(NextMonth(month)))
+}
+
+// MaxPartitionClauseSQL returns the partitioning clause used when creating a table, having only the pmax partition
+func MaxPartitionClauseSQL(column string, byColumns bool) string {
+	columns := ""
+	if byColumns {
+		columns = " columns"
+	}
+
+	return fmt.Sprintf("partition by range%s (%s) (partition %s values less than (maxvalue))", columns, column, MAX_PARTITION_NAME)
+}
+
+// MonthStart truncates the given time to the beginning of its month in UTC
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func NextMonth(t time.Time) time.Time {
+	return MonthStart(t).AddDate(0, 1, 0)
+}
+
+func MonthPartitionName(month time.Time) string {
+	return "p" + MonthStart(month).Format("200601")
+}
+
+// ParseMonthPartitionName returns the month of the partition, ok is false for pmax and foreign partitions
+func ParseMonthPartitionName(name string) (month time.Time, ok bool) {
+	if !strings.HasPrefix(name, "p") || len(name) != 7 {
+		return time.Time{}, false
+	}
+
+	month, err := time.Parse("200601", name[1:])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return month, true
+}
+
+// PartitionsManager adds month partitions and drops old ones
+type PartitionsManager struct {
+	db *database.Database
+}
+
+func NewPartitionsManager(db *database.Database) *PartitionsManager {
+	return &PartitionsManager{db: db}
+}
+
+// GetPartitionNames returns the partition names of the table in their order
+func (m *PartitionsManager) GetPartitionNames(tableName string) ([]string, error) {
+	dbConn, err := m.db.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := dbConn.Query(`SELECT partition_name FROM information_schema.partitions
+		WHERE table_schema = DATABASE() AND table_name = ?
+		ORDER BY partition_ordinal_position`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name sql.NullString
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		if name.Valid {
+			names = append(names, name.String)
+		}
+	}
+
+	return names, rows.Err()
+}
+
+// EnsureMonthPartitions splits pmax so that every month from..to has its own partition.
+// Only months after the last existing month partition are added, data of earlier months stays in the first partition.
+func (m *PartitionsManager) EnsureMonthPartitions(table MonthPartitionedTable, from, to time.Time) error {
+	names, err := m.GetPartitionNames(table.TableName)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 || names[len(names)-1] != MAX_PARTITION_NAME {
+		return errors.New("EnsureMonthPartitions: table " + table.TableName + " is not partitioned by month")
+	}
+
+	var definitions []string
+	for _, month := range MonthsToAdd(names, from, to) {
+		definitions = append(definitions, table.PartitionDefinitionSQL(month))
+	}
+
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	definitions = append(definitions, fmt.Sprintf("partition %s values less than (maxvalue)", MAX_PARTITION_NAME))
+
+	dbConn, err := m.db.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := fmt.Sprintf("alter table %s reorganize partition %s into (\n%s)",
+		table.TableName, MAX_PARTITION_NAME, strings.Join(definitions, ",\n"))
+
+	log.Println("Running sql : " + sqlQuery)
+	_, err = dbConn.Exec(sqlQuery)
+	if err != nil {
+		return fmt.Errorf("failed to add partitions to %s : %w", table.TableName, err)
+	}
+
+	return nil
+}
+
+// MonthsToAdd returns the months from..to EnsureMonthPartitions adds to the partitions of the names,
+// the ones after the last existing month partition
+func MonthsToAdd(names []string, from, to time.Time) []time.Time {
+	month := MonthStart(from)
+	for _, name := range names {
+		existingMonth, ok := ParseMonthPartitionName(name)
+		if ok && !existingMonth.Before(month) {
+			month = NextMonth(existingMonth)
+		}
+	}
+
+	var months []time.Time
+	for ; !month.After(to); month = NextMonth(month) {
+		months = append(months, month)
+	}
+
+	return months
+}
+
+// GetPartitionsOlderThan returns month partitions which contain only departures before the cutoff month
+func (m *PartitionsManager) GetPartitionsOlderThan(tableName string, cutoff time.Time) ([]string, error) {
+	names, err := m.GetPartitionNames(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterPartitionsOlderThan(names, cutoff), nil
+}
+
+// FilterPartitionsOlderThan selects the month partitions which months end not later than the cutoff month start
+func FilterPartitionsOlderThan(names []string, cutoff time.Time) []string {
+	cutoffMonth := MonthStart(cutoff)
+
+	var result []string
+	for _, name := range names {
+		month, ok := ParseMonthPartitionName(name)
+		if ok && !NextMonth(month).After(cutoffMonth) {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// DropPartitions drops the given partitions together with their data
+func (m *PartitionsManager) DropPartitions(tableName string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	dbConn, err := m.db.GetConnection()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := fmt.Sprintf("alter table %s drop partition %s", tableName, strings.Join(names, ", "))
+	log.Println("Running sql : " + sqlQuery)
+	_, err = dbConn.Exec(sqlQuery)
+	if err != nil {
+		return fmt.Errorf("failed to drop partitions of %s : %w", tableName, err)
+	}
+
+	return nil
+}
+
+// GetTravelsDepartureRange returns min and max departure of travels, ok is false when there are no travels
+func (m *PartitionsManager) GetTravelsDepartureRange() (from, to time.Time, ok bool, err error) {
+	dbConn, err := m.db.GetConnection()
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	var minDeparture, maxDeparture sql.NullTime
+	err = dbConn.QueryRow("SELECT MIN(departure), MAX(departure) FROM travels").Scan(&minDeparture, &maxDeparture)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	if !minDeparture.Valid || !maxDeparture.Valid {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	return minDeparture.Time, maxDeparture.Time, true, nil
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMonthPartitionedTable_PartitionDefinitionSQL(t *testing.T) {
+	month := time.Date(2027, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	travels := TravelsPartitionedTable.PartitionDefinitionSQL(month)
+	expected := "partition p202701 values less than ('2027-02-01 00:00:00')"
+	if travels != expected {
+		t.Errorf("Expected %s, got %s", expected, travels)
+	}
+
+	clustered := MonthPartitionedTable{TableName: "clustered_arrival_travels2", ClusterSeconds: CLUSTER_SECONDS}
+	// 2027-02-01 00:00:00 UTC = 1801440000 seconds
+	expected = "partition p202701 values less than (500400)"
+	if got := clustered.PartitionDefinitionSQL(month); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestParseMonthPartitionName(t *testing.T) {
+	month, ok := ParseMonthPartitionName("p202612")
+	if !ok || !month.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected result %v %v", month, ok)
+	}
+
+	for _, name := range []string{MAX_PARTITION_NAME, "p2026", "x202612", "p2026ab"} {
+		if _, ok := ParseMonthPartitionName(name); ok {
+			t.Errorf("Expected %s not to be a month partition", name)
+		}
+	}
+}
+
+func TestFilterPartitionsOlderThan(t *testing.T) {
+	names := []string{"p202610", "p202611", "p202612", "p202701", MAX_PARTITION_NAME}
+	cutoff := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+
+	got := FilterPartitionsOlderThan(names, cutoff)
+	expected := []string{"p202610", "p202611"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestMonthsToAdd(t *testing.T) {
+	names := []string{"p202610", "p202611", MAX_PARTITION_NAME}
+	from := time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2027, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	var got []string
+	for _, month := range MonthsToAdd(names, from, to) {
+		got = append(got, MonthPartitionName(month))
+	}
+	expected := []string{"p202612", "p202701"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if months := MonthsToAdd([]string{MAX_PARTITION_NAME}, to, to); len(months) != 1 || MonthPartitionName(months[0]) != "p202701" {
+		t.Errorf("Expected p202701 of the table with pmax only, got %v", months)
+	}
+}
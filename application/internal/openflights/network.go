@@ -0,0 +1,156 @@
+package openflights
+
+import (
+	"darbelis.eu/persedimai/internal/aviation_edge"
+	"darbelis.eu/persedimai/internal/tables"
+	"path/filepath"
+	"slices"
+)
+
+const Source = "openflights"
+
+// Network is the OpenFlights data converted to the airports and the network routes between them
+type Network struct {
+	Airports []*aviation_edge.AirportResponse
+	Routes   []*tables.NetworkRoute
+	// Airlines operate the routes and have an IATA code, they get negative OpenFlights IDs as airline_id
+	Airlines []*aviation_edge.AirlineResponse
+	// SkippedAirports have no IATA code or an unknown country, SkippedRoutes have an airport not imported
+	SkippedAirports int
+	SkippedRoutes   int
+}
+
+// ReadNetwork reads airports.dat, airlines.dat, routes.dat and countries.dat of the directory
+// and builds the network of the airports in the given countries (ISO 3166-1 alpha-2 codes, all when empty)
+func ReadNetwork(dir string, countryCodes []string) (*Network, error) {
+	airports, err := ReadAirports(filepath.Join(dir, "airports.dat"))
+	if err != nil {
+		return nil, err
+	}
+	airlines, err := ReadAirlines(filepath.Join(dir, "airlines.dat"))
+	if err != nil {
+		return nil, err
+	}
+	routes, err := ReadRoutes(filepath.Join(dir, "routes.dat"))
+	if err != nil {
+		return nil, err
+	}
+	countries, err := ReadCountries(filepath.Join(dir, "countries.dat"))
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildNetwork(airports, airlines, routes, countries, countryCodes), nil
+}
+
+// BuildNetwork converts the OpenFlights rows to the airports of the airports table and the routes between them.
+// The airports get negative OpenFlights IDs as airport_id, not to collide with the Aviation Edge IDs.
+func BuildNetwork(airports []*Airport, airlines []*Airline, routes []*Route, countries []*Country, countryCodes []string) *Network {
+	network := &Network{}
+
+	isoCodes := make(map[string]string, len(countries))
+	for _, country := range countries {
+		isoCodes[country.Name] = country.IsoCode
+	}
+
+	imported := make(map[string]bool)
+	for _, airport := range airports {
+		isoCode := isoCodes[airport.Country]
+		if airport.Iata == "" || isoCode == "" {
+			network.SkippedAirports++
+			continue
+		}
+		if len(countryCodes) > 0 && !slices.Contains(countryCodes, isoCode) {
+			continue
+		}
+
+		imported[airport.Iata] = true
+		network.Airports = append(network.Airports, &aviation_edge.AirportResponse{
+			AirportID:        -airport.ID,
+			NameAirport:      airport.Name,
+			CodeIataAirport:  airport.Iata,
+			CodeIcaoAirport:  airport.Icao,
+			LatitudeAirport:  airport.Latitude,
+			LongitudeAirport: airport.Longitude,
+			Timezone:         airport.Timezone,
+			GMT:              airport.UtcOffset,
+			NameCountry:      airport.Country,
+			CodeIso2Country:  isoCode,
+		})
+	}
+
+	airlinesById := make(map[int]*Airline, len(airlines))
+	airlinesByIcao := make(map[string]*Airline, len(airlines))
+	for _, airline := range airlines {
+		airlinesById[airline.ID] = airline
+		if airline.Icao != "" {
+			airlinesByIcao[airline.Icao] = airline
+		}
+	}
+
+	seen := make(map[string]bool)
+	seenAirlines := make(map[int]bool)
+	for _, route := range routes {
+		if !imported[route.Source] || !imported[route.Dest] {
+			network.SkippedRoutes++
+			continue
+		}
+
+		networkRoute := &tables.NetworkRoute{
+			AirlineCode:   route.Airline,
+			DepartureIata: route.Source,
+			ArrivalIata:   route.Dest,
+			Codeshare:     route.Codeshare,
+			Stops:         route.Stops,
+			Equipment:     route.Equipment,
+			Source:        Source,
+		}
+		airline, ok := airlinesById[route.AirlineID]
+		if !ok {
+			airline, ok = airlinesByIcao[route.Airline]
+		}
+		if ok {
+			networkRoute.AirlineIata = airline.Iata
+			networkRoute.AirlineIcao = airline.Icao
+			if airline.Iata != "" && !seenAirlines[airline.ID] {
+				seenAirlines[airline.ID] = true
+				network.Airlines = append(network.Airlines, toAirlineResponse(airline, isoCodes[airline.Country]))
+			}
+		} else if len(route.Airline) == 2 {
+			networkRoute.AirlineIata = route.Airline
+		} else {
+			networkRoute.AirlineIcao = route.Airline
+		}
+		if networkRoute.AirlineIata != "" {
+			networkRoute.AirlineCode = networkRoute.AirlineIata
+		}
+
+		key := networkRoute.AirlineCode + "|" + networkRoute.DepartureIata + "|" + networkRoute.ArrivalIata
+		if networkRoute.AirlineCode == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		network.Routes = append(network.Routes, networkRoute)
+	}
+
+	return network
+}
+
+func toAirlineResponse(airline *Airline, isoCode string) *aviation_edge.AirlineResponse {
+	status := "historical"
+	if airline.Active {
+		status = "active"
+	}
+
+	return &aviation_edge.AirlineResponse{
+		AirlineID:       -airline.ID,
+		NameAirline:     airline.Name,
+		CodeIataAirline: airline.Iata,
+		CodeIcaoAirline: airline.Icao,
+		CallSign:        airline.Callsign,
+		StatusAirline:   status,
+		NameCountry:     airline.Country,
+		CodeIso2Country: isoCode,
+	}
+}
@@ -0,0 +1,70 @@
+package openflights
+
+import (
+	"darbelis.eu/persedimai/internal/util"
+	"testing"
+)
+
+func TestReadNetwork(t *testing.T) {
+	t.Run("european airports", func(t *testing.T) {
+		network, err := ReadNetwork("testdata", util.EuropeanCountryCodes)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(network.Airports) != 3 {
+			t.Fatalf("Expected 3 airports, got %d", len(network.Airports))
+		}
+		vno := network.Airports[0]
+		if vno.CodeIataAirport != "VNO" || vno.CodeIso2Country != "LT" || vno.Timezone != "Europe/Vilnius" || vno.AirportID != -2952 {
+			t.Errorf("Expected VNO in LT of Europe/Vilnius with ID -2952, got %s in %s of %s with ID %d",
+				vno.CodeIataAirport, vno.CodeIso2Country, vno.Timezone, vno.AirportID)
+		}
+		if network.SkippedAirports != 1 {
+			t.Errorf("Expected the airport without IATA code skipped, got %d", network.SkippedAirports)
+		}
+
+		expected := []string{"BT VNO RIX", "BT RIX VNO", "BA VNO LHR", "BT RIX LHR"}
+		if len(network.Routes) != len(expected) {
+			t.Fatalf("Expected %d routes, got %d", len(expected), len(network.Routes))
+		}
+		for i, route := range network.Routes {
+			got := route.AirlineCode + " " + route.DepartureIata + " " + route.ArrivalIata
+			if got != expected[i] {
+				t.Errorf("Expected route %s, got %s", expected[i], got)
+			}
+		}
+		if network.Routes[0].AirlineIcao != "BTI" || network.Routes[0].Equipment != "CR9 AT7" {
+			t.Errorf("Expected BTI route of CR9 AT7, got %s of %s", network.Routes[0].AirlineIcao, network.Routes[0].Equipment)
+		}
+		if len(network.Airlines) != 2 {
+			t.Fatalf("Expected 2 airlines of the routes, got %d", len(network.Airlines))
+		}
+		airBaltic := network.Airlines[0]
+		if airBaltic.CodeIataAirline != "BT" || airBaltic.AirlineID != -1299 || airBaltic.CallSign != "AIRBALTIC" || airBaltic.CodeIso2Country != "LV" {
+			t.Errorf("Expected BT with ID -1299, callsign AIRBALTIC in LV, got %s with ID %d, callsign %s in %s",
+				airBaltic.CodeIataAirline, airBaltic.AirlineID, airBaltic.CallSign, airBaltic.CodeIso2Country)
+		}
+		if network.SkippedRoutes != 1 {
+			t.Errorf("Expected the route to JFK skipped, got %d", network.SkippedRoutes)
+		}
+	})
+
+	t.Run("all countries", func(t *testing.T) {
+		network, err := ReadNetwork("testdata", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(network.Airports) != 4 || len(network.Routes) != 5 {
+			t.Errorf("Expected 4 airports and 5 routes, got %d and %d", len(network.Airports), len(network.Routes))
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := ReadNetwork("missing", nil)
+		if err == nil {
+			t.Error("Expected error for the missing files")
+		}
+	})
+}
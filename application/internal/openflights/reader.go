@@ -0,0 +1,188 @@
+package openflights
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Airport is a row of airports.dat
+type Airport struct {
+	ID        int
+	Name      string
+	City      string
+	Country   string
+	Iata      string
+	Icao      string
+	Latitude  float64
+	Longitude float64
+	// UtcOffset is the standard time offset in hours, like 2 or 5.5
+	UtcOffset string
+	Timezone  string
+	Type      string
+}
+
+// Airline is a row of airlines.dat
+type Airline struct {
+	ID       int
+	Name     string
+	Iata     string
+	Icao     string
+	Callsign string
+	Country  string
+	Active   bool
+}
+
+// Route is a row of routes.dat, the airline and the airports are given by their IATA or ICAO codes
+type Route struct {
+	Airline   string
+	AirlineID int
+	Source    string
+	SourceID  int
+	Dest      string
+	DestID    int
+	Codeshare bool
+	Stops     int
+	Equipment string
+}
+
+// Country is a row of countries.dat
+type Country struct {
+	Name    string
+	IsoCode string
+}
+
+// ReadAirports reads airports.dat of the OpenFlights data
+func ReadAirports(path string) ([]*Airport, error) {
+	var airports []*Airport
+	err := readRecords(path, 14, func(r []string) error {
+		latitude, err := strconv.ParseFloat(r[6], 64)
+		if err != nil {
+			return fmt.Errorf("invalid latitude %q", r[6])
+		}
+		longitude, err := strconv.ParseFloat(r[7], 64)
+		if err != nil {
+			return fmt.Errorf("invalid longitude %q", r[7])
+		}
+
+		airports = append(airports, &Airport{
+			ID:        atoi(r[0]),
+			Name:      value(r[1]),
+			City:      value(r[2]),
+			Country:   value(r[3]),
+			Iata:      value(r[4]),
+			Icao:      value(r[5]),
+			Latitude:  latitude,
+			Longitude: longitude,
+			UtcOffset: value(r[9]),
+			Timezone:  value(r[11]),
+			Type:      value(r[12]),
+		})
+
+		return nil
+	})
+
+	return airports, err
+}
+
+// ReadAirlines reads airlines.dat of the OpenFlights data
+func ReadAirlines(path string) ([]*Airline, error) {
+	var airlines []*Airline
+	err := readRecords(path, 8, func(r []string) error {
+		airlines = append(airlines, &Airline{
+			ID:       atoi(r[0]),
+			Name:     value(r[1]),
+			Iata:     value(r[3]),
+			Icao:     value(r[4]),
+			Callsign: value(r[5]),
+			Country:  value(r[6]),
+			Active:   r[7] == "Y",
+		})
+
+		return nil
+	})
+
+	return airlines, err
+}
+
+// ReadRoutes reads routes.dat of the OpenFlights data
+func ReadRoutes(path string) ([]*Route, error) {
+	var routes []*Route
+	err := readRecords(path, 9, func(r []string) error {
+		routes = append(routes, &Route{
+			Airline:   value(r[0]),
+			AirlineID: atoi(r[1]),
+			Source:    value(r[2]),
+			SourceID:  atoi(r[3]),
+			Dest:      value(r[4]),
+			DestID:    atoi(r[5]),
+			Codeshare: r[6] == "Y",
+			Stops:     atoi(r[7]),
+			Equipment: value(r[8]),
+		})
+
+		return nil
+	})
+
+	return routes, err
+}
+
+// ReadCountries reads countries.dat of the OpenFlights data
+func ReadCountries(path string) ([]*Country, error) {
+	var countries []*Country
+	err := readRecords(path, 3, func(r []string) error {
+		countries = append(countries, &Country{Name: value(r[0]), IsoCode: value(r[1])})
+
+		return nil
+	})
+
+	return countries, err
+}
+
+// readRecords passes the records of the CSV file having at least the given number of fields to the parser
+func readRecords(path string, fields int, parse func(record []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		if len(record) < fields {
+			return fmt.Errorf("%s line %d: expected %d fields, got %d", path, line, fields, len(record))
+		}
+
+		err = parse(record)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+	}
+}
+
+// value returns the field without the \N marking a missing value
+func value(field string) string {
+	if field == `\N` || field == "-" {
+		return ""
+	}
+
+	return field
+}
+
+func atoi(field string) int {
+	result, _ := strconv.Atoi(field)
+
+	return result
+}
@@ -6,4 +6,14 @@ type AirportMeta struct {
 	AirportCode  string
 	ImportedFrom *time.Time
 	ImportedTo   *time.Time
+
+	// ImportStatus of the last import: "in_progress", "done" or "failed", empty when never started
+	ImportStatus string
+	LastError    string
 }
+
+const (
+	ImportStatusInProgress = "in_progress"
+	ImportStatusDone       = "done"
+	ImportStatusFailed     = "failed"
+)
@@ -0,0 +1,26 @@
+package tables
+
+// DelayStat is the delay distribution of the flights of a scope: a flight, a route or the departures of an airport.
+// The delays are in minutes, the cancelled flights are counted by CancellationRate only.
+type DelayStat struct {
+	Scope            string
+	FlightIataNumber string
+	DepIataCode      string
+	ArrIataCode      string
+	Flights          int
+	Cancelled        int
+	MeanDelay        float64
+	P50Delay         int
+	P75Delay         int
+	P90Delay         int
+	P95Delay         int
+	CancellationRate float64
+	PeriodFrom       string
+	PeriodTo         string
+}
+
+const (
+	DelayScopeFlight  = "flight"
+	DelayScopeRoute   = "route"
+	DelayScopeAirport = "airport"
+)
@@ -0,0 +1,17 @@
+package tables
+
+// Fare is the price of a travel, or of the travels between two points when TravelID is empty.
+// Route fares apply to the departures within ValidFrom and ValidTo (YYYY-MM-DD, open when empty)
+// on the ISO weekdays of DaysOfWeek (like "135", every day when empty).
+type Fare struct {
+	ID         string
+	TravelID   string
+	From       string
+	To         string
+	ValidFrom  string
+	ValidTo    string
+	DaysOfWeek string
+	Amount     float64
+	// Currency is the ISO 4217 code
+	Currency string
+}
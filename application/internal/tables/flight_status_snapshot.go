@@ -0,0 +1,22 @@
+package tables
+
+// FlightStatusSnapshot is a status of a flight seen by a poll of the flight tracker
+type FlightStatusSnapshot struct {
+	FlightIataNumber string
+	AirlineIataCode  string
+	DepIataCode      string
+	ArrIataCode      string
+	Status           string
+	// DepDelay and ArrDelay in minutes, empty when unknown
+	DepDelay string
+	ArrDelay string
+	// DepScheduledTime is the scheduled departure UTC "YYYY-MM-DD HH:MM:SS", empty when the tracker has not given it
+	DepScheduledTime string
+	Latitude         float64
+	Longitude        float64
+	Altitude         float64
+	IsGround         bool
+	// UpdatedAt of the tracker data, PolledAt of the poll, both UTC "YYYY-MM-DD HH:MM:SS"
+	UpdatedAt string
+	PolledAt  string
+}
@@ -0,0 +1,14 @@
+package tables
+
+// PointHub assigns a hub to a point, a point may have several nearby hubs
+type PointHub struct {
+	PointID  string
+	HubID    string
+	Distance float64
+}
+
+// HubConnection is a pair of hubs having direct travels between them
+type HubConnection struct {
+	FromHub string
+	ToHub   string
+}
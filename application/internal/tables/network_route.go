@@ -0,0 +1,16 @@
+package tables
+
+// NetworkRoute is an airport pair served by an airline, without flight numbers and times (like the OpenFlights routes)
+type NetworkRoute struct {
+	// AirlineCode is the IATA code of the airline, the ICAO code when it has none
+	AirlineCode   string
+	AirlineIata   string
+	AirlineIcao   string
+	DepartureIata string
+	ArrivalIata   string
+	Codeshare     bool
+	Stops         int
+	// Equipment are the aircraft IATA codes separated by spaces
+	Equipment string
+	Source    string
+}
@@ -0,0 +1,18 @@
+package tables
+
+// Route is a regular flight of an airline without dates, departure and arrival times are local "HH:MM[:SS]"
+type Route struct {
+	AirlineIata   string
+	FlightNumber  string
+	DepartureIata string
+	DepartureTime string
+	ArrivalIata   string
+	ArrivalTime   string
+	// DaysOfWeek are the ISO weekdays (1 - Monday ... 7 - Sunday) the flight operates on, like "135", empty for every day
+	DaysOfWeek string
+}
+
+// FlightIataNumber is the airline code followed by the flight number, as in the flight schedules
+func (r *Route) FlightIataNumber() string {
+	return r.AirlineIata + r.FlightNumber
+}
@@ -0,0 +1,23 @@
+package tables
+
+// ScheduleChange is a change of a flight schedule found by a repeated import
+type ScheduleChange struct {
+	ChangeType       string
+	Type             string
+	FlightIataNumber string
+	DepIataCode      string
+	ArrIataCode      string
+	DepDate          string
+	Field            string
+	BeforeValue      string
+	AfterValue       string
+	ImportedAt       string
+}
+
+const (
+	ScheduleChangeAdded     = "added"
+	ScheduleChangeRemoved   = "removed"
+	ScheduleChangeTime      = "time"
+	ScheduleChangeCancelled = "cancelled"
+	ScheduleChangeAircraft  = "aircraft"
+)
@@ -1,6 +1,9 @@
 package tables
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Transfer struct {
 	ID        string
@@ -8,4 +11,49 @@ type Transfer struct {
 	To        string
 	Departure time.Time
 	Arrival   time.Time
+	// Airline is the IATA code of the operating airline, empty for the travels other than flights
+	Airline string
+	// Operator is the code of the carrier of the travels other than flights, like the GTFS agency id
+	Operator string
+	// ServiceNumber is the number to book, the flight IATA number or the train or bus number
+	ServiceNumber string
+	// Mode is one of the TransportMode constants, empty when unknown
+	Mode string
+	// VehicleType is the aircraft type IATA code or the vehicle description
+	VehicleType string
+	// Terminal is the departure terminal or platform
+	Terminal string
+}
+
+const (
+	TransportModeFlight = "flight"
+	TransportModeTrain  = "train"
+	TransportModeBus    = "bus"
+	TransportModeTram   = "tram"
+	TransportModeSubway = "subway"
+	TransportModeFerry  = "ferry"
+	// TransportModeWalk is a walk between the stops of a station or from a station to an airport
+	TransportModeWalk = "walk"
+)
+
+// flightTravelIdTimeLayout is the departure time of the flight travel ids, date_format '%Y%m%d%H%i' of bridge.TravelIdSQL
+const flightTravelIdTimeLayout = "200601021504"
+
+// FlightTravelID builds the id of a travel of a flight, flight_departure_DEPARR, the same as bridge.TravelIdSQL
+func FlightTravelID(flightIataNumber string, departure time.Time, depIataCode, arrIataCode string) string {
+	return strings.ToUpper(flightIataNumber) + "_" + departure.Format(flightTravelIdTimeLayout) + "_" + depIataCode + arrIataCode
+}
+
+// ParseFlightTravelID splits the id built by FlightTravelID, ok is false of the ids of the other travels
+func ParseFlightTravelID(id string) (flightIataNumber string, departure time.Time, depIataCode, arrIataCode string, ok bool) {
+	parts := strings.Split(id, "_")
+	if len(parts) != 3 || parts[0] == "" || len(parts[2]) != 6 {
+		return "", time.Time{}, "", "", false
+	}
+	departure, err := time.Parse(flightTravelIdTimeLayout, parts[1])
+	if err != nil {
+		return "", time.Time{}, "", "", false
+	}
+
+	return parts[0], departure, parts[2][:3], parts[2][3:], true
 }
@@ -0,0 +1,20 @@
+package tables
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFlightTravelID(t *testing.T) {
+	departure := time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC)
+	flight, parsed, dep, arr, ok := ParseFlightTravelID(FlightTravelID("bt344", departure, "VNO", "RIX"))
+	if !ok || flight != "BT344" || !parsed.Equal(departure) || dep != "VNO" || arr != "RIX" {
+		t.Errorf("Expected BT344 VNO → RIX at %s, got %s %s → %s at %s (%v)", departure, flight, dep, arr, parsed, ok)
+	}
+
+	for _, id := range []string{"G12", "LTG:1234_20260301_0", "BT344_2026030121_VNORIX", "BT344_202603012130_VNO"} {
+		if _, _, _, _, ok := ParseFlightTravelID(id); ok {
+			t.Errorf("Expected %s not parsed as a travel of a flight", id)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package transfer_patterns
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"sort"
+	"time"
+)
+
+// JoinLegs combines concrete transfers of every leg of a pattern into transfer sequences.
+// legs[i] holds the candidate transfers of the i-th leg. Sequences are ordered by arrival and
+// at most limit of them are returned (0 means no limit).
+func JoinLegs(legs [][]*tables.Transfer, minConnectionTime, maxConnectionTime time.Duration, limit int) []*tables.TransferSequence {
+	if len(legs) == 0 {
+		return nil
+	}
+
+	var sequences []*tables.TransferSequence
+	current := make([]*tables.Transfer, 0, len(legs))
+
+	var join func(leg int)
+	join = func(leg int) {
+		if leg == len(legs) {
+			transfers := make([]*tables.Transfer, len(current))
+			copy(transfers, current)
+			sequences = append(sequences, tables.NewTransferSequence(transfers))
+			return
+		}
+
+		for _, transfer := range legs[leg] {
+			if leg > 0 {
+				connectionTime := transfer.Departure.Sub(current[leg-1].Arrival)
+				if connectionTime < minConnectionTime || connectionTime > maxConnectionTime {
+					continue
+				}
+			}
+
+			current = append(current, transfer)
+			join(leg + 1)
+			current = current[:leg]
+		}
+	}
+	join(0)
+
+	sort.SliceStable(sequences, func(i, j int) bool {
+		return sequences[i].Last().Arrival.Before(sequences[j].Last().Arrival)
+	})
+
+	if limit > 0 && len(sequences) > limit {
+		sequences = sequences[:limit]
+	}
+
+	return sequences
+}
+
+// PreviousLegArrivalRange returns the arrival range of the previous leg transfers which can connect
+// to any of the given transfers
+func PreviousLegArrivalRange(transfers []*tables.Transfer, minConnectionTime, maxConnectionTime time.Duration) (from, to time.Time, ok bool) {
+	if len(transfers) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	minDeparture := transfers[0].Departure
+	maxDeparture := transfers[0].Departure
+	for _, transfer := range transfers[1:] {
+		if transfer.Departure.Before(minDeparture) {
+			minDeparture = transfer.Departure
+		}
+		if transfer.Departure.After(maxDeparture) {
+			maxDeparture = transfer.Departure
+		}
+	}
+
+	return minDeparture.Add(-maxConnectionTime), maxDeparture.Add(-minConnectionTime), true
+}
@@ -0,0 +1,75 @@
+package transfer_patterns
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+func TestJoinLegs(t *testing.T) {
+	legs := [][]*tables.Transfer{
+		{
+			makeTransfer("1", "A", "B", "08:00", "10:00"),
+			makeTransfer("2", "A", "B", "09:00", "11:00"),
+		},
+		{
+			// too short connection from both
+			makeTransfer("3", "B", "D", "10:10", "12:00"),
+			makeTransfer("4", "B", "D", "11:00", "13:00"),
+			makeTransfer("5", "B", "D", "12:00", "14:00"),
+		},
+	}
+
+	sequences := JoinLegs(legs, 30*time.Minute, 8*time.Hour, 0)
+
+	if len(sequences) != 3 {
+		t.Fatalf("Expected 3 sequences, got %d", len(sequences))
+	}
+
+	t.Run("ordered by arrival", func(t *testing.T) {
+		if sequences[0].Last().ID != "4" {
+			t.Errorf("Expected first sequence to end with transfer 4, got %s", sequences[0].Last().ID)
+		}
+		if sequences[0].First().ID != "1" {
+			t.Errorf("Expected first sequence to start with transfer 1, got %s", sequences[0].First().ID)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		limited := JoinLegs(legs, 30*time.Minute, 8*time.Hour, 1)
+		if len(limited) != 1 {
+			t.Errorf("Expected 1 sequence, got %d", len(limited))
+		}
+	})
+
+	t.Run("max connection time", func(t *testing.T) {
+		limited := JoinLegs(legs, 30*time.Minute, time.Hour, 0)
+		if len(limited) != 2 {
+			t.Errorf("Expected 2 sequences, got %d", len(limited))
+		}
+	})
+}
+
+func TestPreviousLegArrivalRange(t *testing.T) {
+	transfers := []*tables.Transfer{
+		makeTransfer("1", "B", "D", "11:00", "13:00"),
+		makeTransfer("2", "B", "D", "09:00", "10:00"),
+	}
+
+	from, to, ok := PreviousLegArrivalRange(transfers, 30*time.Minute, 2*time.Hour)
+	if !ok {
+		t.Fatal("Expected range to be found")
+	}
+
+	if from.Format("15:04") != "07:00" {
+		t.Errorf("Expected range from 07:00, got %s", from.Format("15:04"))
+	}
+	if to.Format("15:04") != "10:30" {
+		t.Errorf("Expected range to 10:30, got %s", to.Format("15:04"))
+	}
+
+	_, _, ok = PreviousLegArrivalRange(nil, 30*time.Minute, 2*time.Hour)
+	if ok {
+		t.Error("Expected no range for empty transfers")
+	}
+}
@@ -0,0 +1,207 @@
+package transfer_patterns
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"sort"
+	"time"
+)
+
+// label is the best known arrival to a point within a search round
+type label struct {
+	arrival   time.Time
+	transfer  *tables.Transfer
+	prevRound int
+}
+
+// PatternsBuilder computes transfer patterns offline from the given transfers.
+// For every departure from the source it runs a round based scan (one round per leg)
+// and records the intermediate points of the journeys which are Pareto optimal
+// by arrival time and legs amount.
+type PatternsBuilder struct {
+	transfers         []*tables.Transfer
+	maxLegs           int
+	minConnectionTime time.Duration
+	maxConnectionTime time.Duration
+	maxTransferTime   time.Duration
+}
+
+func NewPatternsBuilder(transfers []*tables.Transfer, maxLegs int, minConnectionTime, maxConnectionTime time.Duration) *PatternsBuilder {
+	sorted := make([]*tables.Transfer, len(transfers))
+	copy(sorted, transfers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Departure.Before(sorted[j].Departure) })
+
+	var maxTransferTime time.Duration
+	for _, transfer := range sorted {
+		maxTransferTime = max(maxTransferTime, transfer.Arrival.Sub(transfer.Departure))
+	}
+
+	return &PatternsBuilder{
+		transfers:         sorted,
+		maxLegs:           maxLegs,
+		minConnectionTime: minConnectionTime,
+		maxConnectionTime: maxConnectionTime,
+		maxTransferTime:   maxTransferTime,
+	}
+}
+
+// Build returns patterns from the source to each of the destinations
+func (b *PatternsBuilder) Build(source string, destinations []string) []*TransferPattern {
+	destinationsSet := make(map[string]bool)
+	for _, destination := range destinations {
+		destinationsSet[destination] = true
+	}
+
+	patternsMap := make(map[string]*TransferPattern)
+	var patterns []*TransferPattern
+
+	// best arrival to destination by legs amount over all later departures from the source
+	bestArrivals := make(map[string][]time.Time)
+
+	// later departures first, so that a journey is recorded only when no later departure arrives as early.
+	// Transfers departing from the source at the same time are compared together.
+	for i := len(b.transfers) - 1; i >= 0; {
+		departure := b.transfers[i].Departure
+		candidates := make(map[string][]*candidate)
+
+		for ; i >= 0 && b.transfers[i].Departure.Equal(departure); i-- {
+			if b.transfers[i].From != source {
+				continue
+			}
+
+			rounds := b.scan(i)
+			for destination := range destinationsSet {
+				b.collectCandidates(source, destination, rounds, candidates)
+			}
+		}
+
+		for destination, byLegs := range candidates {
+			best := bestArrivals[destination]
+			if best == nil {
+				best = make([]time.Time, b.maxLegs+1)
+				bestArrivals[destination] = best
+			}
+
+			for k, current := range byLegs {
+				if current == nil {
+					continue
+				}
+				if !best[k].IsZero() && !current.arrival.Before(best[k]) {
+					continue
+				}
+				best[k] = current.arrival
+
+				patternKey := destination + ":" + current.pattern.Key()
+				existing, exists := patternsMap[patternKey]
+				if exists {
+					existing.Hits++
+					continue
+				}
+				patternsMap[patternKey] = current.pattern
+				patterns = append(patterns, current.pattern)
+			}
+		}
+	}
+
+	return patterns
+}
+
+// candidate is the journey with the earliest arrival for a departure time and legs amount
+type candidate struct {
+	arrival time.Time
+	pattern *TransferPattern
+}
+
+// collectCandidates keeps the earliest journey to the destination for each legs amount,
+// which improves the journey having fewer legs
+func (b *PatternsBuilder) collectCandidates(source, destination string, rounds []map[string]label, candidates map[string][]*candidate) {
+	for k := 1; k <= b.maxLegs; k++ {
+		current, ok := rounds[k][destination]
+		if !ok || current.prevRound != k-1 {
+			// not improved in this round, the same journey was found with fewer legs
+			continue
+		}
+
+		byLegs := candidates[destination]
+		if byLegs == nil {
+			byLegs = make([]*candidate, b.maxLegs+1)
+			candidates[destination] = byLegs
+		}
+
+		if byLegs[k] != nil && !current.arrival.Before(byLegs[k].arrival) {
+			continue
+		}
+
+		byLegs[k] = &candidate{arrival: current.arrival, pattern: b.reconstruct(source, destination, rounds, k)}
+	}
+}
+
+// scan runs the rounds for journeys starting with the transfer at the given index.
+// rounds[k] holds labels of journeys having at most k legs.
+func (b *PatternsBuilder) scan(firstIndex int) []map[string]label {
+	first := b.transfers[firstIndex]
+
+	rounds := make([]map[string]label, b.maxLegs+1)
+	rounds[0] = map[string]label{}
+	rounds[1] = map[string]label{first.To: {arrival: first.Arrival, transfer: first, prevRound: 0}}
+
+	horizon := first.Arrival.Add(time.Duration(b.maxLegs-1) * (b.maxConnectionTime + b.maxTransferTime))
+
+	for k := 2; k <= b.maxLegs; k++ {
+		previous := rounds[k-1]
+		current := make(map[string]label, len(previous))
+		for point, l := range previous {
+			current[point] = l
+		}
+
+		for j := firstIndex + 1; j < len(b.transfers); j++ {
+			transfer := b.transfers[j]
+			if transfer.Departure.After(horizon) {
+				break
+			}
+
+			from, ok := previous[transfer.From]
+			if !ok {
+				continue
+			}
+
+			connectionTime := transfer.Departure.Sub(from.arrival)
+			if connectionTime < b.minConnectionTime || connectionTime > b.maxConnectionTime {
+				continue
+			}
+
+			existing, exists := current[transfer.To]
+			if exists && !transfer.Arrival.Before(existing.arrival) {
+				continue
+			}
+
+			current[transfer.To] = label{arrival: transfer.Arrival, transfer: transfer, prevRound: k - 1}
+		}
+
+		rounds[k] = current
+	}
+
+	return rounds
+}
+
+// reconstruct follows the labels back from the destination and builds the pattern of the journey
+func (b *PatternsBuilder) reconstruct(source, destination string, rounds []map[string]label, round int) *TransferPattern {
+	var points []string
+	point := destination
+	for round > 0 {
+		l := rounds[round][point]
+		point = l.transfer.From
+		round = l.prevRound
+		points = append([]string{point}, points...)
+	}
+
+	// points[0] is the source
+	intermediate := points[1:]
+
+	return &TransferPattern{
+		From:         source,
+		To:           destination,
+		Intermediate: intermediate,
+		Legs:         len(intermediate) + 1,
+		Hits:         1,
+	}
+}
@@ -0,0 +1,84 @@
+package transfer_patterns
+
+import (
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+func makeTransfer(id, from, to string, departure, arrival string) *tables.Transfer {
+	day := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	dep, _ := time.Parse("15:04", departure)
+	arr, _ := time.Parse("15:04", arrival)
+
+	return &tables.Transfer{
+		ID:        id,
+		From:      from,
+		To:        to,
+		Departure: day.Add(time.Duration(dep.Hour())*time.Hour + time.Duration(dep.Minute())*time.Minute),
+		Arrival:   day.Add(time.Duration(arr.Hour())*time.Hour + time.Duration(arr.Minute())*time.Minute),
+	}
+}
+
+func TestPatternsBuilder_Build(t *testing.T) {
+	transfers := []*tables.Transfer{
+		// direct, slow
+		makeTransfer("1", "A", "D", "08:00", "20:00"),
+		// via B, faster
+		makeTransfer("2", "A", "B", "08:00", "10:00"),
+		makeTransfer("3", "B", "D", "11:00", "13:00"),
+		// via C, arrives later than via B with the same legs amount
+		makeTransfer("4", "A", "C", "08:00", "10:00"),
+		makeTransfer("5", "C", "D", "11:00", "14:00"),
+		// via B then C, slower than via B only
+		makeTransfer("6", "B", "C", "10:30", "11:00"),
+		// connection too short from B
+		makeTransfer("7", "B", "D", "10:10", "11:00"),
+	}
+
+	builder := NewPatternsBuilder(transfers, 3, 30*time.Minute, 8*time.Hour)
+	patterns := builder.Build("A", []string{"D"})
+
+	keys := map[string]int{}
+	for _, pattern := range patterns {
+		keys[pattern.Key()] = pattern.Legs
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 patterns, got %v", keys)
+	}
+	if legs, ok := keys[""]; !ok || legs != 1 {
+		t.Errorf("Expected the direct pattern with 1 leg, got %v", keys)
+	}
+	if legs, ok := keys["B"]; !ok || legs != 2 {
+		t.Errorf("Expected pattern via B with 2 legs, got %v", keys)
+	}
+}
+
+func TestPatternsBuilder_BuildLaterDepartureDominates(t *testing.T) {
+	transfers := []*tables.Transfer{
+		// early departure via C arrives later than a later departure via B
+		makeTransfer("1", "A", "C", "06:00", "07:00"),
+		makeTransfer("2", "C", "D", "12:00", "16:00"),
+		makeTransfer("3", "A", "B", "08:00", "09:00"),
+		makeTransfer("4", "B", "D", "10:00", "12:00"),
+	}
+
+	builder := NewPatternsBuilder(transfers, 2, 30*time.Minute, 8*time.Hour)
+	patterns := builder.Build("A", []string{"D"})
+
+	if len(patterns) != 1 || patterns[0].Key() != "B" {
+		t.Errorf("Expected only pattern via B, got %d patterns", len(patterns))
+	}
+}
+
+func TestTransferPattern_Points(t *testing.T) {
+	pattern := &TransferPattern{From: "A", To: "D", Intermediate: SplitPoints("B,C")}
+	points := pattern.Points()
+	if JoinPoints(points) != "A,B,C,D" {
+		t.Errorf("Unexpected points %v", points)
+	}
+	if len(SplitPoints("")) != 0 {
+		t.Error("Expected no points of an empty string")
+	}
+}
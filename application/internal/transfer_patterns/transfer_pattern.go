@@ -0,0 +1,41 @@
+package transfer_patterns
+
+import "strings"
+
+const POINTS_SEPARATOR = ","
+
+// TransferPattern is a sequence of intermediate points which produced an optimal path
+// from one point to another
+type TransferPattern struct {
+	From         string
+	To           string
+	Intermediate []string
+	Legs         int
+	// Hits is how many optimal journeys had this pattern
+	Hits int
+}
+
+// Key returns intermediate points joined, as stored in the database
+func (p *TransferPattern) Key() string {
+	return JoinPoints(p.Intermediate)
+}
+
+// Points returns all the points of the pattern including the source and the destination
+func (p *TransferPattern) Points() []string {
+	points := []string{p.From}
+	points = append(points, p.Intermediate...)
+
+	return append(points, p.To)
+}
+
+func JoinPoints(points []string) string {
+	return strings.Join(points, POINTS_SEPARATOR)
+}
+
+func SplitPoints(joined string) []string {
+	if joined == "" {
+		return []string{}
+	}
+
+	return strings.Split(joined, POINTS_SEPARATOR)
+}
@@ -0,0 +1,52 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/tables"
+)
+
+// DetailsLoader sets the airlines and the service details of the found transfers, implemented by dao.TravelDao
+type DetailsLoader interface {
+	LoadDetails(transfers []*tables.Transfer) error
+}
+
+// AirlineTravelSearchStrategy drops the paths found by another strategy not passing the airline filter.
+// The strategies find the travels by the times only, so the wrapped strategy is asked for CandidateLimit paths,
+// of which the first filter.Limit ones passing the filter are returned.
+type AirlineTravelSearchStrategy struct {
+	strategy TravelSearchStrategy
+	loader   DetailsLoader
+	// OtherAirline counts the candidates of the last search dropped by the airline filter
+	OtherAirline int
+}
+
+func NewAirlineTravelSearchStrategy(strategy TravelSearchStrategy, loader DetailsLoader) *AirlineTravelSearchStrategy {
+	return &AirlineTravelSearchStrategy{strategy: strategy, loader: loader}
+}
+
+// FindPath finds the candidates with the wrapped strategy, loads their details and keeps the ones of the allowed airlines
+func (s *AirlineTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*TravelPath, error) {
+	paths, err := s.strategy.FindPath(candidateFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []*tables.Transfer
+	for _, path := range paths {
+		transfers = append(transfers, path.Transfers...)
+	}
+	err = s.loader.LoadDetails(transfers)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := FilterByAirlines(paths, filter)
+	s.OtherAirline = len(paths) - len(allowed)
+
+	return limitPaths(allowed, filter.Limit), nil
+}
+
+// GetName returns the strategy name
+func (s *AirlineTravelSearchStrategy) GetName() string {
+	return s.strategy.GetName() + " (airlines)"
+}
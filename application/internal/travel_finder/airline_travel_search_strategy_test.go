@@ -0,0 +1,49 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+// airlinesLoader sets the airline of the transfers by their ids
+type airlinesLoader map[string]string
+
+func (l airlinesLoader) LoadDetails(transfers []*tables.Transfer) error {
+	for _, transfer := range transfers {
+		transfer.Airline = l[transfer.ID]
+	}
+	return nil
+}
+
+func directPath(id string) *TravelPath {
+	departure := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	transfer := &tables.Transfer{ID: id, From: "VNO", To: "RIX", Departure: departure, Arrival: departure.Add(time.Hour)}
+
+	return MakeTravelPathOfTransferSequence(tables.NewTransferSequence([]*tables.Transfer{transfer}))
+}
+
+func TestAirlineTravelSearchStrategy_FindPath(t *testing.T) {
+	inner := &fakeStrategy{paths: []*TravelPath{directPath("FR1"), directPath("FR2"), directPath("BT1"), directPath("FR3"), directPath("BT2"), directPath("BT3")}}
+	loader := airlinesLoader{"FR1": "FR", "FR2": "FR", "FR3": "FR", "BT1": "BT", "BT2": "BT", "BT3": "BT"}
+	strategy := NewAirlineTravelSearchStrategy(inner, loader)
+
+	filter := data.NewTravelFilter("VNO", "RIX", time.Time{}, time.Time{}, 1)
+	filter.Limit = 2
+	filter.IncludeAirlines = []string{"BT"}
+	paths, err := strategy.FindPath(filter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if inner.askedLimit != CandidateLimit {
+		t.Errorf("Expected %d candidates asked for, got %d", CandidateLimit, inner.askedLimit)
+	}
+	if len(paths) != 2 || paths[0].Transfers[0].ID != "BT1" || paths[1].Transfers[0].ID != "BT2" {
+		t.Errorf("Expected BT1 and BT2, got %v", paths)
+	}
+	if strategy.OtherAirline != 3 {
+		t.Errorf("Expected 3 candidates of other airlines, got %d", strategy.OtherAirline)
+	}
+}
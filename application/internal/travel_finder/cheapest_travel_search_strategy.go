@@ -0,0 +1,69 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/fares"
+	"darbelis.eu/persedimai/internal/tables"
+	"sort"
+)
+
+// CheapestTravelSearchStrategy orders the paths found by another strategy by their total price
+// in filter.Currency, the shorter total duration first of the same price. The paths with unpriced legs
+// follow the fully priced ones by their duration. The strategies find the paths by the times only, so the
+// wrapped strategy is asked for CandidateLimit paths by arrival, of which the filter.Limit cheapest ones are
+// returned. The result is approximate: a cheaper path arriving after all the candidates is not found.
+// The fares of all the candidate transfers are loaded in one query.
+type CheapestTravelSearchStrategy struct {
+	strategy TravelSearchStrategy
+	finder   fares.Finder
+}
+
+func NewCheapestTravelSearchStrategy(strategy TravelSearchStrategy, finder fares.Finder) *CheapestTravelSearchStrategy {
+	return &CheapestTravelSearchStrategy{strategy: strategy, finder: finder}
+}
+
+// FindPath finds the candidates with the wrapped strategy, sets their prices and keeps the cheapest ones
+func (s *CheapestTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*TravelPath, error) {
+	paths, err := s.strategy.FindPath(candidateFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []*tables.Transfer
+	for _, path := range paths {
+		transfers = append(transfers, path.Transfers...)
+	}
+	found, err := s.finder.FindForTransfers(transfers, filter.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	SortByPrice(paths, fares.NewIndex(found), filter.Currency)
+
+	return limitPaths(paths, filter.Limit), nil
+}
+
+// GetName returns the strategy name
+func (s *CheapestTravelSearchStrategy) GetName() string {
+	return s.strategy.GetName() + " (cheapest)"
+}
+
+// SortByPrice sets the prices of the paths and orders the completely priced ones by the price
+// and the total duration, followed by the others by the total duration
+func SortByPrice(paths []*TravelPath, index *fares.Index, currency string) {
+	for _, path := range paths {
+		path.Price = index.Price(path.Transfers, currency)
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := paths[i].Price, paths[j].Price
+		if a.Complete() != b.Complete() {
+			return a.Complete()
+		}
+		if a.Complete() && a.Total != b.Total {
+			return a.Total < b.Total
+		}
+
+		return paths[i].TotalDuration < paths[j].TotalDuration
+	})
+}
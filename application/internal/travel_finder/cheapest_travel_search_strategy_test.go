@@ -0,0 +1,108 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/fares"
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+// fakeFinder returns its fares of the currency
+type fakeFinder []*tables.Fare
+
+func (f fakeFinder) FindForTransfers(transfers []*tables.Transfer, currency string) ([]*tables.Fare, error) {
+	var found []*tables.Fare
+	for _, fare := range f {
+		if fare.Currency == currency {
+			found = append(found, fare)
+		}
+	}
+	return found, nil
+}
+
+// pricedPath goes through its travels an hour each, the first departing at 08:00 and the next ones after a wait
+func pricedPath(wait time.Duration, ids ...string) *TravelPath {
+	departure := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	var transfers []*tables.Transfer
+	for _, id := range ids {
+		transfers = append(transfers, &tables.Transfer{ID: id, From: id + "-from", To: id + "-to", Departure: departure, Arrival: departure.Add(time.Hour)})
+		departure = departure.Add(time.Hour + wait)
+	}
+
+	return MakeTravelPathOfTransferSequence(tables.NewTransferSequence(transfers))
+}
+
+func pathIds(paths []*TravelPath) []string {
+	var ids []string
+	for _, path := range paths {
+		id := ""
+		for _, transfer := range path.Transfers {
+			id += transfer.ID
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestSortByPrice(t *testing.T) {
+	index := fares.NewIndex([]*tables.Fare{
+		{ID: "1", TravelID: "A", Amount: 50, Currency: "EUR"},
+		{ID: "2", TravelID: "B", Amount: 30, Currency: "EUR"},
+		{ID: "3", TravelID: "C", Amount: 20, Currency: "EUR"},
+		{ID: "4", TravelID: "D", Amount: 10.1, Currency: "EUR"},
+	})
+	paths := []*TravelPath{
+		pricedPath(0, "A"),                // 50, complete
+		pricedPath(0, "X"),                // unpriced
+		pricedPath(2*time.Hour, "B", "C"), // 50, complete, longer than A
+		pricedPath(0, "D", "X"),           // partially priced, longer than X
+		pricedPath(0, "D", "C"),           // 30.10, complete
+	}
+
+	SortByPrice(paths, index, "EUR")
+
+	expected := []string{"DC", "A", "BC", "X", "DX"}
+	ids := pathIds(paths)
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, ids)
+		}
+	}
+	if paths[0].Price.Total != 30.1 || len(paths[0].Price.Legs) != 2 || paths[0].Price.Legs[1].ID != "3" {
+		t.Errorf("Expected 30.10 of the fares 4 and 3, got %+v", paths[0].Price)
+	}
+	if paths[4].Price.Complete() || paths[4].Price.PricedLegs != 1 {
+		t.Errorf("Expected 1 of 2 legs priced, got %+v", paths[4].Price)
+	}
+}
+
+func TestCheapestTravelSearchStrategy_FindPath(t *testing.T) {
+	inner := &fakeStrategy{paths: []*TravelPath{pricedPath(0, "A"), pricedPath(0, "B"), pricedPath(0, "C"), pricedPath(0, "D")}}
+	finder := fakeFinder{
+		{ID: "1", TravelID: "A", Amount: 40, Currency: "EUR"},
+		{ID: "2", TravelID: "B", Amount: 30, Currency: "EUR"},
+		{ID: "3", TravelID: "C", Amount: 20, Currency: "USD"},
+		{ID: "4", TravelID: "D", Amount: 10, Currency: "EUR"},
+	}
+	strategy := NewCheapestTravelSearchStrategy(inner, finder)
+
+	filter := data.NewTravelFilter("", "", time.Time{}, time.Time{}, 1)
+	filter.Limit = 2
+	paths, err := strategy.FindPath(filter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if inner.askedLimit != CandidateLimit {
+		t.Errorf("Expected %d candidates asked for, got %d", CandidateLimit, inner.askedLimit)
+	}
+	// D is the last one by arrival of the wrapped strategy, C has no fare in EUR
+	ids := pathIds(paths)
+	if len(ids) != 2 || ids[0] != "D" || ids[1] != "B" {
+		t.Errorf("Expected D and B, got %v", ids)
+	}
+	if strategy.GetName() != "Fake (cheapest)" {
+		t.Errorf("Expected name 'Fake (cheapest)', got '%s'", strategy.GetName())
+	}
+}
@@ -30,6 +30,9 @@ func (s *ClusteredTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*
 
 	fmt.Printf("ClusteredTravelSearchStrategy FindPath called, travel filter: %v\n", filter)
 
+	diagnostics := s.travelDao.Diagnostics
+
+	stopClusterQueryStep := diagnostics.StartStep("cluster query")
 	switch filter.TravelCount {
 	case 1:
 		sequences, err = s.travelDao.FindPathSimple1(filter)
@@ -63,6 +66,7 @@ func (s *ClusteredTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*
 		}
 		return nil, errors.New("invalid TravelCount: must be 1, 2, 3, 4, or 5")
 	}
+	stopClusterQueryStep()
 
 	if err != nil {
 		return nil, err
@@ -73,17 +77,34 @@ func (s *ClusteredTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*
 	}
 
 	// Reload actual transfers from database to get precise timestamps
+	stopReloadStep := diagnostics.StartStep("reloadActualTransfers")
 	err = s.reloadActualTransfers(sequences)
+	stopReloadStep()
 	if err != nil {
 		return nil, err
 	}
 
 	// Filter sequences by location connectivity and minimum connection time
+	stopFilterStep := diagnostics.StartStep("post-filtering")
 	minConnectionTime := time.Duration(filter.MinConnectionTimeMinutes) * time.Minute
+	notConnectedCount := 0
+	shortConnectionCount := 0
 	filteredSequences := util.ArrayFilter(sequences, func(sequence *tables.TransferSequence) bool {
-		return sequence.AreLocationsConnected() && sequence.ValidateMinConnectionTime(minConnectionTime)
+		if !sequence.AreLocationsConnected() {
+			notConnectedCount++
+			return false
+		}
+		if !sequence.ValidateMinConnectionTime(minConnectionTime) {
+			shortConnectionCount++
+			return false
+		}
+		return true
 		// TODO check the arrival time range too
 	})
+	stopFilterStep()
+
+	diagnostics.AddDiscards("locations not connected", notConnectedCount)
+	diagnostics.AddDiscards("connection shorter than minimum", shortConnectionCount)
 
 	travelPaths := util.ArrayMap(filteredSequences, func(seq *tables.TransferSequence) *TravelPath {
 		return MakeTravelPathOfTransferSequence(seq)
@@ -0,0 +1,127 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/hubs"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// MAX_HUB_POINT_SEQUENCES limits the amount of hub routes queried for one search
+const MAX_HUB_POINT_SEQUENCES = 50
+
+// hubGraphCache keeps the hub graph of the last build of createhubs for all the searches
+var hubGraphCache struct {
+	mu      sync.Mutex
+	buildID int64
+	graph   *hubs.HubGraph
+}
+
+// loadHubGraph returns the cached hub graph, loading it again after a new build of the hub connections
+func loadHubGraph(hubDao *dao.HubDao) (*hubs.HubGraph, error) {
+	buildID, err := hubDao.GetLastBuildID()
+	if err != nil {
+		return nil, err
+	}
+
+	hubGraphCache.mu.Lock()
+	defer hubGraphCache.mu.Unlock()
+
+	if hubGraphCache.graph != nil && hubGraphCache.buildID == buildID {
+		return hubGraphCache.graph, nil
+	}
+
+	connections, err := hubDao.SelectHubConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	hubGraphCache.buildID = buildID
+	hubGraphCache.graph = hubs.NewHubGraph(connections)
+
+	return hubGraphCache.graph, nil
+}
+
+// HubTravelSearchStrategy searches in two phases. First it combines access legs from the source
+// to its nearby hubs, hub to hub routes and egress legs from the destination hubs into point sequences,
+// then it loads concrete travels only along those sequences.
+type HubTravelSearchStrategy struct {
+	travelDao *dao.TravelDao
+	hubDao    *dao.HubDao
+}
+
+func NewHubTravelSearchStrategy(travelDao *dao.TravelDao, hubDao *dao.HubDao) *HubTravelSearchStrategy {
+	return &HubTravelSearchStrategy{
+		travelDao: travelDao,
+		hubDao:    hubDao,
+	}
+}
+
+// FindPath finds paths having exactly filter.TravelCount travels going through hubs
+func (s *HubTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*TravelPath, error) {
+	log.Printf("HubTravelSearchStrategy FindPath called, travel filter: %v", filter)
+
+	if filter.TravelCount < 1 {
+		return nil, errors.New("invalid TravelCount: must be positive")
+	}
+
+	diagnostics := s.travelDao.Diagnostics
+
+	stopHubsStep := diagnostics.StartStep("hub routes")
+	sourceHubs, err := s.hubDao.FindPointHubIDs(filter.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationHubs, err := s.hubDao.FindPointHubIDs(filter.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sourceHubs) == 0 || len(destinationHubs) == 0 {
+		return nil, fmt.Errorf("hubs are not assigned for %s → %s", filter.Source, filter.Destination)
+	}
+
+	graph, err := loadHubGraph(s.hubDao)
+	if err != nil {
+		return nil, err
+	}
+
+	pointSequences := graph.PointSequences(filter.Source, filter.Destination, sourceHubs, destinationHubs, filter.TravelCount, MAX_HUB_POINT_SEQUENCES)
+	stopHubsStep()
+
+	stopLegsStep := diagnostics.StartStep("legs queries")
+	var sequences []*tables.TransferSequence
+	emptyRoutesCount := 0
+	for _, points := range pointSequences {
+		pointsSequences, err := findSequencesAlongPoints(s.travelDao, points, filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(pointsSequences) == 0 {
+			emptyRoutesCount++
+		}
+		sequences = append(sequences, pointsSequences...)
+	}
+	stopLegsStep()
+
+	diagnostics.AddDiscards("hub routes without connecting departures", emptyRoutesCount)
+
+	sequences = sortAndLimitSequences(sequences, filter.Limit)
+
+	travelPaths := util.ArrayMap(sequences, func(seq *tables.TransferSequence) *TravelPath {
+		return MakeTravelPathOfTransferSequence(seq)
+	})
+
+	return travelPaths, nil
+}
+
+// GetName returns the strategy name
+func (s *HubTravelSearchStrategy) GetName() string {
+	return "Hub"
+}
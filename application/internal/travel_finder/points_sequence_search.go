@@ -0,0 +1,51 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/transfer_patterns"
+	"sort"
+	"time"
+)
+
+// findSequencesAlongPoints loads transfers of every leg between the consecutive points starting
+// from the last leg and joins them into sequences arriving within the filter arrival range
+func findSequencesAlongPoints(travelDao *dao.TravelDao, points []string, filter *data.TravelFilter) ([]*tables.TransferSequence, error) {
+	minConnectionTime := time.Duration(filter.MinConnectionTimeMinutes) * time.Minute
+	maxConnectionTime := time.Duration(filter.MaxConnectionTimeHours) * time.Hour
+
+	legs := make([][]*tables.Transfer, len(points)-1)
+
+	arrivalFrom := filter.ArrivalTimeFrom
+	arrivalTo := filter.ArrivalTimeTo
+	for leg := len(legs) - 1; leg >= 0; leg-- {
+		transfers, err := travelDao.FindByPointsAndArrivalRange(points[leg], points[leg+1], arrivalFrom, arrivalTo)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		arrivalFrom, arrivalTo, ok = transfer_patterns.PreviousLegArrivalRange(transfers, minConnectionTime, maxConnectionTime)
+		if !ok {
+			return nil, nil
+		}
+
+		legs[leg] = transfers
+	}
+
+	return transfer_patterns.JoinLegs(legs, minConnectionTime, maxConnectionTime, filter.Limit), nil
+}
+
+// sortAndLimitSequences orders sequences by arrival and keeps at most limit of them (0 means no limit)
+func sortAndLimitSequences(sequences []*tables.TransferSequence, limit int) []*tables.TransferSequence {
+	sort.SliceStable(sequences, func(i, j int) bool {
+		return sequences[i].Last().Arrival.Before(sequences[j].Last().Arrival)
+	})
+
+	if limit > 0 && len(sequences) > limit {
+		sequences = sequences[:limit]
+	}
+
+	return sequences
+}
@@ -0,0 +1,41 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/delays"
+)
+
+// ReliableTravelSearchStrategy scores the reliability of the paths found by another strategy and drops the ones
+// less reliable than filter.MinReliability. The wrapped strategy is asked for CandidateLimit paths, of which
+// the first filter.Limit reliable enough ones are returned.
+type ReliableTravelSearchStrategy struct {
+	strategy TravelSearchStrategy
+	scorer   *delays.ReliabilityScorer
+	// Unreliable counts the candidates of the last search dropped by filter.MinReliability
+	Unreliable int
+}
+
+func NewReliableTravelSearchStrategy(strategy TravelSearchStrategy, scorer *delays.ReliabilityScorer) *ReliableTravelSearchStrategy {
+	return &ReliableTravelSearchStrategy{strategy: strategy, scorer: scorer}
+}
+
+// FindPath finds the candidates with the wrapped strategy and keeps the reliable enough ones
+func (s *ReliableTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*TravelPath, error) {
+	paths, err := s.strategy.FindPath(candidateFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	reliable, err := ScoreReliability(paths, s.scorer, filter.MinReliability)
+	if err != nil {
+		return nil, err
+	}
+	s.Unreliable = len(paths) - len(reliable)
+
+	return limitPaths(reliable, filter.Limit), nil
+}
+
+// GetName returns the strategy name
+func (s *ReliableTravelSearchStrategy) GetName() string {
+	return s.strategy.GetName() + " (reliable)"
+}
@@ -0,0 +1,80 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/delays"
+	"darbelis.eu/persedimai/internal/tables"
+	"testing"
+	"time"
+)
+
+// fakeStrategy returns its paths up to the limit asked for
+type fakeStrategy struct {
+	paths      []*TravelPath
+	askedLimit int
+}
+
+func (s *fakeStrategy) FindPath(filter *data.TravelFilter) ([]*TravelPath, error) {
+	s.askedLimit = filter.Limit
+	return limitPaths(s.paths, filter.Limit), nil
+}
+
+func (s *fakeStrategy) GetName() string {
+	return "Fake"
+}
+
+type routeStatsFinder map[string]*tables.DelayStat
+
+func (f routeStatsFinder) FindFlight(flight, dep, arr string) (*tables.DelayStat, error) {
+	return nil, nil
+}
+
+func (f routeStatsFinder) FindRoute(dep, arr string) (*tables.DelayStat, error) {
+	return f[dep+arr], nil
+}
+
+func (f routeStatsFinder) FindAirport(dep string) (*tables.DelayStat, error) {
+	return nil, nil
+}
+
+// connectingPath goes A → B → C with the given connection time in B, the departure is shifted by the index
+func connectingPath(index int, connection time.Duration) *TravelPath {
+	departure := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC).Add(time.Duration(index) * time.Minute)
+	first := &tables.Transfer{ID: "AB", From: "A", To: "B", Departure: departure, Arrival: departure.Add(time.Hour)}
+	second := &tables.Transfer{ID: "BC", From: "B", To: "C", Departure: first.Arrival.Add(connection), Arrival: first.Arrival.Add(connection + time.Hour)}
+
+	return MakeTravelPathOfTransferSequence(tables.NewTransferSequence([]*tables.Transfer{first, second}))
+}
+
+func TestReliableTravelSearchStrategy_FindPath(t *testing.T) {
+	inner := &fakeStrategy{paths: []*TravelPath{
+		connectingPath(0, 0),
+		connectingPath(1, 3*time.Hour),
+		connectingPath(2, 0),
+		connectingPath(3, 3*time.Hour),
+		connectingPath(4, 3*time.Hour),
+	}}
+	finder := routeStatsFinder{"AB": {P50Delay: 10, P75Delay: 20, P90Delay: 40, P95Delay: 60}}
+	strategy := NewReliableTravelSearchStrategy(inner, delays.NewReliabilityScorer(finder, 0))
+
+	filter := data.NewTravelFilter("A", "C", time.Time{}, time.Time{}, 2)
+	filter.Limit = 2
+	filter.MinReliability = 0.9
+	paths, err := strategy.FindPath(filter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if inner.askedLimit != CandidateLimit {
+		t.Errorf("Expected %d candidates asked for, got %d", CandidateLimit, inner.askedLimit)
+	}
+	if len(paths) != 2 || paths[0] != inner.paths[1] || paths[1] != inner.paths[3] {
+		t.Errorf("Expected the first 2 reliable paths, got %v", paths)
+	}
+	if strategy.Unreliable != 2 {
+		t.Errorf("Expected 2 unreliable candidates, got %d", strategy.Unreliable)
+	}
+	if filter.Limit != 2 {
+		t.Errorf("Expected the filter of the caller kept, got limit %d", filter.Limit)
+	}
+}
@@ -25,6 +25,14 @@ func (s *SimpleTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*Tra
 	var sequences []*tables.TransferSequence
 	var err error
 
+	if filter.TravelCount > 3 {
+		return nil, errors.New("unimplemented: TravelCount > 3 not supported")
+	}
+	if filter.TravelCount < 1 {
+		return nil, errors.New("invalid TravelCount: must be 1, 2, or 3")
+	}
+
+	stopQueryStep := s.travelDao.Diagnostics.StartStep("query")
 	switch filter.TravelCount {
 	case 1:
 		sequences, err = s.travelDao.FindPathSimple1(filter)
@@ -32,12 +40,8 @@ func (s *SimpleTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*Tra
 		sequences, err = s.travelDao.FindPathSimple2(filter)
 	case 3:
 		sequences, err = s.travelDao.FindPathSimple3(filter)
-	default:
-		if filter.TravelCount > 3 {
-			return nil, errors.New("unimplemented: TravelCount > 3 not supported")
-		}
-		return nil, errors.New("invalid TravelCount: must be 1, 2, or 3")
 	}
+	stopQueryStep()
 
 	if err != nil {
 		return nil, err
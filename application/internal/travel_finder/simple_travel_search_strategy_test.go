@@ -11,7 +11,7 @@ func TestSimpleTravelSearchStrategy_FindPath_InvalidTravelCount(t *testing.T) {
 
 	t.Run("TravelCount=0", func(t *testing.T) {
 		filter := data.NewTravelFilter("", "", time.Time{}, time.Time{}, 0)
-		_, err := strategy.FindPaths(filter)
+		_, err := strategy.FindPath(filter)
 		if err == nil {
 			t.Error("Expected error for TravelCount=0, got nil")
 		}
@@ -22,7 +22,7 @@ func TestSimpleTravelSearchStrategy_FindPath_InvalidTravelCount(t *testing.T) {
 
 	t.Run("TravelCount=4", func(t *testing.T) {
 		filter := data.NewTravelFilter("", "", time.Time{}, time.Time{}, 4)
-		_, err := strategy.FindPaths(filter)
+		_, err := strategy.FindPath(filter)
 		if err == nil {
 			t.Error("Expected error for TravelCount=4, got nil")
 		}
@@ -33,7 +33,7 @@ func TestSimpleTravelSearchStrategy_FindPath_InvalidTravelCount(t *testing.T) {
 
 	t.Run("TravelCount=5", func(t *testing.T) {
 		filter := data.NewTravelFilter("", "", time.Time{}, time.Time{}, 5)
-		_, err := strategy.FindPaths(filter)
+		_, err := strategy.FindPath(filter)
 		if err == nil {
 			t.Error("Expected error for TravelCount=5, got nil")
 		}
@@ -0,0 +1,83 @@
+package travel_finder
+
+import (
+	"darbelis.eu/persedimai/internal/dao"
+	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/tables"
+	"darbelis.eu/persedimai/internal/util"
+	"errors"
+	"fmt"
+)
+
+// TransferPatternsTravelSearchStrategy searches only along the precomputed transfer patterns of the pair.
+// Departures of every leg are loaded backward from the destination, so each query is a single
+// indexed lookup instead of a self join of the travels table.
+type TransferPatternsTravelSearchStrategy struct {
+	travelDao          *dao.TravelDao
+	transferPatternDao *dao.TransferPatternDao
+}
+
+func NewTransferPatternsTravelSearchStrategy(travelDao *dao.TravelDao, transferPatternDao *dao.TransferPatternDao) *TransferPatternsTravelSearchStrategy {
+	return &TransferPatternsTravelSearchStrategy{
+		travelDao:          travelDao,
+		transferPatternDao: transferPatternDao,
+	}
+}
+
+// FindPath finds paths having exactly filter.TravelCount travels along the known patterns
+func (s *TransferPatternsTravelSearchStrategy) FindPath(filter *data.TravelFilter) ([]*TravelPath, error) {
+	if filter.TravelCount < 1 {
+		return nil, errors.New("invalid TravelCount: must be positive")
+	}
+
+	diagnostics := s.travelDao.Diagnostics
+
+	stopPatternsStep := diagnostics.StartStep("load patterns")
+	patterns, err := s.transferPatternDao.FindByPair(filter.Source, filter.Destination, filter.TravelCount)
+	stopPatternsStep()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patterns) == 0 {
+		count, err := s.transferPatternDao.CountByPair(filter.Source, filter.Destination)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("transfer patterns are not computed for %s → %s", filter.Source, filter.Destination)
+		}
+
+		return nil, nil
+	}
+
+	stopLegsStep := diagnostics.StartStep("legs queries")
+	var sequences []*tables.TransferSequence
+	emptyPatternsCount := 0
+	for _, pattern := range patterns {
+		patternSequences, err := findSequencesAlongPoints(s.travelDao, pattern.Points(), filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(patternSequences) == 0 {
+			emptyPatternsCount++
+		}
+		sequences = append(sequences, patternSequences...)
+	}
+	stopLegsStep()
+
+	diagnostics.AddDiscards("patterns without connecting departures", emptyPatternsCount)
+
+	sequences = sortAndLimitSequences(sequences, filter.Limit)
+
+	travelPaths := util.ArrayMap(sequences, func(seq *tables.TransferSequence) *TravelPath {
+		return MakeTravelPathOfTransferSequence(seq)
+	})
+
+	return travelPaths, nil
+}
+
+// GetName returns the strategy name
+func (s *TransferPatternsTravelSearchStrategy) GetName() string {
+	return "TransferPatterns"
+}
@@ -2,6 +2,8 @@ package travel_finder
 
 import (
 	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/delays"
+	"darbelis.eu/persedimai/internal/fares"
 	"darbelis.eu/persedimai/internal/tables"
 	"fmt"
 	"strings"
@@ -14,6 +16,10 @@ type TravelPath struct {
 	TotalDuration time.Duration
 	TotalDistance float64
 	TransferCount int
+	// Reliability of the connections, nil when not scored
+	Reliability *delays.Reliability
+	// Price of the path by its legs, nil when not priced
+	Price *fares.PathPrice
 }
 
 func MakeTravelPathOfTransferSequence(sequence *tables.TransferSequence) *TravelPath {
@@ -24,6 +30,40 @@ func MakeTravelPathOfTransferSequence(sequence *tables.TransferSequence) *Travel
 	}
 }
 
+// ScoreReliability sets the reliability of the paths and drops the ones less reliable than minProbability (0 keeps all)
+func ScoreReliability(paths []*TravelPath, scorer *delays.ReliabilityScorer, minProbability float64) ([]*TravelPath, error) {
+	var scored []*TravelPath
+	for _, path := range paths {
+		reliability, err := scorer.Score(tables.NewTransferSequence(path.Transfers))
+		if err != nil {
+			return nil, err
+		}
+		path.Reliability = reliability
+
+		if reliability.Probability >= minProbability {
+			scored = append(scored, path)
+		}
+	}
+
+	return scored, nil
+}
+
+// FilterByAirlines drops the paths not passing the airline filter, the airlines of the transfers must be loaded
+func FilterByAirlines(paths []*TravelPath, filter *data.TravelFilter) []*TravelPath {
+	var filtered []*TravelPath
+	for _, path := range paths {
+		airlines := make([]string, len(path.Transfers))
+		for i, transfer := range path.Transfers {
+			airlines[i] = transfer.Airline
+		}
+		if filter.AllowsAirlines(airlines) {
+			filtered = append(filtered, path)
+		}
+	}
+
+	return filtered
+}
+
 // ToString returns a formatted string representation of the travel path
 func (tp *TravelPath) ToString(pointGetter data.PointGetter) string {
 	var sb strings.Builder
@@ -11,3 +11,27 @@ type TravelSearchStrategy interface {
 	// GetName returns the strategy name (for logging/debugging)
 	GetName() string
 }
+
+// CandidateLimit is the number of paths the strategies dropping or reordering the found paths ask the wrapped
+// strategy for, so that filter.Limit paths are left of busy pairs. The paths arriving after the first
+// CandidateLimit ones are never considered, so their results are approximate.
+const CandidateLimit = 1000
+
+// candidateFilter copies the filter with the limit widened to CandidateLimit, no limit (0) is kept
+func candidateFilter(filter *data.TravelFilter) *data.TravelFilter {
+	candidates := *filter
+	if candidates.Limit > 0 && candidates.Limit < CandidateLimit {
+		candidates.Limit = CandidateLimit
+	}
+
+	return &candidates
+}
+
+// limitPaths keeps at most limit paths (0 means no limit)
+func limitPaths(paths []*TravelPath, limit int) []*TravelPath {
+	if limit > 0 && len(paths) > limit {
+		return paths[:limit]
+	}
+
+	return paths
+}
@@ -4,14 +4,19 @@ import (
 	"darbelis.eu/persedimai/di"
 	"darbelis.eu/persedimai/internal/dao"
 	"darbelis.eu/persedimai/internal/data"
+	"darbelis.eu/persedimai/internal/delays"
+	"darbelis.eu/persedimai/internal/fares"
 	"darbelis.eu/persedimai/internal/tables"
 	"darbelis.eu/persedimai/internal/travel_finder"
 	"darbelis.eu/persedimai/internal/util"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -34,6 +39,14 @@ type SearchFormData struct {
 	TravelCount       string
 	MaxConnectionTime string
 	MinConnectionTime string
+	MinReliability    string
+	IncludeAirlines   string
+	ExcludeAirlines   string
+	SameAirline       bool
+	OrderBy           string
+	Currency          string
+	Debug             bool
+	Analyze           bool
 }
 
 type StrategyOption struct {
@@ -58,15 +71,30 @@ type SearchResultData struct {
 	TravelCount        int
 	MaxConnectionTime  int
 	MinConnectionTime  int
+	MinReliability     int
+	UnreliablePaths    int // found but dropped by MinReliability
+	IncludeAirlines    string
+	ExcludeAirlines    string
+	SameAirline        bool
+	OtherAirlinePaths  int // found but dropped by the airline filter
+	OrderBy            string
+	Currency           string
 	Paths              []*TravelPath
 	ExecutionTime      string
 	Error              string
+	Debug              bool
+	Analyze            bool
+	Diagnostics        *data.SearchDiagnostics
 }
 
 type TravelPath struct {
 	Transfers     []*TransferDisplay
 	TotalDuration string
 	TransferCount int
+	// Reliability is the probability of making all the connections, empty when unknown
+	Reliability string
+	// Price is the total price of the path, empty when not ordered by the price
+	Price string
 }
 
 type TransferDisplay struct {
@@ -75,6 +103,14 @@ type TransferDisplay struct {
 	Departure string
 	Arrival   string
 	Duration  string
+	// Airline is the operating airline name and IATA code, else the operator code of the travels other than flights
+	Airline       string
+	ServiceNumber string
+	Mode          string
+	VehicleType   string
+	Terminal      string
+	// Price is the fare of the transfer, empty when unknown
+	Price string
 }
 
 const SEARCH_TIMEOUT = 30
@@ -87,6 +123,8 @@ func (controller *TravelSearchController) SearchForm(c *gin.Context) {
 	strategies := []StrategyOption{
 		{Name: "Clustered Strategy", Value: "clustered"},
 		{Name: "Simple Strategy", Value: "simple"},
+		{Name: "Transfer Patterns Strategy", Value: "patterns"},
+		{Name: "Hub Strategy", Value: "hubs"},
 	}
 
 	// For now, we'll load points from the first available database
@@ -110,6 +148,14 @@ func (controller *TravelSearchController) SearchForm(c *gin.Context) {
 	travelCount := c.Query("travel_count")
 	maxConnectionTime := c.Query("max_connection_time")
 	minConnectionTime := c.Query("min_connection_time")
+	minReliability := c.Query("min_reliability")
+	includeAirlines := c.Query("include_airlines")
+	excludeAirlines := c.Query("exclude_airlines")
+	sameAirline := c.Query("same_airline") != ""
+	orderBy := c.Query("order_by")
+	currency := c.Query("currency")
+	debug := c.Query("debug") != ""
+	analyze := c.Query("analyze") != ""
 
 	if maxConnectionTime == "" {
 		maxConnectionTime = "32"
@@ -128,6 +174,14 @@ func (controller *TravelSearchController) SearchForm(c *gin.Context) {
 		TravelCount:       travelCount,
 		MaxConnectionTime: maxConnectionTime,
 		MinConnectionTime: minConnectionTime,
+		MinReliability:    minReliability,
+		IncludeAirlines:   includeAirlines,
+		ExcludeAirlines:   excludeAirlines,
+		SameAirline:       sameAirline,
+		OrderBy:           orderBy,
+		Currency:          currency,
+		Debug:             debug,
+		Analyze:           analyze,
 	}
 
 	maxConnectionTimes := util.ArrayMap(dao.MAX_CLUSTERED_CONNECTION_TIME_RANGE, func(t int) string { return strconv.Itoa(t) })
@@ -151,6 +205,14 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 	travelCountStr := c.PostForm("travel_count")
 	maxConnectionTimeStr := c.PostForm("max_connection_time")
 	minConnectionTimeStr := c.PostForm("min_connection_time")
+	minReliabilityStr := c.PostForm("min_reliability")
+	includeAirlines := c.PostForm("include_airlines")
+	excludeAirlines := c.PostForm("exclude_airlines")
+	sameAirline := c.PostForm("same_airline") != ""
+	orderBy := c.PostForm("order_by")
+	currency := strings.ToUpper(strings.TrimSpace(c.PostForm("currency")))
+	debug := c.PostForm("debug") != ""
+	analyze := c.PostForm("analyze") != ""
 
 	travelCount, err := strconv.Atoi(travelCountStr)
 	if err != nil {
@@ -195,6 +257,12 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 
 	travelDao.Timeout = searchTimeout
 
+	// Collect executed queries, EXPLAIN output and step timings
+	if debug {
+		travelDao.Diagnostics = data.NewSearchDiagnostics()
+		travelDao.Diagnostics.Analyze = analyze
+	}
+
 	// Create strategy
 	var strategy travel_finder.TravelSearchStrategy
 	switch strategyType {
@@ -202,6 +270,10 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 		strategy = travel_finder.NewSimpleTravelSearchStrategy(travelDao)
 	case "clustered":
 		strategy = travel_finder.NewClusteredTravelSearchStrategy(travelDao)
+	case "patterns":
+		strategy = travel_finder.NewTransferPatternsTravelSearchStrategy(travelDao, dao.NewTransferPatternDao(db))
+	case "hubs":
+		strategy = travel_finder.NewHubTravelSearchStrategy(travelDao, dao.NewHubDao(db))
 	default:
 		c.HTML(http.StatusOK, "travel-search-result.html", gin.H{
 			"data": SearchResultData{Error: "Unknown strategy: " + strategyType},
@@ -209,6 +281,11 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 		return
 	}
 
+	// The found paths are ordered by their total price, the fares of all the found travels are loaded in one query
+	if orderBy == "price" {
+		strategy = travel_finder.NewCheapestTravelSearchStrategy(strategy, dao.NewFaresDao(db))
+	}
+
 	// Create filter
 	filter := data.NewTravelFilter(source, destination, arrivalTimeFrom, arrivalTimeTo, travelCount)
 
@@ -234,6 +311,41 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 		filter.MinConnectionTimeMinutes = minConnectionTime
 	}
 
+	// Parse and set min reliability (percent)
+	if minReliability, err := strconv.Atoi(minReliabilityStr); err == nil && minReliability > 0 && minReliability <= 100 {
+		filter.MinReliability = float64(minReliability) / 100
+	}
+
+	filter.IncludeAirlines = data.ParseAirlines(includeAirlines)
+	filter.ExcludeAirlines = data.ParseAirlines(excludeAirlines)
+	filter.SameAirlineOnly = sameAirline
+	if currency != "" {
+		filter.Currency = currency
+	}
+
+	// The paths of the other airlines are dropped of more candidates, their airlines are loaded in one query
+	var airlineStrategy *travel_finder.AirlineTravelSearchStrategy
+	if filter.HasAirlineFilter() {
+		airlineStrategy = travel_finder.NewAirlineTravelSearchStrategy(strategy, travelDao)
+		strategy = airlineStrategy
+	}
+
+	// The paths less reliable than the minimum are dropped of more candidates, see delaystats
+	var reliableStrategy *travel_finder.ReliableTravelSearchStrategy
+	if filter.MinReliability > 0 {
+		scorer := delays.NewReliabilityScorer(dao.NewDelayStatsDao(db), time.Duration(filter.MinConnectionTimeMinutes)*time.Minute)
+		reliableStrategy = travel_finder.NewReliableTravelSearchStrategy(strategy, scorer)
+		strategy = reliableStrategy
+	}
+
+	// Count searches of the pair aside of the search, the most searched pairs get their transfer patterns precomputed
+	go func() {
+		err := dao.NewSearchStatsDao(db).RegisterSearch(source, destination)
+		if err != nil {
+			log.Printf("Failed to register search of %s → %s: %v", source, destination, err)
+		}
+	}()
+
 	// Execute search in goroutine with timeout
 	type SearchResult struct {
 		Paths []*travel_finder.TravelPath
@@ -264,11 +376,39 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 
 	if err != nil {
 		c.HTML(http.StatusOK, "travel-search-result.html", gin.H{
-			"data": SearchResultData{Error: "Search error: " + err.Error()},
+			"data": SearchResultData{Error: "Search error: " + err.Error(), Debug: debug, Analyze: analyze, Diagnostics: travelDao.Diagnostics},
 		})
 		return
 	}
 
+	// The strategies load the times only, the airlines and the service details of the found travels are loaded in one query,
+	// the airline strategy has loaded them already
+	var transfers []*tables.Transfer
+	for _, path := range paths {
+		transfers = append(transfers, path.Transfers...)
+	}
+	otherAirlinePaths := 0
+	if airlineStrategy != nil {
+		otherAirlinePaths = airlineStrategy.OtherAirline
+	} else {
+		err = travelDao.LoadDetails(transfers)
+		if err != nil {
+			log.Printf("Failed to load the travel details: %v", err)
+		}
+	}
+
+	// Rate the connections by the delay stats of the arriving flights, the reliable strategy has rated them already
+	unreliablePaths := 0
+	if reliableStrategy != nil {
+		unreliablePaths = reliableStrategy.Unreliable
+	} else {
+		scorer := delays.NewReliabilityScorer(dao.NewDelayStatsDao(db), time.Duration(filter.MinConnectionTimeMinutes)*time.Minute)
+		_, err = travel_finder.ScoreReliability(paths, scorer, 0)
+		if err != nil {
+			log.Printf("Failed to rate the connections: %v", err)
+		}
+	}
+
 	// Get point data for display
 	pointDao := dao.NewPointDao(db)
 	pointsData, _ := pointDao.SelectAll()
@@ -277,6 +417,18 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 		pointMap[p.ID] = p
 	}
 
+	// Get airline names for display
+	var airlineCodes []string
+	for _, transfer := range transfers {
+		if transfer.Airline != "" && !slices.Contains(airlineCodes, transfer.Airline) {
+			airlineCodes = append(airlineCodes, transfer.Airline)
+		}
+	}
+	airlineNames, err := dao.NewAirlinesDao(db).FindNames(airlineCodes)
+	if err != nil {
+		log.Printf("Failed to load the airline names: %v", err)
+	}
+
 	// Convert to display format
 	displayPaths := make([]*TravelPath, len(paths))
 	for i, path := range paths {
@@ -292,17 +444,33 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 			}
 
 			transfers[j] = &TransferDisplay{
-				From:      fromName,
-				To:        toName,
-				Departure: transfer.Departure.Format(time.DateTime),
-				Arrival:   transfer.Arrival.Format(time.DateTime),
-				Duration:  transfer.Arrival.Sub(transfer.Departure).String(),
+				From:          fromName,
+				To:            toName,
+				Departure:     transfer.Departure.Format(time.DateTime),
+				Arrival:       transfer.Arrival.Format(time.DateTime),
+				Duration:      transfer.Arrival.Sub(transfer.Departure).String(),
+				Airline:       transfer.Airline,
+				ServiceNumber: transfer.ServiceNumber,
+				Mode:          transfer.Mode,
+				VehicleType:   transfer.VehicleType,
+				Terminal:      transfer.Terminal,
+			}
+			if path.Price != nil && path.Price.Legs[j] != nil {
+				transfers[j].Price = formatPrice(path.Price.Legs[j].Amount, path.Price.Currency)
+			}
+			if transfer.Airline == "" {
+				transfers[j].Airline = transfer.Operator
+			}
+			if name, ok := airlineNames[transfer.Airline]; ok {
+				transfers[j].Airline = fmt.Sprintf("%s (%s)", name, transfer.Airline)
 			}
 		}
 		displayPaths[i] = &TravelPath{
 			Transfers:     transfers,
 			TotalDuration: path.TotalDuration.String(),
 			TransferCount: path.TransferCount,
+			Reliability:   formatReliability(path.Reliability),
+			Price:         formatPathPrice(path.Price),
 		}
 	}
 
@@ -330,8 +498,19 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 		TravelCount:        travelCount,
 		MaxConnectionTime:  filter.MaxConnectionTimeHours,
 		MinConnectionTime:  filter.MinConnectionTimeMinutes,
+		MinReliability:     int(math.Round(filter.MinReliability * 100)),
+		UnreliablePaths:    unreliablePaths,
+		IncludeAirlines:    strings.Join(filter.IncludeAirlines, ","),
+		ExcludeAirlines:    strings.Join(filter.ExcludeAirlines, ","),
+		SameAirline:        filter.SameAirlineOnly,
+		OtherAirlinePaths:  otherAirlinePaths,
+		OrderBy:            orderBy,
+		Currency:           filter.Currency,
 		Paths:              displayPaths,
 		ExecutionTime:      executionTime.String(),
+		Debug:              debug,
+		Analyze:            analyze,
+		Diagnostics:        travelDao.Diagnostics,
 	}
 
 	c.HTML(http.StatusOK, "travel-search-result.html", gin.H{
@@ -339,6 +518,34 @@ func (controller *TravelSearchController) SearchResult(c *gin.Context) {
 	})
 }
 
+// formatPrice shows an amount with cents and its currency
+func formatPrice(amount float64, currency string) string {
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+// formatPathPrice shows the total price of a completely priced path, else how many of its legs have a fare,
+// empty of the paths found without ordering by the price
+func formatPathPrice(price *fares.PathPrice) string {
+	if price == nil {
+		return ""
+	}
+	if price.Complete() {
+		return formatPrice(price.Total, price.Currency)
+	}
+
+	return fmt.Sprintf("%d of %d legs priced", price.PricedLegs, len(price.Legs))
+}
+
+// formatReliability shows the probability of making the rated connections, empty of the paths without rated ones
+func formatReliability(reliability *delays.Reliability) string {
+	if reliability == nil || reliability.RatedConnections == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%.0f%% (%d of %d connections rated)",
+		reliability.Probability*100, reliability.RatedConnections, reliability.Connections)
+}
+
 func getAvailableDatabases() []DatabaseOption {
 	var databases []DatabaseOption
 
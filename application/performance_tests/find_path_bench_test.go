@@ -47,13 +47,13 @@ func BenchmarkFindPaths2(b *testing.B) {
 	fromDate := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
 	toDate := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
 
-	for b.Loop() {
+	for i := 0; i < b.N; i++ {
 		point1 := points[rand.Intn(len(points))]
 		point2 := points[rand.Intn(len(points))]
 
 		filter := data.NewTravelFilter(point1.ID, point2.ID, fromDate, toDate, 2)
 
-		paths, err := strategy.FindPaths(filter)
+		paths, err := strategy.FindPath(filter)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -105,13 +105,13 @@ func BenchmarkFindPaths3(b *testing.B) {
 	fromDate := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
 	toDate := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
 
-	for b.Loop() {
+	for i := 0; i < b.N; i++ {
 		point1 := points[rand.Intn(len(points))]
 		point2 := points[rand.Intn(len(points))]
 
 		filter := data.NewTravelFilter(point1.ID, point2.ID, fromDate, toDate, 3)
 
-		paths, err := strategy.FindPaths(filter)
+		paths, err := strategy.FindPath(filter)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -93,7 +93,7 @@ func TestSimpleStrategy3Transits(t *testing.T) {
 	//	TravelCount:     2,
 	//}
 
-	paths, err := strategy.FindPaths(filter)
+	paths, err := strategy.FindPath(filter)
 	if err != nil {
 		t.Fatal(err)
 	}